@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/abdfnx/resto/cli/secret"
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/credentials"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+func AuthAdd(f *factory.Factory) *cobra.Command {
+	opts := options.AuthAddCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "add <host> [flags]",
+		Short: "Store the credentials of a host",
+		Long:  `Store the credentials of a host in ~/.resto/hosts.json, requests to the host use them when no auth is given. Passwords and tokens are kept in the vault, hosts.json only has their secret: references.`,
+		Example: heredoc.Doc(`
+			# the password is prompted, so it doesn't end up in your shell history
+			resto auth add api.example.com --username USERNAME
+
+			echo $PASSWORD | resto auth add localhost:8080 --username USERNAME --password-stdin
+			resto auth add api.example.com --token env:EXAMPLE_TOKEN
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Host = args[0]
+
+			return runAdd(&opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Username, "username", "u", "", "The username to use for basic or digest authentication")
+	cmd.Flags().StringVarP(&opts.Token, "token", "t", "", "The bearer token to use for authentication")
+	cmd.Flags().StringVar(&opts.Type, "auth-type", "", "The authentication type: basic, bearer or digest (Default: from the given credentials)")
+	cmd.Flags().BoolVar(&opts.PasswordStdin, "password-stdin", false, "Read the password from stdin")
+
+	return cmd
+}
+
+func runAdd(opts *options.AuthAddCommandOptions) error {
+	if opts.Type == "" {
+		if opts.Token != "" {
+			opts.Type = "bearer"
+		} else {
+			opts.Type = "basic"
+		}
+	}
+
+	if opts.Type != "bearer" && opts.Username == "" {
+		return &tools.FlagError{Err: fmt.Errorf("--username is required for %s authentication", opts.Type)}
+	}
+
+	if opts.Type != "bearer" {
+		if opts.PasswordStdin {
+			password, err := ioutil.ReadAll(opts.IO.In)
+
+			if err != nil {
+				return err
+			}
+
+			opts.Password = strings.TrimRight(string(password), "\r\n")
+		} else if opts.IO.CanPrompt() {
+			if err := survey.AskOne(&survey.Password{Message: "Password:"}, &opts.Password); err != nil {
+				return err
+			}
+		} else {
+			return &tools.FlagError{Err: fmt.Errorf("--password-stdin is required when resto can't prompt for the password")}
+		}
+	}
+
+	host := credentials.NormalizeHost(opts.Host)
+
+	var secrets credentials.SecretStore
+
+	// env: and secret: references are stored as they are, anything else goes to the vault
+	for _, value := range []string{opts.Password, opts.Token} {
+		if value != "" && !credentials.IsReference(value) && secrets == nil {
+			v, err := secret.OpenVault(opts.IO)
+
+			if err != nil {
+				return err
+			}
+
+			secrets = v
+		}
+	}
+
+	err := credentials.Add(host, credentials.Host{
+		Type:     opts.Type,
+		Username: opts.Username,
+		Password: opts.Password,
+		Token:    opts.Token,
+	}, secrets)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(opts.IO.Out, "%s Stored the %s credentials of %s\n", opts.IO.ColorScheme().SuccessIcon(), opts.Type, host)
+
+	return nil
+}
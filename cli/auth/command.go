@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"github.com/abdfnx/resto/cmd/factory"
+
+	"github.com/spf13/cobra"
+)
+
+func AuthCMD(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage authentication",
+		Long:  `Login to OAuth2 profiles and manage the credentials resto uses`,
+	}
+
+	cmd.AddCommand(AuthLogin(f), AuthAdd(f), AuthList(f), AuthRemove(f))
+
+	return cmd
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/credentials"
+	"github.com/abdfnx/resto/core/options"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+func AuthList(f *factory.Factory) *cobra.Command {
+	opts := options.AuthCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the hosts with stored credentials",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func runList(opts *options.AuthCommandOptions) error {
+	hosts, err := credentials.Hosts()
+
+	if err != nil {
+		return err
+	}
+
+	if len(hosts) == 0 {
+		fmt.Fprintln(opts.IO.ErrOut, "no stored credentials, add some with `resto auth add <host>`")
+		return nil
+	}
+
+	hostsTable := table.NewWriter()
+	hostsTable.AppendHeader(table.Row{"Host", "Type", "Username"})
+
+	for _, name := range credentials.HostNames(hosts) {
+		hostsTable.AppendRow(table.Row{name, hosts[name].Type, hosts[name].Username})
+	}
+
+	hostsTable.SetStyle(table.StyleRounded)
+
+	fmt.Fprintln(opts.IO.Out, hostsTable.Render())
+
+	return nil
+}
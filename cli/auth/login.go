@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/abdfnx/resto/cli/secret"
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/oauth2"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+func AuthLogin(f *factory.Factory) *cobra.Command {
+	opts := options.AuthLoginCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "login <profile>",
+		Short: "Login to an OAuth2 profile in the browser",
+		Long:  `Login to an OAuth2 profile with the authorization code flow and PKCE, the profile needs an authUrl, a tokenUrl and a clientId. The token is kept in the vault.`,
+		Example: heredoc.Doc(`
+			resto auth login github
+
+			# just print the login URL
+			resto auth login github --no-browser
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Profile = args[0]
+
+			return runLogin(&opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.NoBrowser, "no-browser", false, "Don't open the login URL in the browser")
+
+	return cmd
+}
+
+func runLogin(opts *options.AuthLoginCommandOptions) error {
+	openBrowser := tools.OpenBrowser
+
+	if opts.NoBrowser {
+		openBrowser = nil
+	}
+
+	// the token is kept in the vault, a new vault asks for its passphrase twice
+	if _, err := secret.OpenVault(opts.IO); err != nil {
+		return err
+	}
+
+	token, err := oauth2.Login(&options.OAuth2{Profile: opts.Profile}, openBrowser, opts.IO.ErrOut)
+
+	if err != nil {
+		return err
+	}
+
+	if !token.ExpiresAt.IsZero() {
+		fmt.Fprintf(opts.IO.Out, "Logged in to %s, the token expires at %s\n", opts.Profile, token.ExpiresAt.Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Fprintf(opts.IO.Out, "Logged in to %s\n", opts.Profile)
+	}
+
+	return nil
+}
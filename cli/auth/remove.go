@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/credentials"
+	"github.com/abdfnx/resto/core/options"
+
+	"github.com/spf13/cobra"
+)
+
+func AuthRemove(f *factory.Factory) *cobra.Command {
+	opts := options.AuthCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "remove <host>",
+		Aliases: []string{"rm"},
+		Short:   "Remove the stored credentials of a host",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Host = args[0]
+
+			return runRemove(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func runRemove(opts *options.AuthCommandOptions) error {
+	if err := credentials.Remove(opts.Host); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(opts.IO.Out, "%s Removed the credentials of %s\n", opts.IO.ColorScheme().SuccessIcon(), credentials.NormalizeHost(opts.Host))
+
+	return nil
+}
@@ -1,10 +1,17 @@
 package cli
 
 import (
+	"strings"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/export"
+
 	"github.com/spf13/cobra"
 )
 
-func DeleteCMD() *cobra.Command {
+func DeleteCMD(f *factory.Factory) *cobra.Command {
+	withBodyOpts.IO = f.IOStreams
+
 	cmd := &cobra.Command{
 		Use:   "delete <url> [flags]",
 		Short: "Send a DELETE request",
@@ -18,9 +25,7 @@ func DeleteCMD() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&withBodyOpts.Method.AuthType.TokenAuth, "token", "t", "", "The bearer token to use for authentication")
-	cmd.Flags().StringVarP(&withBodyOpts.Method.AuthType.BasicAuthUsername, "username", "u", "", "The username to use for basic authentication")
-	cmd.Flags().StringVarP(&withBodyOpts.Method.AuthType.BasicAuthPassword, "password", "p", "", "The password to use for basic authentication")
+	authFlags(cmd, withBodyOpts.Method.AuthType)
 	cmd.Flags().BoolVarP(&withBodyOpts.Method.JustShowBody, "just-body", "j", false, "Just show the response body")
 	cmd.Flags().BoolVarP(&withBodyOpts.Method.JustShowHeaders, "headers", "H", false, "Just show the response headers")
 	cmd.Flags().StringVarP(&withBodyOpts.Method.SaveFile, "save", "s", "", "Save the response to a file")
@@ -28,6 +33,10 @@ func DeleteCMD() *cobra.Command {
 	cmd.Flags().StringVarP(&withBodyOpts.Method.Body, "body", "b", "", "The body of the request")
 	cmd.Flags().BoolVarP(&withBodyOpts.Method.OpenEditor, "editor", "e", false, "Open the editor to edit the body")
 	cmd.Flags().BoolVarP(&withBodyOpts.Method.IsBodyStdin, "body-stdin", "i", false, "Read the body from stdin")
+	cmd.Flags().StringVar(&withBodyOpts.Method.Print, "print", "", "Print the request as a snippet instead of sending it (" + strings.Join(export.Names(), ", ") + ")")
+	cmd.Flags().StringVar(&withBodyOpts.Method.Filter, "filter", "", "Only show the value at a gjson path of a JSON response")
+	cmd.Flags().BoolVarP(&withBodyOpts.Method.RawOutput, "raw-output", "r", false, "Show filtered strings without quotes")
+	cmd.Flags().BoolVar(&withBodyOpts.Method.NoPager, "no-pager", false, "Don't show the response in a pager")
 
 	return cmd
 }
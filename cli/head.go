@@ -1,10 +1,17 @@
 package cli
 
 import (
+	"strings"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/export"
+
 	"github.com/spf13/cobra"
 )
 
-func HeadCMD() *cobra.Command {
+func HeadCMD(f *factory.Factory) *cobra.Command {
+	basicOpts.IO = f.IOStreams
+
 	cmd := &cobra.Command{
 		Use:   "head <url> [flags]",
 		Short: "Send a HEAD request",
@@ -21,9 +28,11 @@ func HeadCMD() *cobra.Command {
 	cmd.Flags().BoolVarP(&basicOpts.Method.JustShowBody, "just-body", "j", false, "Just show the response body")
 	cmd.Flags().BoolVarP(&basicOpts.Method.JustShowHeaders, "headers", "H", false, "Just show the response headers")
 	cmd.Flags().StringVarP(&basicOpts.Method.SaveFile, "save", "s", "", "Save the response body to a file")
-	cmd.Flags().StringVarP(&basicOpts.Method.AuthType.BasicAuthUsername, "username", "u", "", "The username to use for basic authentication")
-	cmd.Flags().StringVarP(&basicOpts.Method.AuthType.BasicAuthPassword, "password", "p", "", "The password to use for basic authentication")
-	cmd.Flags().StringVarP(&basicOpts.Method.AuthType.TokenAuth, "token", "t", "", "The bearer token to use for authentication")
+	authFlags(cmd, basicOpts.Method.AuthType)
+	cmd.Flags().StringVar(&basicOpts.Method.Print, "print", "", "Print the request as a snippet instead of sending it (" + strings.Join(export.Names(), ", ") + ")")
+	cmd.Flags().StringVar(&basicOpts.Method.Filter, "filter", "", "Only show the value at a gjson path of a JSON response")
+	cmd.Flags().BoolVarP(&basicOpts.Method.RawOutput, "raw-output", "r", false, "Show filtered strings without quotes")
+	cmd.Flags().BoolVar(&basicOpts.Method.NoPager, "no-pager", false, "Don't show the response in a pager")
 
 	return cmd
 }
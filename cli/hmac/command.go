@@ -0,0 +1,19 @@
+package hmac_cmd
+
+import (
+	"github.com/abdfnx/resto/cmd/factory"
+
+	"github.com/spf13/cobra"
+)
+
+func HmacCMD(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hmac",
+		Short: "Debug HMAC request signatures",
+		Long:  `Compute and check the HMAC signatures of the hmac auth type, to find out why a server refuses them.`,
+	}
+
+	cmd.AddCommand(HmacVerify(f))
+
+	return cmd
+}
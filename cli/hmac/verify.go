@@ -0,0 +1,146 @@
+package hmac_cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/hmac"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+func HmacVerify(f *factory.Factory) *cobra.Command {
+	opts := options.HMACVerifyCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "verify <url> [flags]",
+		Short: "Show the canonical string and the signature of a request",
+		Long: heredoc.Doc(`
+			Build the canonical string of a request like the hmac auth type does, sign it,
+			and compare it with the signature the server expects or received.
+		`),
+		Example: heredoc.Doc(`
+			resto hmac verify https://hooks.example.com/orders --method POST --body-file order.json \
+				--key env:WEBHOOK_KEY --timestamp 1700000000 --signature 5d41402abc4b2a76b9719d911017c592
+
+			# another canonical string and a base64 signature
+			resto hmac verify https://hooks.example.com/orders --key secret:WEBHOOK_KEY \
+				--template '{timestamp}.{body}' --encoding base64 --body '{"id": 1}'
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.URL = args[0]
+
+			if err := tools.MutuallyExclusive(
+				"specify only one of `--body` or `--body-file`",
+				opts.Body != "",
+				opts.BodyFile != "",
+			); err != nil {
+				return err
+			}
+
+			err := runVerify(&opts)
+
+			// a mismatch is already reported, the usage doesn't help
+			if err == tools.SilentError {
+				cmd.SilenceUsage = true
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.HMAC.Key, "key", "", "The HMAC key, env:NAME and secret:NAME are resolved")
+	cmd.Flags().StringVar(&opts.HMAC.Algorithm, "algorithm", "", "The HMAC hash: sha256, sha512 or sha1 (Default: sha256)")
+	cmd.Flags().StringVar(&opts.HMAC.Template, "template", "", "The canonical string to sign (Default: {method}\\n{path}\\n{timestamp}\\n{body})")
+	cmd.Flags().StringVar(&opts.HMAC.Encoding, "encoding", "", "The encoding of the signature: hex or base64 (Default: hex)")
+	cmd.Flags().StringVarP(&opts.Method, "method", "m", "POST", "The method of the request")
+	cmd.Flags().StringVar(&opts.Timestamp, "timestamp", "", "The timestamp the request was signed with")
+	cmd.Flags().StringVarP(&opts.Body, "body", "b", "", "The body of the request")
+	cmd.Flags().StringVar(&opts.BodyFile, "body-file", "", "Read the body of the request from a file")
+	cmd.Flags().StringVarP(&opts.Signature, "signature", "s", "", "The signature to compare with")
+
+	return cmd
+}
+
+func runVerify(opts *options.HMACVerifyCommandOptions) error {
+	if opts.HMAC.Key == "" {
+		return &tools.FlagError{Err: fmt.Errorf("--key is required")}
+	}
+
+	key, err := tools.ResolveValue(opts.HMAC.Key)
+
+	if err != nil {
+		return err
+	}
+
+	opts.HMAC.Key = key
+
+	u, err := url.Parse(opts.URL)
+
+	if err != nil {
+		return fmt.Errorf("invalid url: %s", err.Error())
+	}
+
+	body := []byte(opts.Body)
+
+	if opts.BodyFile != "" {
+		if body, err = ioutil.ReadFile(opts.BodyFile); err != nil {
+			return err
+		}
+	}
+
+	msg := hmac.Message{
+		Method:    opts.Method,
+		Host:      u.Host,
+		Path:      u.EscapedPath(),
+		Query:     u.RawQuery,
+		Timestamp: opts.Timestamp,
+		Body:      body,
+	}
+
+	result, err := hmac.Verify(opts.HMAC, msg, opts.Signature)
+
+	if err != nil {
+		return err
+	}
+
+	cs := opts.IO.ColorScheme()
+	out := opts.IO.Out
+
+	fmt.Fprintln(out, cs.Bold("CANONICAL STRING"))
+
+	// every line is quoted, so trailing spaces and \r are visible
+	for _, line := range strings.Split(result.Canonical, "\n") {
+		fmt.Fprintf(out, "%q\n", line)
+	}
+
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "%s %s\n", cs.Bold("SIGNATURE"), result.Expected)
+
+	if opts.Signature == "" {
+		return nil
+	}
+
+	if !result.Match {
+		fmt.Fprintf(out, "%s the signature doesn't match %s\n", cs.FailureIcon(), opts.Signature)
+
+		if hmac.UsesTimestamp(opts.HMAC.Template) && opts.Timestamp == "" {
+			fmt.Fprintf(out, "%s the canonical string uses a timestamp, set the one of the request with --timestamp\n", cs.WarningIcon())
+		}
+
+		return tools.SilentError
+	}
+
+	fmt.Fprintf(out, "%s the signature matches\n", cs.SuccessIcon())
+
+	return nil
+}
@@ -0,0 +1,361 @@
+package import_cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/core/curl"
+	"github.com/abdfnx/resto/core/export"
+	"github.com/abdfnx/resto/core/layout"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+func ImportCMD(f *factory.Factory, version string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a request from another tool",
+		Long:  `Import a request from another tool, like a curl command line.`,
+	}
+
+	cmd.AddCommand(curlCMD(f, version))
+
+	return cmd
+}
+
+func curlCMD(f *factory.Factory, version string) *cobra.Command {
+	opts := options.ImportCommandOptions{
+		IO: f.IOStreams,
+		Save: "",
+		UI: false,
+		Print: "",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "curl '<command>' [flags]",
+		Short: "Import a curl command",
+		Long:  `Import a curl command, send it, save it as a Restofile or open it in the resto UI.`,
+		Args:  cobra.MaximumNArgs(1),
+		Example: heredoc.Doc(`
+			# Send the request of a curl command
+			resto import curl 'curl -X POST https://api.xcode.codes -H "Content-Type: application/json" -d "{}"'
+
+			# Read the command from stdin
+			pbpaste | resto import curl
+
+			# Save it as a Restofile
+			resto import curl 'curl https://api.github.com -u user:pass' --save Restofile
+
+			# Load it into the resto UI
+			resto import curl 'curl https://api.github.com -H "Authorization: Bearer TOKEN"' --ui
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			command := ""
+
+			if len(args) > 0 {
+				command = args[0]
+			} else {
+				stdin, err := ioutil.ReadAll(os.Stdin)
+
+				if err != nil {
+					return err
+				}
+
+				command = string(stdin)
+			}
+
+			if err := tools.MutuallyExclusive("only one of --save, --ui and --print can be used", opts.Save != "", opts.UI, opts.Print != ""); err != nil {
+				return err
+			}
+
+			return runImportCurl(&opts, command, version)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Save, "save", "s", "", "Save the request as a Restofile to the given path")
+	cmd.Flags().BoolVar(&opts.UI, "ui", false, "Open the request in the resto UI")
+	cmd.Flags().StringVar(&opts.Print, "print", "", "Print the request as a snippet instead of sending it (" + strings.Join(export.Names(), ", ") + ")")
+
+	return cmd
+}
+
+func runImportCurl(opts *options.ImportCommandOptions, command, version string) error {
+	parsed, err := curl.Parse(strings.TrimSpace(command))
+
+	if err != nil {
+		return err
+	}
+
+	if opts.UI {
+		// the UI has no fields for these, they're printed before it takes over the terminal
+		if parsed.Insecure {
+			fmt.Fprintln(os.Stderr, "resto UI doesn't support --insecure, skipped it")
+		}
+
+		if len(parsed.Form) > 0 {
+			fmt.Fprintln(os.Stderr, "resto UI doesn't support multipart forms (-F), skipped them")
+		}
+
+		layout.LayoutWithRequest(version, toRequest(parsed), nil)
+
+		return nil
+	}
+
+	if opts.Save != "" {
+		return saveRestofile(parsed, opts.Save)
+	}
+
+	if opts.Print != "" {
+		req, err := parsed.HTTPRequest()
+
+		if err != nil {
+			return err
+		}
+
+		snippet, err := export.Generate(opts.Print, req)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(opts.IO.Out, snippet)
+
+		return nil
+	}
+
+	req, err := sendRequest(parsed)
+
+	if err != nil {
+		return err
+	}
+
+	respone, status, headers, err := api.Do(req, opts.IO.ColorEnabled())
+
+	if err != nil {
+		return err
+	}
+
+	if err := opts.IO.StartPager(); err != nil {
+		fmt.Fprintf(opts.IO.ErrOut, "error starting pager: %v\n", err)
+	}
+
+	defer opts.IO.StopPager()
+
+	out := opts.IO.Out
+
+	fmt.Fprintln(out, headers)
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, status)
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, respone)
+
+	return nil
+}
+
+// sendRequest maps a parsed curl command to a request that's sent like every other request of resto,
+// with the headers and body curl would have sent
+func sendRequest(parsed *curl.Request) (*options.Request, error) {
+	req := toRequest(parsed)
+	req.Insecure = parsed.Insecure
+
+	// sent as a header with its params, a content type like application/graphql would change the body otherwise
+	req.ContentType = ""
+
+	if contentType := parsed.Header("Content-Type"); contentType != "" {
+		req.Headers["Content-Type"] = contentType
+	}
+
+	if len(parsed.Form) > 0 {
+		built, err := parsed.HTTPRequest()
+
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := ioutil.ReadAll(built.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Body = string(body)
+		req.Headers["Content-Type"] = built.Header.Get("Content-Type")
+	}
+
+	for key := range req.Headers {
+		// with --compressed, the go transport asks for gzip and decompresses the response itself
+		if parsed.Compressed && strings.EqualFold(key, "Accept-Encoding") {
+			delete(req.Headers, key)
+		}
+	}
+
+	return req, nil
+}
+
+// toRequest maps a parsed curl command to the fields of the resto UI
+func toRequest(parsed *curl.Request) *options.Request {
+	req := &options.Request{
+		Method: parsed.Method,
+		URL: parsed.URL,
+		ContentType: parsed.ContentType(),
+		Body: parsed.Body,
+		Headers: map[string]string{},
+		AuthType: &options.Auth{},
+	}
+
+	if parsed.Username != "" {
+		req.AuthType.Type = "basic"
+		req.AuthType.BasicAuthUsername = parsed.Username
+		req.AuthType.BasicAuthPassword = parsed.Password
+	} else if token := parsed.BearerToken(); token != "" {
+		req.AuthType.Type = "bearer"
+		req.AuthType.TokenAuth = token
+	}
+
+	for _, h := range parsed.Headers {
+		if strings.EqualFold(h.Key, "Content-Type") {
+			continue
+		}
+
+		if strings.EqualFold(h.Key, "Authorization") && req.AuthType.Type != "" {
+			continue
+		}
+
+		req.Headers[h.Key] = h.Value
+	}
+
+	return req
+}
+
+// inlineBodySize is the size up to which a body is written in the Restofile instead of a file next to it
+const inlineBodySize = 2048
+
+// saveRestofile writes the request as a Restofile, a small body is written inline and a large one goes to a file next to it
+func saveRestofile(parsed *curl.Request, path string) error {
+	if len(parsed.Form) > 0 {
+		return fmt.Errorf("multipart forms (-F) can't be saved as a Restofile")
+	}
+
+	req := toRequest(parsed)
+
+	entry := func(key, value string) restofile.Node {
+		return &restofile.Entry{Key: key, Values: []restofile.Value{{Text: value}}}
+	}
+
+	request := &restofile.Block{Name: "request", Body: []restofile.Node{entry("method", req.Method), entry("url", req.URL)}}
+
+	if req.ContentType != "" {
+		request.Body = append(request.Body, entry("contentType", req.ContentType))
+	}
+
+	file := &restofile.File{Body: []restofile.Node{request}}
+
+	if len(req.Headers) > 0 {
+		var keys []string
+
+		for key := range req.Headers {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		headers := &restofile.Block{Name: "headers"}
+
+		for _, key := range keys {
+			headers.Body = append(headers.Body, entry(key, req.Headers[key]))
+		}
+
+		file.Body = append(file.Body, headers)
+	}
+
+	if value, ok := inlineBody(req.Body); ok {
+		file.Body = append(file.Body, &restofile.Block{Name: "body", Body: []restofile.Node{
+			&restofile.Entry{Key: "content", Values: []restofile.Value{value}},
+		}})
+	} else if req.Body != "" {
+		bodyFile := path + ".body" + bodyExtension(req.ContentType)
+
+		if err := ioutil.WriteFile(bodyFile, []byte(req.Body), 0644); err != nil {
+			return err
+		}
+
+		// readFrom is read relative to the Restofile, which is in the same directory
+		file.Body = append(file.Body, &restofile.Block{Name: "body", Body: []restofile.Node{entry("readFrom", filepath.Base(bodyFile))}})
+	}
+
+	if req.AuthType.Type == "basic" {
+		file.Body = append(file.Body, &restofile.Block{Name: "auth", Body: []restofile.Node{
+			entry("type", "basic"),
+			entry("username", req.AuthType.BasicAuthUsername),
+			entry("password", req.AuthType.BasicAuthPassword),
+		}})
+	} else if req.AuthType.Type == "bearer" {
+		file.Body = append(file.Body, &restofile.Block{Name: "auth", Body: []restofile.Node{
+			entry("type", "bearer"),
+			entry("token", req.AuthType.TokenAuth),
+		}})
+	}
+
+	// written like resto fmt does
+	if err := ioutil.WriteFile(path, restofile.Format(file), 0644); err != nil {
+		return err
+	}
+
+	if parsed.Insecure {
+		fmt.Fprintln(os.Stderr, "Restofile doesn't support --insecure, skipped it")
+	}
+
+	fmt.Println("Saved to " + path)
+
+	return nil
+}
+
+// inlineBody returns the value of a body that can be written in the Restofile as it is: a heredoc, or a """ string
+// when it has {{variables}} that must be sent as written
+func inlineBody(body string) (restofile.Value, bool) {
+	if body == "" || len(body) > inlineBodySize || strings.ContainsAny(body, "\r\x00") {
+		return restofile.Value{}, false
+	}
+
+	// blank lines would lose their spaces
+	for _, line := range strings.Split(body, "\n") {
+		if line != "" && strings.TrimSpace(line) == "" {
+			return restofile.Value{}, false
+		}
+	}
+
+	if !strings.Contains(body, "{{") {
+		return restofile.Value{Text: body, Heredoc: "EOF"}, true
+	}
+
+	if strings.Contains(body, `"""`) {
+		return restofile.Value{}, false
+	}
+
+	return restofile.Value{Text: body, Raw: true}, true
+}
+
+func bodyExtension(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return ".json"
+	case strings.Contains(contentType, "graphql"):
+		return ".graphql"
+	case strings.Contains(contentType, "xml"):
+		return ".xml"
+	case strings.Contains(contentType, "html"):
+		return ".html"
+	}
+
+	return ".txt"
+}
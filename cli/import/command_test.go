@@ -0,0 +1,94 @@
+package import_cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/core/curl"
+	"github.com/abdfnx/resto/core/restofile"
+)
+
+func TestSaveRestofileInSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api", "Restofile")
+
+	if err := os.Mkdir(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"items": [` + strings.Repeat(`"resto",`, inlineBodySize/8) + `"resto"]}`
+
+	parsed, err := curl.Parse("curl -X POST https://api.example.com -H 'Content-Type: application/json' -d '" + body + "'")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveRestofile(parsed, path); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := restofile.Load(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readFrom := file.Block("body").Entry("readFrom")
+
+	if readFrom == nil {
+		t.Fatalf("expected the body to be saved in a file, got:\n%s", restofile.Format(file))
+	}
+
+	if readFrom.Value() != "Restofile.body.json" {
+		t.Errorf("expected readFrom to be relative to the Restofile, got %q", readFrom.Value())
+	}
+
+	// read like resto run does, relative to the Restofile
+	data, err := ioutil.ReadFile(filepath.Join(filepath.Dir(readFrom.Pos.File), readFrom.Value()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != body {
+		t.Errorf("expected the body %q, got %q", body, data)
+	}
+}
+
+func TestSendRequest(t *testing.T) {
+	parsed, err := curl.Parse("curl -k --compressed https://api.example.com/graphql -H 'Content-Type: application/graphql; charset=utf-8' -H 'Accept-Encoding: gzip' -H 'Authorization: Bearer abc' -d '{ viewer { login } }'")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := sendRequest(parsed)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !req.Insecure {
+		t.Error("expected -k to skip the verification of TLS certificates")
+	}
+
+	if req.ContentType != "" || req.Headers["Content-Type"] != "application/graphql; charset=utf-8" {
+		t.Errorf("expected the content type to be sent as written, got %q and the headers %v", req.ContentType, req.Headers)
+	}
+
+	if _, ok := req.Headers["Accept-Encoding"]; ok {
+		t.Errorf("expected --compressed to drop Accept-Encoding, got the headers %v", req.Headers)
+	}
+
+	if req.AuthType.Type != "bearer" || req.AuthType.TokenAuth != "abc" || req.Headers["Authorization"] != "" {
+		t.Errorf("expected the bearer token in the auth, got %+v and the headers %v", req.AuthType, req.Headers)
+	}
+
+	if req.Body != "{ viewer { login } }" {
+		t.Errorf("expected the body to be sent as written, got %q", req.Body)
+	}
+}
@@ -0,0 +1,19 @@
+package jwt_cmd
+
+import (
+	"github.com/abdfnx/resto/cmd/factory"
+
+	"github.com/spf13/cobra"
+)
+
+func JwtCMD(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jwt",
+		Short: "Inspect JSON Web Tokens",
+		Long:  `Decode and verify JSON Web Tokens, like the bearer tokens of your requests.`,
+	}
+
+	cmd.AddCommand(JwtDecode(f))
+
+	return cmd
+}
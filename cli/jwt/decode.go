@@ -0,0 +1,147 @@
+package jwt_cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/core/jwt"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+func JwtDecode(f *factory.Factory) *cobra.Command {
+	opts := options.JWTDecodeCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "decode [<token>] [flags]",
+		Short: "Decode a JWT and check its times and signature",
+		Long:  `Show the header and claims of a JWT, when it expires, and verify its signature with a secret, a public key or a JWKS file.`,
+		Example: heredoc.Doc(`
+			resto jwt decode eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+
+			# read the token from stdin or an env variable
+			echo $TOKEN | resto jwt decode
+			resto jwt decode env:TOKEN --audience api://orders
+
+			# verify the signature
+			resto jwt decode env:TOKEN --secret env:JWT_SECRET
+			resto jwt decode env:TOKEN --jwks jwks.json
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Token = args[0]
+			}
+
+			if err := tools.MutuallyExclusive(
+				"specify only one of `--secret`, `--key` or `--jwks`",
+				opts.Secret != "",
+				opts.KeyFile != "",
+				opts.JWKSFile != "",
+			); err != nil {
+				return err
+			}
+
+			err := runDecode(&opts)
+
+			// a failed verification is already reported, the usage doesn't help
+			if err == tools.SilentError {
+				cmd.SilenceUsage = true
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Secret, "secret", "", "The HS256 secret to verify the signature with, env:NAME reads it from an env variable")
+	cmd.Flags().StringVar(&opts.KeyFile, "key", "", "A PEM public key or certificate to verify RS256 signatures with")
+	cmd.Flags().StringVar(&opts.JWKSFile, "jwks", "", "A JWKS file to verify RS256 signatures with")
+	cmd.Flags().StringVar(&opts.Audience, "audience", "", "Warn when the token isn't meant for this audience")
+
+	return cmd
+}
+
+func runDecode(opts *options.JWTDecodeCommandOptions) error {
+	raw, err := tools.ResolveValue(opts.Token)
+
+	if err != nil {
+		return err
+	}
+
+	if raw == "" || raw == "-" {
+		stdin, err := ioutil.ReadAll(opts.IO.In)
+
+		if err != nil {
+			return err
+		}
+
+		raw = string(stdin)
+	}
+
+	token, err := jwt.Decode(raw)
+
+	if err != nil {
+		return err
+	}
+
+	cs := opts.IO.ColorScheme()
+	out := opts.IO.Out
+
+	colorize := func(s string) string {
+		if opts.IO.ColorEnabled() {
+			return api.Colorize(s)
+		}
+
+		return s
+	}
+
+	fmt.Fprintln(out, cs.Bold("HEADER"))
+	fmt.Fprintln(out, colorize(token.HeaderJSON()))
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, cs.Bold("CLAIMS"))
+	fmt.Fprintln(out, colorize(token.ClaimsJSON()))
+
+	now := time.Now()
+
+	if times := token.Times(now); len(times) > 0 {
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, strings.Join(times, "\n"))
+	}
+
+	warnings := token.Warnings(now, opts.Audience)
+
+	if len(warnings) > 0 {
+		fmt.Fprintln(out, "")
+
+		for _, warning := range warnings {
+			fmt.Fprintf(out, "%s %s\n", cs.WarningIcon(), warning)
+		}
+	}
+
+	key := jwt.Key{Secret: opts.Secret, File: opts.KeyFile, JWKS: opts.JWKSFile}
+
+	if key.Empty() {
+		return nil
+	}
+
+	fmt.Fprintln(out, "")
+
+	if err := token.Verify(key); err != nil {
+		fmt.Fprintf(out, "%s %s\n", cs.FailureIcon(), err.Error())
+
+		return tools.SilentError
+	}
+
+	fmt.Fprintf(out, "%s the %s signature is valid\n", cs.SuccessIcon(), token.Algorithm())
+
+	return nil
+}
@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"strings"
+
+	"github.com/abdfnx/resto/core/api"
 	"github.com/abdfnx/resto/core/options"
+
+	"github.com/spf13/cobra"
 )
 
 var basicOpts = options.CLIOptions{
@@ -15,6 +20,10 @@ var basicOpts = options.CLIOptions{
 		JustShowBody: false,
 		JustShowHeaders: false,
 		SaveFile: "",
+		Print: "",
+		Filter: "",
+		RawOutput: false,
+		NoPager: false,
 	},
 }
 
@@ -33,6 +42,10 @@ var withBodyOpts = options.CLIOptions{
 		OpenEditor: false,
 		Body: "",
 		IsBodyStdin: false,
+		Print: "",
+		Filter: "",
+		RawOutput: false,
+		NoPager: false,
 	},
 }
 
@@ -40,4 +53,13 @@ var getLatestOpts = options.GetLatestCommandOptions{
 	Registry: "",
 	Repo: "",
 	Token: "",
-}
\ No newline at end of file
+}
+
+// authFlags adds the authentication flags shared by the request commands, one for each field of the registered auth providers
+func authFlags(cmd *cobra.Command, auth *options.Auth) {
+	cmd.Flags().StringVar(&auth.Type, "auth-type", "", "The authentication type: "+strings.Join(api.AuthTypes(), ", ")+" (Default: from the given credentials)")
+
+	for _, flag := range api.AuthFlags() {
+		cmd.Flags().StringVarP(flag.Value(auth), flag.Name, flag.Shorthand, "", flag.Usage)
+	}
+}
@@ -7,6 +7,15 @@ request {
    contentType "application/graphql"
 }
 
+headers {
+   Accept "application/json"
+   "X-Tenant" "env:TENANT"
+}
+
+query {
+   page "2"
+}
+
 body {
    openBodyEditor "true"
    # if `openBodyEditor` prop is false or not set
@@ -16,12 +25,36 @@ body {
 auth {
    type "bearer"
    token: "MY_TOKEN"
-   # or basic auth
+   # or basic auth, or digest auth with type "digest"
    type "basic"
    username: "USERNAME"
    password: "P@$$w0rd"
    # to use from env variable
    password "env:MY_PASSWORD"
+   # or from the encrypted vault, see `resto secret`
+   password "secret:MY_PASSWORD"
+   # or oauth2, with a profile from settings or the client credentials
+   type "oauth2"
+   profile "example"
+   tokenUrl "https://auth.example.com/oauth/token"
+   clientId "CLIENT_ID"
+   clientSecret "env:CLIENT_SECRET"
+   scopes "orders:read"
+   # or aws-sigv4, credentials default to the AWS env variables and ~/.aws/credentials
+   type "aws-sigv4"
+   profile "minio"
+   region "us-east-1"
+   service "s3"
+   # or an api key, in a header (Default: X-API-Key), a query param or a cookie
+   type "apikey"
+   name "api_key"
+   in "query"
+   key "env:API_KEY"
+   # or an hmac signature of a canonical string, in X-Signature with the timestamp in X-Timestamp
+   type "hmac"
+   key "secret:WEBHOOK_KEY"
+   algorithm "sha256"
+   template "{method}\n{path}\n{timestamp}\n{body}"
 }
 */
 
@@ -30,316 +63,329 @@ package run
 import (
 	"fmt"
 	"os"
-	"log"
-	"io/ioutil"
+	"path/filepath"
 	"strings"
 
-	"github.com/abdfnx/resto/tools"
+	"github.com/abdfnx/resto/cmd/factory"
 	"github.com/abdfnx/resto/core/api"
-	"github.com/abdfnx/resto/core/editor"
-	"github.com/abdfnx/resto/core/editor/runtime"
+	"github.com/abdfnx/resto/core/capture"
+	"github.com/abdfnx/resto/core/environment"
+	"github.com/abdfnx/resto/core/export"
 	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/tools"
 
-	tcell "github.com/gdamore/tcell/v2"
-	"github.com/rivo/tview"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"github.com/pkg/errors"
 )
 
-func RunCMD() *cobra.Command {
+func RunCMD(f *factory.Factory) *cobra.Command {
 	opts := options.RunCommandOptions{
+		IO: f.IOStreams,
 		Path: "",
 		ShowAll: false,
 	}
 
 	cmd := &cobra.Command{
-		Use:   "run  [flags]",
+		Use:   "run [<name>] [flags]",
 		Short: "Send a request from Restofile",
-		Long:  `Send a request via file "Restofile"`,
+		Long:  `Send a request via file "Restofile", a Restofile can hold several named requests like request "create-user" { ... }`,
+		Example: heredoc.Doc(`
+			# Send the request of ./Restofile
+			resto run
+
+			# Send the request called create-user
+			resto run create-user --file api/Restofile
+
+			# List the requests, then send all of them in order
+			resto run --list
+			resto run --all
+
+			# Use the variables of the staging environment
+			resto run create-user --env staging
+
+			# Keep the values captured by login for the next runs
+			resto run login --state .resto/state.json
+			resto run create-user --state .resto/state.json
+		`),
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Name = args[0]
+			}
+
+			if err := tools.MutuallyExclusive("specify only one of a request name, `--all` or `--list`", opts.Name != "", opts.All, opts.List); err != nil {
+				return err
+			}
+
 			return run(&opts)
 		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+
+			return completeRequests(restofilePath(&opts)), cobra.ShellCompDirectiveNoFileComp
+		},
 	}
 
 	cmd.Flags().StringVarP(&opts.Path, "file", "f", "", "Path to Restofile (Default: PATH/Restofile)")
-	cmd.Flags().BoolVarP(&opts.ShowAll, "all", "a", false, "Show all response headers & status")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Send all the requests of the Restofile in order, without a pager")
+	cmd.Flags().BoolVarP(&opts.List, "list", "l", false, "List the requests of the Restofile")
+	cmd.Flags().StringVar(&opts.State, "state", "", "A file to save the captured values to, and to read them from in the next runs")
+	cmd.Flags().StringVarP(&opts.Env, "env", "e", "", "The environment of the variables, an env block of the Restofile or an environment of " + environment.FileName)
+	cmd.Flags().BoolVarP(&opts.ShowAll, "include", "i", false, "Show all response headers & status")
+	cmd.Flags().StringVar(&opts.Filter, "filter", "", "Only show the value at a gjson path of a JSON response")
+	cmd.Flags().BoolVarP(&opts.RawOutput, "raw-output", "r", false, "Show filtered strings without quotes")
+	cmd.Flags().BoolVar(&opts.NoPager, "no-pager", false, "Don't show the response in a pager")
+	cmd.Flags().StringVar(&opts.Print, "print", "", "Print the request as a snippet instead of sending it (" + strings.Join(export.Names(), ", ") + ")")
+
+	// -a showed the headers before --all sent all the requests
+	cmd.Flags().BoolVarP(&opts.ShowAll, "show-all", "a", false, "Show all response headers & status")
+	cmd.Flags().MarkDeprecated("show-all", "use -i/--include instead")
 
 	return cmd
 }
 
-func run(opts *options.RunCommandOptions) error {
-	path := "./Restofile"
+func restofilePath(opts *options.RunCommandOptions) string {
+	if opts.Path != "" {
+		return opts.Path
+	}
 
-	fn := tools.CLIRequestFile("txt")
-	cType := ""
+	return "./Restofile"
+}
 
-	method := ""
-	url := ""
-	contentType := ""
+func run(opts *options.RunCommandOptions) error {
+	file, err := restofile.Load(restofilePath(opts))
+
+	if err != nil {
+		return errors.Errorf("Error: %v", err)
+	}
 
-	openBodyEditor := false
-	readFrom := ""
-	content := ""
+	blocks, err := requestBlocks(file)
 
-	authType := ""
-	token := ""
-	username := ""
-	password := ""
+	if err != nil {
+		return errors.Errorf("Error: %v", err)
+	}
 
-	if opts.Path != "" {
-		path = opts.Path
+	if opts.List {
+		return listRequests(opts, blocks)
 	}
 
-	data, err := ioutil.ReadFile(path)
+	selected, err := selectRequests(blocks, opts.Name, opts.All)
 
 	if err != nil {
 		return errors.Errorf("Error: %v", err)
 	}
 
-	if strings.Contains(string(data), "request {") || strings.Contains(string(data), "request{") {
-		if strings.Contains(string(data), "method") {
-			method = strings.TrimSpace(strings.Split(string(data), "method")[1])
-			method = strings.TrimSpace(strings.Split(method, "\"")[1])
+	envs, err := environment.ReadFile(filepath.Join(filepath.Dir(file.Path), environment.FileName))
 
-			if strings.Contains(method, "env:") {
-				method = strings.TrimSpace(strings.Split(method, "env:")[1])
-				method = os.Getenv(method)
-			}
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Errorf("Error: %v", err)
+	}
+
+	if opts.Env != "" {
+		if err := checkEnv(file, opts.Env, envs); err != nil {
+			return errors.Errorf("Error: %v", err)
 		}
+	}
 
-		if strings.Contains(string(data), "url") {
-			url = strings.TrimSpace(strings.Split(string(data), "url")[1])
-			url = strings.TrimSpace(strings.Split(url, "\"")[1])
+	captured := map[string]string{}
 
-			if strings.Contains(url, "env:") {
-				url = strings.TrimSpace(strings.Split(url, "env:")[1])
-				url = os.Getenv(url)
-			}
+	if opts.State != "" {
+		captured, err = capture.ReadState(opts.State)
+
+		if err != nil {
+			return errors.Errorf("Error: %v", err)
 		}
+	}
 
-		if strings.Contains(string(data), "contentType") {
-			contentType = strings.TrimSpace(strings.Split(string(data), "contentType")[1])
-			contentType = strings.TrimSpace(strings.Split(contentType, "\"")[1])
+	if opts.All {
+		opts.NoPager = true
+	}
 
-			if strings.Contains(contentType, "env:") {
-				contentType = strings.TrimSpace(strings.Split(contentType, "env:")[1])
-				contentType = os.Getenv(contentType)
-			}
+	for _, block := range selected {
+		v := newVars(file, block, opts.Env, envs, captured)
 
-			if contentType != "" {
-				if contentType == "application/json" || contentType == "json" {
-					fn = tools.CLIRequestFile("json")
-					cType = "application/json"
-				} else if contentType == "application/graphql" || contentType == "graphql" {
-					fn = tools.CLIRequestFile("graphql")
-					cType = "application/graphql"
-				} else if contentType == "application/xml" || contentType == "xml" {
-					fn = tools.CLIRequestFile("xml")
-					cType = "application/xml"
-				} else if contentType == "text/html" || contentType == "html" {
-					fn = tools.CLIRequestFile("html")
-					cType = "text/html"
-				} else {
-					fn = tools.CLIRequestFile("txt")
-					cType = "text/plain"
-				}
-			}
+		if err := runBefore(file, block, v); err != nil {
+			return errors.Errorf("Error: %v", err)
+		}
+
+		req, err := readRequest(file, block, v)
+
+		if err != nil {
+			return errors.Errorf("Error: %v", err)
+		}
+
+		if opts.All {
+			fmt.Fprintln(opts.IO.Out, opts.IO.ColorScheme().Bold(fmt.Sprintf("# %s: %s %s", requestName(block), req.Method, req.URL)))
 		}
-	}
 
-	if strings.Contains(string(data), "body {") || strings.Contains(string(data), "body{") {
-		if strings.Contains(string(data), "openBodyEditor") {
-			openBodyEditorValue := strings.TrimSpace(strings.Split(string(data), "openBodyEditor")[1])
-			openBodyEditorValue = strings.TrimSpace(strings.Split(openBodyEditorValue, "\"")[1])
+		res, err := send(opts, req, func(res *api.Response) error {
+			return runAfter(file, block, v, req, res)
+		})
 
-			if strings.Contains(openBodyEditorValue, "env:") {
-				openBodyEditorValue = strings.TrimSpace(strings.Split(openBodyEditorValue, "env:")[1])
-				openBodyEditorValue = os.Getenv(openBodyEditorValue)
+		if err != nil {
+			if opts.All {
+				return fmt.Errorf("%s: %w", requestName(block), err)
 			}
 
-			if openBodyEditorValue == "yes" || openBodyEditorValue == "true" {
-				openBodyEditor = true
-			} else {
-				openBodyEditor = false
+			return err
+		}
+
+		if res != nil {
+			if err := captureValues(opts.State, block, res, captured); err != nil {
+				return errors.Errorf("Error: %v", err)
 			}
+		}
+	}
 
-			if openBodyEditor {
-				fileContent, err := ioutil.ReadFile(fn)
-				buffer := editor.NewBufferFromString(string(fileContent), fn)
-				if err != nil {
-					log.Fatalf("could not read %v: %v", fn, err)
-				}
+	return nil
+}
 
-				var colorscheme editor.Colorscheme
-				if railscast := runtime.Files.FindFile(editor.RTColorscheme, "railscast"); railscast != nil {
-					if data, err := railscast.Data(); err == nil {
-						colorscheme = editor.ParseColorscheme(string(data))
-					}
-				}
+// captureValues adds the values of the capture block of a request to captured, and saves them to the state file if it's set
+func captureValues(state string, block *restofile.Block, res *api.Response, captured map[string]string) error {
+	entries := block.Block("capture").Entries()
 
-				bodyEditor := editor.NewView(buffer)
-				bodyEditor.SetRuntimeFiles(runtime.Files)
-				bodyEditor.SetColorscheme(colorscheme)
+	if len(entries) == 0 {
+		return nil
+	}
 
-				app := tview.NewApplication()
-				bodyEditor.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-					switch event.Key() {
-						case tcell.KeyCtrlS:
-							tools.SaveBuffer(buffer, fn)
-							app.Stop()
-							return nil
+	for _, entry := range entries {
+		value, err := capture.Value(entry.Value(), res)
 
-						case tcell.KeyCtrlQ:
-							app.Stop()
-							return nil
-					}
+		if err != nil {
+			return restofile.Errorf(entry.Values[0].Pos, "can't capture %s, %v", entry.Key, err)
+		}
 
-					return event
-				})
+		captured[entry.Key] = value
+	}
 
-				app.SetRoot(bodyEditor, true)
+	if state != "" {
+		return capture.WriteState(state, captured)
+	}
 
-				if err := app.Run(); err != nil {
-					log.Fatalf("%v", err)
-				}
+	return nil
+}
 
-				b, e := os.Open(fn)
+// send sends a request of the Restofile and prints its response after passing it to after, or prints the request with --print
+func send(opts *options.RunCommandOptions, req *options.Request, after func(res *api.Response) error) (*api.Response, error) {
+	api.WarnExpiredToken(opts.IO, req.AuthType)
 
-				if e != nil {
-					fmt.Println(e)
-				}
+	if opts.Print != "" {
+		httpReq, err := api.Build(req)
 
-				defer b.Close()
+		if err != nil {
+			return nil, err
+		}
 
-				body, err := ioutil.ReadAll(b)
+		api.RedactRequest(httpReq, req.AuthType)
 
-				if err != nil {
-					panic(err)
-				}
+		snippet, err := export.Generate(opts.Print, httpReq)
 
-				content = string(body)
-			}
+		if err != nil {
+			return nil, err
 		}
 
-		if strings.Contains(string(data), "readFrom") {
-			readFrom = strings.TrimSpace(strings.Split(string(data), "readFrom")[1])
-			readFrom = strings.TrimSpace(strings.Split(readFrom, "\"")[1])
+		fmt.Fprintln(opts.IO.Out, snippet)
 
-			if strings.Contains(readFrom, "env:") {
-				readFrom = strings.TrimSpace(strings.Split(readFrom, "env:")[1])
-				readFrom = os.Getenv(readFrom)
-			}
+		return nil, nil
+	}
 
-			body, err := ioutil.ReadFile(readFrom)
-			if err != nil {
-				return err
-			}
+	res, err := api.Exchange(req)
 
-			content = string(body)
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		if !openBodyEditor && readFrom == "" {
-			if method == "POST" || method == "PUT" || method == "PATCH" || method == "DELETE" {
-				return errors.Errorf("Error: body is required")
-			}
-		}
+	if err := after(res); err != nil {
+		return nil, errors.Errorf("Error: %v", err)
 	}
 
-	if strings.Contains(string(data), "auth {") || strings.Contains(string(data), "auth{") {
-		if strings.Contains(string(data), "type") {
-			authType = strings.TrimSpace(strings.Split(string(data), "type")[1])
-			authType = strings.TrimSpace(strings.Split(authType, "\"")[1])
+	respone, status, headers := api.Format(res, opts.IO.ColorEnabled() && opts.Filter == "")
 
-			if strings.Contains(authType, "env:") {
-				authType = strings.TrimSpace(strings.Split(authType, "env:")[1])
-				authType = os.Getenv(authType)
-			}
-		}
+	return res, printResponse(opts, respone, status, headers)
+}
 
-		if authType == "bearer" {
-			if strings.Contains(string(data), "token") {
-				token = strings.TrimSpace(strings.Split(string(data), "token")[1])
-				token = strings.TrimSpace(strings.Split(token, "\"")[1])
+// listRequests prints the names, methods and urls of the requests as they're written in the Restofile
+func listRequests(opts *options.RunCommandOptions, blocks []*restofile.Block) error {
+	requestsTable := table.NewWriter()
+	requestsTable.AppendHeader(table.Row{"Name", "Method", "URL"})
 
-				if strings.Contains(token, "env:") {
-					token = strings.TrimSpace(strings.Split(token, "env:")[1])
-					token = os.Getenv(token)
-				}
-			}
-		} else if authType == "basic" {
-			if strings.Contains(string(data), "username") {
-				username = strings.TrimSpace(strings.Split(string(data), "username")[1])
-				username = strings.TrimSpace(strings.Split(username, "\"")[1])
-
-				if strings.Contains(username, "env:") {
-					username = strings.TrimSpace(strings.Split(username, "env:")[1])
-					username = os.Getenv(username)
-				}
-			}
+	for _, block := range blocks {
+		requestsTable.AppendRow(table.Row{requestName(block), strings.ToUpper(block.Value("method")), block.Value("url")})
+	}
 
-			if strings.Contains(string(data), "password") {
-				password = strings.TrimSpace(strings.Split(string(data), "password")[1])
-				password = strings.TrimSpace(strings.Split(password, "\"")[1])
+	requestsTable.SetStyle(table.StyleRounded)
 
-				if strings.Contains(password, "env:") {
-					password = strings.TrimSpace(strings.Split(password, "env:")[1])
-					password = os.Getenv(password)
-				}
-			}
-		}
+	fmt.Fprintln(opts.IO.Out, requestsTable.Render())
+
+	return nil
+}
+
+// completeRequests returns the names of the requests for shell completion, with their methods and urls as descriptions
+func completeRequests(path string) []string {
+	file, err := restofile.Load(path)
+
+	if err != nil {
+		return nil
 	}
 
-	if method == "GET" || method == "HEAD" {
-		respone, status, headers, err := api.BasicGet(
-			url,
-			method,
-			authType,
-			token,
-			username,
-			password,
-			true,
-			0,
-			nil,
-		)
+	var names []string
 
-		if err != nil {
-			return err
+	for _, block := range file.Blocks("request") {
+		if block.Label() != "" && !block.Abstract() {
+			names = append(names, block.Label() + "\t" + strings.ToUpper(block.Value("method")) + " " + block.Value("url"))
 		}
+	}
 
-		if opts.ShowAll {
-			fmt.Println(headers)
-			fmt.Println("")
-			fmt.Println(status)
-		}
-	
-		fmt.Println("\n" + respone)
-	} else if method == "POST" || method == "PUT" || method == "PATCH" || method == "DELETE" {
-		respone, status, headers, err :=
-			api.BasicRequestWithBody(
-				url,
-				method,
-				cType,
-				content,
-				authType,
-				token,
-				username,
-				password,
-				true,
-				0,
-				nil,
-			)
+	return names
+}
 
-		if err != nil {
-			return err
-		}
-		
-		if opts.ShowAll {
-			fmt.Println(headers)
-			fmt.Println("")
-			fmt.Println(status)
+func requestName(block *restofile.Block) string {
+	if block.Label() == "" {
+		return "(unnamed)"
+	}
+
+	return block.Label()
+}
+
+// printResponse shows the response body, or just the value selected by --filter
+func printResponse(opts *options.RunCommandOptions, respone, status, headers string) error {
+	if !opts.NoPager {
+		if err := opts.IO.StartPager(); err != nil {
+			fmt.Fprintf(opts.IO.ErrOut, "error starting pager: %v\n", err)
 		}
 
-		fmt.Println("\n" + respone)
+		defer opts.IO.StopPager()
+	}
+
+	out := opts.IO.Out
+
+	if opts.ShowAll {
+		fmt.Fprintln(out, headers)
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, status)
+	}
+
+	if opts.Filter == "" {
+		fmt.Fprintln(out, "\n" + respone)
+
+		return nil
+	}
+
+	filtered, err := api.FilterResponse(respone, opts.Filter, opts.RawOutput)
+
+	if err != nil {
+		return err
+	}
+
+	if opts.RawOutput || !opts.IO.ColorEnabled() {
+		fmt.Fprintln(out, filtered)
+	} else {
+		fmt.Fprintln(out, api.Colorize(filtered))
 	}
 
 	return nil
@@ -0,0 +1,238 @@
+package run
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/ios"
+	"github.com/abdfnx/resto/tools"
+)
+
+func writeRestofile(t *testing.T, src string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "Restofile")
+
+	if err := ioutil.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestRunPrint(t *testing.T) {
+	path := writeRestofile(t, `request "create" {
+   method "POST"
+   url "https://api.example.com/users"
+   contentType "json"
+
+   headers { X-Tenant "acme" }
+   query { dry_run "true" }
+
+   body {
+      content <<EOF
+      {"name": "resto"}
+      EOF
+   }
+}
+`)
+
+	io, _, out, _ := ios.Test()
+
+	if err := run(&options.RunCommandOptions{IO: io, Path: path, Print: "curl"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"-X POST",
+		"'https://api.example.com/users?dry_run=true'",
+		"-H 'X-Tenant: acme'",
+		"-H 'Content-Type: application/json'",
+		`--data-raw '{"name": "resto"}'`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the snippet to contain %q, got\n%s", expected, out.String())
+		}
+	}
+
+	io, _, _, _ = ios.Test()
+
+	if err := run(&options.RunCommandOptions{IO: io, Path: path, Print: "cobol"}); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestRunWarnsExpiredToken(t *testing.T) {
+	// {"alg":"none"}.{"exp":1}
+	token := "eyJhbGciOiJub25lIn0.eyJleHAiOjF9."
+
+	path := writeRestofile(t, `request "me" {
+   method "GET"
+   url "https://api.example.com/me"
+
+   auth {
+      type "bearer"
+      token "`+token+`"
+   }
+}
+`)
+
+	io, _, _, errOut := ios.Test()
+
+	if err := run(&options.RunCommandOptions{IO: io, Path: path, Print: "curl"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(errOut.String(), "the token expired") {
+		t.Errorf("expected a warning for the expired token, got %q", errOut.String())
+	}
+}
+
+func TestRunUnresolvedHeader(t *testing.T) {
+	tools.RegisterResolver("failing:", func(name string) (string, error) {
+		return "", fmt.Errorf("no value for %s", name)
+	})
+
+	path := writeRestofile(t, `request "me" {
+   method "GET"
+   url "https://api.example.com/me"
+
+   headers { X-Token "failing:TOKEN" }
+}
+`)
+
+	io, _, _, _ := ios.Test()
+
+	err := run(&options.RunCommandOptions{IO: io, Path: path, Print: "curl"})
+
+	if err == nil || !strings.Contains(err.Error(), "Restofile:5:22: no value for TOKEN") {
+		t.Errorf("expected the error of the header with its position, got %v", err)
+	}
+}
+
+func TestRunHostileCapture(t *testing.T) {
+	tools.RegisterResolver("secret:", func(name string) (string, error) {
+		return "vault-" + name, nil
+	})
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret")
+
+	// the server answers the login with references, hoping the next request sends their values back
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			fmt.Fprint(w, `{"token": "secret:PROD_TOKEN", "key": "env:AWS_SECRET_ACCESS_KEY"}`)
+			return
+		}
+
+		fmt.Fprintf(w, "%s %s %s", r.Header.Get("X-Token"), r.URL.Query().Get("key"), r.Header.Get("Authorization"))
+	}))
+
+	defer server.Close()
+
+	for _, c := range []struct {
+		name     string
+		auth     string
+		expected string
+		err      string
+	}{
+		{
+			name:     "headers and query",
+			expected: "secret:PROD_TOKEN env:AWS_SECRET_ACCESS_KEY",
+		},
+		{
+			name: "auth",
+			auth: `auth { type "bearer"; token "{{token}}" }`,
+			err:  "token is made from captured values, it can't be an env: or secret: reference",
+		},
+	} {
+		path := writeRestofile(t, fmt.Sprintf(`request "login" {
+   method "GET"
+   url "%[1]s/login"
+
+   capture {
+      token "json:token"
+      key "json:key"
+   }
+}
+
+request "me" {
+   method "GET"
+   url "%[1]s/me"
+
+   headers { X-Token "{{token}}" }
+   query { key "{{key}}" }
+   %[2]s
+}
+`, server.URL, c.auth))
+
+		io, _, out, _ := ios.Test()
+
+		err := run(&options.RunCommandOptions{IO: io, Path: path, All: true})
+
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected an error with %q, got %v", c.name, c.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if !strings.Contains(out.String(), c.expected) || strings.Contains(out.String(), "vault-") || strings.Contains(out.String(), "aws-secret") {
+			t.Errorf("%s: expected the captured values to be sent as they are, got %q", c.name, out.String())
+		}
+	}
+}
+
+func TestCompleteRequests(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		src      string
+		expected []string
+	}{
+		{
+			name: "named requests",
+			src: `request "list" {
+   method "get"
+   url "https://api.example.com/users"
+}
+
+request "create" {
+   method "POST"
+   url "https://api.example.com/users"
+}
+
+request {
+   url "https://api.example.com"
+}`,
+			expected: []string{"list\tGET https://api.example.com/users", "create\tPOST https://api.example.com/users"},
+		},
+		{
+			name: "unnamed request",
+			src:  `request { url "https://api.example.com" }`,
+		},
+		{
+			name: "syntax error",
+			src:  `request "list" {`,
+		},
+	} {
+		got := completeRequests(writeRestofile(t, c.src))
+
+		if fmt.Sprint(got) != fmt.Sprint(c.expected) {
+			t.Errorf("%s: expected the completions %q, got %q", c.name, c.expected, got)
+		}
+	}
+
+	if got := completeRequests(filepath.Join(t.TempDir(), "Restofile")); got != nil {
+		t.Errorf("expected no completions without a Restofile, got %q", got)
+	}
+}
@@ -0,0 +1,129 @@
+package run
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func FmtCMD(f *factory.Factory) *cobra.Command {
+	opts := options.FmtCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "fmt [<path>...] [flags]",
+		Short: "Format Restofiles",
+		Long: heredoc.Doc(`
+			Rewrite Restofiles in the canonical style: blocks are indented with three spaces, values are quoted,
+			the method, url and contentType come first in a request and the blocks are in the same order in every file.
+			Comments are kept and the requests keep their order.
+
+			A path can be a Restofile or a directory, the Restofiles in a directory are found recursively.
+			The names of the files that were formatted are printed.
+		`),
+		Example: heredoc.Doc(`
+			# Format ./Restofile
+			resto fmt
+
+			# Check that all the Restofiles of the api directory are formatted, for the CI
+			resto fmt api --check
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Paths = args
+
+			err := runFmt(&opts)
+
+			// the files are already listed, the usage doesn't help
+			if err == tools.SilentError {
+				cmd.SilenceUsage = true
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Check, "check", false, "Don't write the files, list the ones that aren't formatted and exit with a non-zero status if there are any")
+
+	return cmd
+}
+
+func runFmt(opts *options.FmtCommandOptions) error {
+	paths, err := restofiles(opts.Paths)
+
+	if err != nil {
+		return errors.Errorf("Error: %v", err)
+	}
+
+	failed := false
+
+	for _, path := range paths {
+		changed, err := formatFile(path, !opts.Check)
+
+		if err != nil {
+			fmt.Fprintf(opts.IO.ErrOut, "%s %v\n", opts.IO.ColorScheme().FailureIcon(), err)
+			failed = true
+
+			continue
+		}
+
+		if changed {
+			fmt.Fprintln(opts.IO.Out, path)
+			failed = failed || opts.Check
+		}
+	}
+
+	if failed {
+		return tools.SilentError
+	}
+
+	return nil
+}
+
+// formatFile formats the Restofile at path and reports whether it changed, the file is only written when write is true.
+// Its line endings are kept
+func formatFile(path string, write bool) (bool, error) {
+	src, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return false, err
+	}
+
+	file, err := restofile.Parse(path, src)
+
+	if err != nil {
+		return false, err
+	}
+
+	formatted := restofile.Format(file)
+
+	if bytes.Contains(src, []byte("\r\n")) {
+		formatted = bytes.ReplaceAll(formatted, []byte("\n"), []byte("\r\n"))
+	}
+
+	if bytes.Equal(src, formatted) {
+		return false, nil
+	}
+
+	if !write {
+		return true, nil
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, ioutil.WriteFile(path, formatted, info.Mode())
+}
@@ -0,0 +1,175 @@
+package run
+
+import (
+	"encoding/json"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/core/environment"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/tools"
+)
+
+// envName matches the characters of a variable name that can't be in the name of an env variable
+var envName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// hookRequest is the request a before hook reads on its stdin
+type hookRequest struct {
+	Name   string            `json:"name"`
+	Method string            `json:"method"`
+	URL    string            `json:"url"`
+	Vars   map[string]string `json:"vars"`
+}
+
+// runBefore runs the before hook of a request, before its values are interpolated. The hook gets the request
+// as RESTO_* variables and as JSON on its stdin, the `name=value` lines it prints are added to the variables
+func runBefore(file *restofile.File, block *restofile.Block, v *vars) error {
+	hooks := innerBlock(file, block, "hooks")
+	entry := hooks.Entry("before")
+
+	if entry == nil {
+		return nil
+	}
+
+	// the variables of the url that aren't defined yet are kept, the hook can define them
+	url, _ := environment.InterpolateLiterals(block.Value("url"), v.values, v.captured)
+
+	request := hookRequest{
+		Name:   requestName(block),
+		Method: strings.ToUpper(block.Value("method")),
+		URL:    url,
+		Vars:   map[string]string{},
+	}
+
+	for _, values := range []map[string]string{v.values, v.captured} {
+		for key, value := range values {
+			request.Vars[key] = value
+		}
+	}
+
+	input, err := json.Marshal(request)
+
+	if err != nil {
+		return err
+	}
+
+	env := []string{
+		"RESTO_REQUEST=" + request.Name,
+		"RESTO_METHOD=" + request.Method,
+		"RESTO_URL=" + request.URL,
+	}
+
+	out, err := runHook(hooks, entry, v, env, string(input))
+
+	if err != nil {
+		return err
+	}
+
+	for i, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		name := strings.SplitN(line, "=", 2)
+
+		if len(name) != 2 || strings.TrimSpace(name[0]) == "" {
+			return restofile.Errorf(entry.Values[0].Pos, "the before hook printed %q on line %d, it can only print name=value lines", line, i+1)
+		}
+
+		// the values of the hook are used as they are, like the captured ones they replace
+		v.captured[strings.TrimSpace(name[0])] = name[1]
+	}
+
+	return nil
+}
+
+// runAfter runs the after hook of a request, before its response is printed, captured and checked. The hook gets
+// the body of the response on its stdin and the request and the status as RESTO_* variables, what it prints
+// replaces the body, unless it prints nothing
+func runAfter(file *restofile.File, block *restofile.Block, v *vars, req *options.Request, res *api.Response) error {
+	hooks := innerBlock(file, block, "hooks")
+	entry := hooks.Entry("after")
+
+	if entry == nil {
+		return nil
+	}
+
+	headers, err := json.Marshal(res.Header)
+
+	if err != nil {
+		return err
+	}
+
+	env := []string{
+		"RESTO_REQUEST=" + requestName(block),
+		"RESTO_METHOD=" + req.Method,
+		"RESTO_URL=" + req.URL,
+		"RESTO_STATUS=" + strconv.Itoa(res.StatusCode),
+		"RESTO_HEADERS=" + string(headers),
+	}
+
+	out, err := runHook(hooks, entry, v, env, string(res.Body))
+
+	if err != nil {
+		return err
+	}
+
+	if out != "" {
+		res.Body = []byte(out)
+	}
+
+	return nil
+}
+
+// runHook runs the command of a hook entry with the shell of the hooks block, bash or powershell by default.
+// The command isn't templated, the variables of the request are its RESTO_VAR_<name> env variables,
+// so a value can't change what the command runs
+func runHook(hooks *restofile.Block, entry *restofile.Entry, v *vars, env []string, stdin string) (string, error) {
+	command := entry.Value()
+	shell := v.value(hooks, "shell")
+
+	if v.err != nil {
+		return "", v.err
+	}
+
+	if shell == "" {
+		shell = "bash"
+
+		if runtime.GOOS == "windows" {
+			shell = "powershell.exe"
+		}
+	}
+
+	err, out, errout := tools.ExecWith(shell, command, append(env, hookVars(v)...), stdin)
+
+	if err != nil {
+		if errout = strings.TrimSpace(errout); errout != "" {
+			return "", restofile.Errorf(entry.Values[0].Pos, "the %s hook failed, %v: %s", entry.Key, err, errout)
+		}
+
+		return "", restofile.Errorf(entry.Values[0].Pos, "the %s hook failed, %v", entry.Key, err)
+	}
+
+	return out, nil
+}
+
+// hookVars returns the variables of a request as RESTO_VAR_<name> env variables, the characters of the names
+// that can't be in an env variable are replaced by _
+func hookVars(v *vars) []string {
+	var env []string
+
+	// the captured values come last, they take precedence like in the values of the request
+	for _, values := range []map[string]string{v.values, v.captured} {
+		for name, value := range values {
+			env = append(env, "RESTO_VAR_"+envName.ReplaceAllString(name, "_")+"="+value)
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,152 @@
+package run
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/ios"
+	"github.com/abdfnx/resto/tools"
+)
+
+const hooksRestofile = `hooks {
+   before "echo nonce=n-$RESTO_REQUEST"
+}
+
+request "signed" {
+   method "POST"
+   url "%[1]s/sign"
+
+   headers { X-Nonce "{{nonce}}" }
+
+   body {
+      content "{{nonce}}"
+   }
+
+   hooks {
+      before "grep -q '\"method\":\"POST\"' && echo nonce=n-42"
+      after "tr a-z A-Z"
+   }
+
+   capture { upper "regex:(N-\d+)" }
+
+   expect { body equals "N-42 N-42" }
+}
+
+request "shared" {
+   method "GET"
+   url "%[1]s/sign"
+
+   headers { X-Nonce "{{nonce}}" }
+
+   expect { body equals "n-shared" }
+}
+
+request "status" {
+   method "GET"
+   url "%[1]s/sign"
+
+   hooks {
+      after "printf '%%s %%s' $RESTO_STATUS $RESTO_METHOD"
+   }
+
+   expect { body equals "200 GET" }
+}
+
+request "failing" {
+   method "GET"
+   url "%[1]s/sign"
+
+   hooks {
+      before "echo no token >&2; exit 3"
+   }
+}
+
+request "noise" {
+   method "GET"
+   url "%[1]s/sign"
+
+   hooks {
+      before "echo fetching"
+   }
+}
+
+request "injection" {
+   method "GET"
+   url "%[1]s/sign"
+
+   vars { payload "x'; echo injected; '" }
+
+   hooks {
+      after "printf '%%s|%%s' \"$RESTO_VAR_payload\" '{{payload}}'"
+   }
+
+   expect { body equals """x'; echo injected; '|{{payload}}""" }
+}
+
+request "literal" {
+   method "POST"
+   url "%[1]s/sign"
+
+   headers { X-Nonce "got" }
+
+   body {
+      content "at {{home}}"
+   }
+
+   hooks {
+      before "echo home=env:HOME"
+   }
+
+   expect { body equals "got at env:HOME" }
+}
+`
+
+func TestRunHooks(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("the hooks run with bash")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		fmt.Fprint(w, strings.TrimSpace(r.Header.Get("X-Nonce")+" "+string(body)))
+	}))
+
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "Restofile")
+
+	if err := ioutil.WriteFile(path, []byte(fmt.Sprintf(hooksRestofile, server.URL)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	io, _, out, _ := ios.Test()
+
+	if err := runTests(&options.TestCommandOptions{IO: io, Paths: []string{path}}); err != tools.SilentError {
+		t.Fatalf("expected a SilentError for the failing hooks, got %v", err)
+	}
+
+	for _, expected := range []string{
+		"✓ signed",
+		"✓ shared",
+		"✓ status",
+		"✓ injection",
+		"✓ literal",
+		"X failing",
+		"Restofile:50:14: the before hook failed, exit status 3: no token",
+		"X noise",
+		`the before hook printed "fetching" on line 1, it can only print name=value lines`,
+		"7 requests, 5 passed, 0 failed, 2 errors",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the output to contain %q, got\n%s", expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package run
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/environment"
+	"github.com/abdfnx/resto/core/lint"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func LintCMD(f *factory.Factory) *cobra.Command {
+	opts := options.LintCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "lint [<path>...] [flags]",
+		Short: "Check Restofiles for mistakes",
+		Long: heredoc.Doc(`
+			Check Restofiles for unknown blocks and keys, requests without a method, a url or a body, invalid urls
+			and expect blocks, undefined variables and credentials written in plain text.
+
+			A path can be a Restofile or a directory, the Restofiles in a directory are found recursively.
+			The command exits with a non-zero status when there are errors, warnings are only reported.
+		`),
+		Example: heredoc.Doc(`
+			# Check ./Restofile
+			resto lint
+
+			# Check the variables of the staging environment
+			resto lint api --env staging
+
+			# Read the problems in another tool
+			resto lint --format json
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Paths = args
+
+			if opts.Format != "text" && opts.Format != "json" {
+				return &tools.FlagError{Err: fmt.Errorf("invalid format %q, it must be text or json", opts.Format)}
+			}
+
+			err := runLint(&opts)
+
+			// the problems are already reported, the usage doesn't help
+			if err == tools.SilentError {
+				cmd.SilenceUsage = true
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Env, "env", "e", "", "The environment to check the variables with (Default: the variables of all the environments are defined)")
+	cmd.Flags().StringVar(&opts.Format, "format", "text", "The output format: text or json")
+
+	return cmd
+}
+
+func runLint(opts *options.LintCommandOptions) error {
+	paths, err := restofiles(opts.Paths)
+
+	if err != nil {
+		return errors.Errorf("Error: %v", err)
+	}
+
+	diagnostics := []lint.Diagnostic{}
+
+	for _, path := range paths {
+		diagnostics = append(diagnostics, lintFile(opts, path)...)
+	}
+
+	errorsCount, warnings := 0, 0
+
+	for _, d := range diagnostics {
+		if d.Severity == lint.Error {
+			errorsCount++
+		} else {
+			warnings++
+		}
+	}
+
+	if opts.Format == "json" {
+		data, err := json.MarshalIndent(diagnostics, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(opts.IO.Out, string(data))
+	} else {
+		printDiagnostics(opts, diagnostics, errorsCount, warnings)
+	}
+
+	if errorsCount > 0 {
+		return tools.SilentError
+	}
+
+	return nil
+}
+
+func lintFile(opts *options.LintCommandOptions, path string) []lint.Diagnostic {
+	file, err := restofile.ParseFile(path)
+
+	if err != nil {
+		return []lint.Diagnostic{lint.Syntax(path, err)}
+	}
+
+	file, err = restofile.Resolve(file)
+
+	if err != nil {
+		return []lint.Diagnostic{lint.Include(path, err)}
+	}
+
+	envs, err := environment.ReadFile(filepath.Join(filepath.Dir(path), environment.FileName))
+
+	if err != nil && !os.IsNotExist(err) {
+		return []lint.Diagnostic{{File: path, Severity: lint.Error, Rule: "environment", Message: err.Error()}}
+	}
+
+	// like resto test, a file without environments only uses its vars blocks
+	if opts.Env != "" && (len(envs) > 0 || len(file.Blocks("env")) > 0) {
+		if err := checkEnv(file, opts.Env, envs); err != nil {
+			return []lint.Diagnostic{{File: path, Severity: lint.Error, Rule: "environment", Message: err.Error()}}
+		}
+	}
+
+	return lint.Check(file, envs, opts.Env)
+}
+
+func printDiagnostics(opts *options.LintCommandOptions, diagnostics []lint.Diagnostic, errorsCount, warnings int) {
+	cs := opts.IO.ColorScheme()
+	out := opts.IO.Out
+
+	for _, d := range diagnostics {
+		severity := cs.Red(d.Severity)
+
+		if d.Severity == lint.Warning {
+			severity = cs.Yellow(d.Severity)
+		}
+
+		fmt.Fprintf(out, "%s: %s: %s %s\n", d.Position(), severity, d.Message, cs.Gray("(" + d.Rule + ")"))
+	}
+
+	if len(diagnostics) == 0 {
+		fmt.Fprintf(out, "%s No problems found\n", cs.SuccessIcon())
+
+		return
+	}
+
+	fmt.Fprintf(out, "\n%d %s, %d %s, %d %s\n", len(diagnostics), plural(len(diagnostics), "problem"), errorsCount, plural(errorsCount, "error"), warnings, plural(warnings, "warning"))
+}
@@ -0,0 +1,327 @@
+package run
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/core/editor"
+	"github.com/abdfnx/resto/core/editor/runtime"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/tools"
+
+	tcell "github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// requestBlocks returns the request blocks of a Restofile that aren't abstract, names must be unique and only one request can be unnamed
+func requestBlocks(file *restofile.File) ([]*restofile.Block, error) {
+	blocks := file.Blocks("request")
+
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("%s has no request block", file.Path)
+	}
+
+	seen := map[string]*restofile.Block{}
+
+	var concrete []*restofile.Block
+
+	for _, block := range blocks {
+		name := block.Label()
+
+		if first, ok := seen[name]; ok {
+			if name == "" {
+				return nil, restofile.Errorf(block.Pos, "only one request can be unnamed, name it like request \"name\" { ... }")
+			}
+
+			return nil, restofile.Errorf(block.Pos, "duplicate request %q, it's already defined at %s", name, first.Pos)
+		}
+
+		seen[name] = block
+
+		if !block.Abstract() {
+			concrete = append(concrete, block)
+		}
+	}
+
+	if len(concrete) == 0 {
+		return nil, fmt.Errorf("%s only has abstract requests, add a request that extends one of them", file.Path)
+	}
+
+	return concrete, nil
+}
+
+// selectRequests returns the requests to send, the one called name, all of them or the only one of the Restofile
+func selectRequests(blocks []*restofile.Block, name string, all bool) ([]*restofile.Block, error) {
+	if all {
+		return blocks, nil
+	}
+
+	if name == "" {
+		if len(blocks) == 1 {
+			return blocks, nil
+		}
+
+		return nil, fmt.Errorf("the Restofile has %d requests, pick one of %s or use --all", len(blocks), strings.Join(requestNames(blocks), ", "))
+	}
+
+	for _, block := range blocks {
+		if block.Label() == name {
+			return []*restofile.Block{block}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there's no request called %q, it must be one of %s", name, strings.Join(requestNames(blocks), ", "))
+}
+
+// requestNames returns the names of the named requests
+func requestNames(blocks []*restofile.Block) []string {
+	var names []string
+
+	for _, block := range blocks {
+		if block.Label() != "" {
+			names = append(names, block.Label())
+		}
+	}
+
+	return names
+}
+
+// readRequest reads a request block, its body and auth blocks default to the top-level ones of the Restofile,
+// its headers and query params are added to the top-level ones
+func readRequest(file *restofile.File, block *restofile.Block, v *vars) (*options.Request, error) {
+	fn := tools.CLIRequestFile("txt")
+	cType := ""
+
+	openBodyEditor := false
+	content := ""
+
+	method := strings.ToUpper(v.value(block, "method"))
+	url := v.value(block, "url")
+	contentType := v.value(block, "contentType")
+
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	if method == "" || url == "" {
+		return nil, restofile.Errorf(block.Pos, "the request block needs a method and a url")
+	}
+
+	if method != "GET" && method != "HEAD" && method != "POST" && method != "PUT" && method != "PATCH" && method != "DELETE" {
+		return nil, restofile.Errorf(block.Entry("method").Pos, "unknown method %q, it must be GET, HEAD, POST, PUT, PATCH or DELETE", method)
+	}
+
+	if contentType != "" {
+		if contentType == "application/json" || contentType == "json" {
+			fn = tools.CLIRequestFile("json")
+			cType = "application/json"
+		} else if contentType == "application/graphql" || contentType == "graphql" {
+			fn = tools.CLIRequestFile("graphql")
+			cType = "application/graphql"
+		} else if contentType == "application/xml" || contentType == "xml" {
+			fn = tools.CLIRequestFile("xml")
+			cType = "application/xml"
+		} else if contentType == "text/html" || contentType == "html" {
+			fn = tools.CLIRequestFile("html")
+			cType = "text/html"
+		} else {
+			fn = tools.CLIRequestFile("txt")
+			cType = "text/plain"
+		}
+	}
+
+	if body := innerBlock(file, block, "body"); body != nil {
+		if body.Entry("content") != nil && body.Entry("readFrom") != nil {
+			return nil, restofile.Errorf(body.Entry("readFrom").Pos, "the body block has content and readFrom, keep one of them")
+		}
+
+		openBodyEditorValue := v.value(body, "openBodyEditor")
+
+		if openBodyEditorValue == "yes" || openBodyEditorValue == "true" {
+			openBodyEditor = true
+		}
+
+		if openBodyEditor {
+			data, err := editBody(fn)
+
+			if err != nil {
+				return nil, err
+			}
+
+			content = v.interpolate(body.Pos, data)
+		}
+
+		readFrom := v.value(body, "readFrom")
+
+		// the path is relative to the file readFrom is written in, which can be an included one
+		if readFrom != "" && !filepath.IsAbs(readFrom) {
+			readFrom = filepath.Join(filepath.Dir(body.Entry("readFrom").Pos.File), readFrom)
+		}
+
+		if readFrom != "" {
+			data, err := ioutil.ReadFile(readFrom)
+			if err != nil {
+				return nil, err
+			}
+
+			content = v.interpolate(body.Entry("readFrom").Pos, string(data))
+		}
+
+		// an inline body, """ strings are sent as written
+		inline := body.Entry("content")
+
+		if inline != nil {
+			content = inline.Value()
+
+			if !inline.Values[0].Raw {
+				content = v.interpolate(inline.Values[0].Pos, content)
+			}
+		}
+
+		if !openBodyEditor && readFrom == "" && inline == nil {
+			if method == "POST" || method == "PUT" || method == "PATCH" || method == "DELETE" {
+				return nil, restofile.Errorf(body.Pos, "body is required, set content, readFrom or openBodyEditor")
+			}
+		}
+	}
+
+	authBlock := innerBlock(file, block, "auth")
+	auth := &options.Auth{Type: v.value(authBlock, "type")}
+
+	provider, ok := api.LookupAuthProvider(auth.Type)
+
+	if auth.Type != "" && !ok {
+		return nil, restofile.Errorf(authBlock.Entry("type").Pos, "unknown auth type %q, it must be %s", auth.Type, strings.Join(api.AuthTypes(), ", "))
+	}
+
+	if ok {
+		// credentials are sent as written, so they can be redacted from the output, their `env:` and `secret:` values are resolved when the request is built
+		for _, field := range provider.Fields() {
+			if field.Resolve {
+				*field.Value(auth) = v.raw(authBlock, field.Key)
+			} else {
+				*field.Value(auth) = v.value(authBlock, field.Key)
+			}
+		}
+	}
+
+	headers, err := pairs(file, block, "headers", v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := pairs(file, block, "query", v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	if method == "GET" || method == "HEAD" {
+		cType = ""
+		content = ""
+	}
+
+	return &options.Request{
+		Method:      method,
+		URL:         url,
+		ContentType: cType,
+		Body:        content,
+		Headers:     headers,
+		Query:       query,
+		AuthType:    auth,
+	}, nil
+}
+
+// pairs returns the entries of the top-level block called name and of the one of a request, like the headers or the query params,
+// `env:NAME` and `secret:NAME` values are resolved
+func pairs(file *restofile.File, block *restofile.Block, name string, v *vars) (map[string]string, error) {
+	values := map[string]string{}
+
+	for _, b := range []*restofile.Block{file.Block(name), block.Block(name)} {
+		if b == nil {
+			continue
+		}
+
+		if nested := b.Blocks(""); len(nested) > 0 {
+			return nil, restofile.Errorf(nested[0].Pos, "the %s block can't hold a %s block", name, nested[0].Name)
+		}
+
+		for _, entry := range b.Entries() {
+			if len(entry.Values) > 1 {
+				return nil, restofile.Errorf(entry.Values[1].Pos, "%q takes a single value, quote it if it has spaces", entry.Key)
+			}
+
+			values[entry.Key] = v.resolve(entry.Values[0])
+		}
+	}
+
+	return values, nil
+}
+
+// innerBlock returns the block called name of a request, or the top-level one shared by all requests
+func innerBlock(file *restofile.File, block *restofile.Block, name string) *restofile.Block {
+	if inner := block.Block(name); inner != nil {
+		return inner
+	}
+
+	return file.Block(name)
+}
+
+// editBody opens the body file fn in resto editor and returns what was saved
+func editBody(fn string) (string, error) {
+	fileContent, err := ioutil.ReadFile(fn)
+	buffer := editor.NewBufferFromString(string(fileContent), fn)
+	if err != nil {
+		return "", fmt.Errorf("could not read %v: %v", fn, err)
+	}
+
+	var colorscheme editor.Colorscheme
+	if railscast := runtime.Files.FindFile(editor.RTColorscheme, "railscast"); railscast != nil {
+		if data, err := railscast.Data(); err == nil {
+			colorscheme = editor.ParseColorscheme(string(data))
+		}
+	}
+
+	bodyEditor := editor.NewView(buffer)
+	bodyEditor.SetRuntimeFiles(runtime.Files)
+	bodyEditor.SetColorscheme(colorscheme)
+
+	app := tview.NewApplication()
+	bodyEditor.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+			case tcell.KeyCtrlS:
+				tools.SaveBuffer(buffer, fn)
+				app.Stop()
+				return nil
+
+			case tcell.KeyCtrlQ:
+				app.Stop()
+				return nil
+		}
+
+		return event
+	})
+
+	app.SetRoot(bodyEditor, true)
+
+	if err := app.Run(); err != nil {
+		return "", err
+	}
+
+	body, err := ioutil.ReadFile(fn)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
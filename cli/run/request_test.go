@@ -0,0 +1,321 @@
+package run
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+)
+
+// loadRestofile writes files in a temporary directory and loads its Restofile
+func loadRestofile(t *testing.T, files map[string]string) *restofile.File {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := restofile.Load(filepath.Join(dir, "Restofile"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestRequestBlocks(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		src   string
+		names []string
+		err   string
+	}{
+		{
+			name:  "unnamed",
+			src:   `request { url "https://api.example.com" }`,
+			names: []string{""},
+		},
+		{
+			name:  "named",
+			src:   "request \"list\" { url \"https://api.example.com\" }\nrequest \"get\" { url \"https://api.example.com/1\" }\nrequest { url \"https://api.example.com\" }",
+			names: []string{"list", "get", ""},
+		},
+		{
+			name: "no request",
+			src:  `vars { id "1" }`,
+			err:  "has no request block",
+		},
+		{
+			name: "duplicate",
+			src:  "request \"list\" { url \"https://api.example.com\" }\nrequest \"list\" { url \"https://api.example.com\" }",
+			err:  `:2:1: duplicate request "list", it's already defined at`,
+		},
+		{
+			name:  "abstract",
+			src:   "request \"base\" {\n   url \"https://api.example.com\"\n   abstract \"true\"\n}\nrequest \"list\" { extends \"base\" }",
+			names: []string{"list"},
+		},
+		{
+			name: "only abstract",
+			src:  "request \"base\" {\n   url \"https://api.example.com\"\n   abstract \"true\"\n}",
+			err:  "only has abstract requests",
+		},
+		{
+			name: "two unnamed",
+			src:  "request { url \"https://api.example.com\" }\nrequest { url \"https://api.example.com\" }",
+			err:  ":2:1: only one request can be unnamed",
+		},
+	} {
+		blocks, err := requestBlocks(loadRestofile(t, map[string]string{"Restofile": c.src}))
+
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected an error with %q, got %v", c.name, c.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		var names []string
+
+		for _, block := range blocks {
+			names = append(names, block.Label())
+		}
+
+		if fmt.Sprint(names) != fmt.Sprint(c.names) {
+			t.Errorf("%s: expected the requests %q, got %q", c.name, c.names, names)
+		}
+	}
+}
+
+func TestSelectRequests(t *testing.T) {
+	named := "request \"list\" { url \"https://api.example.com\" }\nrequest \"get\" { url \"https://api.example.com/1\" }"
+
+	for _, c := range []struct {
+		name     string
+		src      string
+		request  string
+		all      bool
+		expected []string
+		err      string
+	}{
+		{name: "only request", src: `request { url "https://api.example.com" }`, expected: []string{""}},
+		{name: "by name", src: named, request: "get", expected: []string{"get"}},
+		{name: "all", src: named, all: true, expected: []string{"list", "get"}},
+		{name: "no name", src: named, err: "the Restofile has 2 requests, pick one of list, get or use --all"},
+		{name: "unknown name", src: named, request: "delete", err: `there's no request called "delete", it must be one of list, get`},
+	} {
+		blocks, err := requestBlocks(loadRestofile(t, map[string]string{"Restofile": c.src}))
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		selected, err := selectRequests(blocks, c.request, c.all)
+
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%s: expected the error %q, got %v", c.name, c.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		var names []string
+
+		for _, block := range selected {
+			names = append(names, block.Label())
+		}
+
+		if fmt.Sprint(names) != fmt.Sprint(c.expected) {
+			t.Errorf("%s: expected the requests %q, got %q", c.name, c.expected, names)
+		}
+	}
+}
+
+func TestReadRequest(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		files    map[string]string
+		expected options.Request
+		err      string
+	}{
+		{
+			name: "headers and query",
+			files: map[string]string{"Restofile": `headers { Accept "application/json" }
+
+request {
+   method "get"
+   url "https://api.example.com/{{id}}"
+
+   vars { id "42" }
+   headers { X-Tenant "acme" }
+   query { page "2" }
+}`},
+			expected: options.Request{
+				Method:  "GET",
+				URL:     "https://api.example.com/42",
+				Headers: map[string]string{"Accept": "application/json", "X-Tenant": "acme"},
+				Query:   map[string]string{"page": "2"},
+			},
+		},
+		{
+			name: "read from a file next to the Restofile",
+			files: map[string]string{
+				"Restofile": `request {
+   method "POST"
+   url "https://api.example.com"
+   contentType "json"
+
+   body { readFrom "bodies/user.json" }
+}`,
+				"bodies/user.json": `{"name": "resto"}`,
+			},
+			expected: options.Request{Method: "POST", URL: "https://api.example.com", ContentType: "application/json", Body: `{"name": "resto"}`},
+		},
+		{
+			name: "read from a file next to an included file",
+			files: map[string]string{
+				"Restofile": `include "shared/body.resto"
+
+request {
+   method "PUT"
+   url "https://api.example.com"
+}`,
+				"shared/body.resto": `body { readFrom "user.json" }`,
+				"shared/user.json":  `{"name": "shared"}`,
+			},
+			expected: options.Request{Method: "PUT", URL: "https://api.example.com", Body: `{"name": "shared"}`},
+		},
+		{
+			name: "bearer auth",
+			files: map[string]string{"Restofile": `request {
+   method "GET"
+   url "https://api.example.com"
+
+   auth {
+      type "bearer"
+      token "env:TOKEN"
+   }
+}`},
+			expected: options.Request{Method: "GET", URL: "https://api.example.com", AuthType: &options.Auth{Type: "bearer", TokenAuth: "env:TOKEN"}},
+		},
+		{
+			name: "raw strings",
+			files: map[string]string{"Restofile": `request {
+   method "GET"
+   url """https://api.example.com/{{id}}"""
+
+   vars { id "42" }
+   headers { X-Template """{{id}}""" }
+   query { q """{{id}}""" }
+
+   auth {
+      type "bearer"
+      token """{{id}}"""
+   }
+}`},
+			expected: options.Request{
+				Method:   "GET",
+				URL:      "https://api.example.com/{{id}}",
+				Headers:  map[string]string{"X-Template": "{{id}}"},
+				Query:    map[string]string{"q": "{{id}}"},
+				AuthType: &options.Auth{Type: "bearer", TokenAuth: "{{id}}"},
+			},
+		},
+		{
+			name: "content and readFrom",
+			files: map[string]string{"Restofile": `request {
+   method "POST"
+   url "https://api.example.com"
+
+   body {
+      content "{}"
+      readFrom "user.json"
+   }
+}`},
+			err: "Restofile:7:7: the body block has content and readFrom, keep one of them",
+		},
+		{
+			name: "unknown auth type",
+			files: map[string]string{"Restofile": `request {
+   method "GET"
+   url "https://api.example.com"
+
+   auth { type "ticket" }
+}`},
+			err: `Restofile:5:11: unknown auth type "ticket"`,
+		},
+		{
+			name: "missing body",
+			files: map[string]string{"Restofile": `request {
+   method "POST"
+   url "https://api.example.com"
+
+   body { openBodyEditor "false" }
+}`},
+			err: "Restofile:5:4: body is required, set content, readFrom or openBodyEditor",
+		},
+	} {
+		file := loadRestofile(t, c.files)
+		blocks, err := requestBlocks(file)
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		req, err := readRequest(file, blocks[0], newVars(file, blocks[0], "", nil, nil))
+
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected an error with %q, got %v", c.name, c.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if req.Method != c.expected.Method || req.URL != c.expected.URL || req.ContentType != c.expected.ContentType || req.Body != c.expected.Body {
+			t.Errorf("%s: expected %s %s %q %q, got %s %s %q %q", c.name, c.expected.Method, c.expected.URL, c.expected.ContentType, c.expected.Body, req.Method, req.URL, req.ContentType, req.Body)
+		}
+
+		if c.expected.Headers != nil && fmt.Sprint(req.Headers) != fmt.Sprint(c.expected.Headers) {
+			t.Errorf("%s: expected the headers %v, got %v", c.name, c.expected.Headers, req.Headers)
+		}
+
+		if c.expected.Query != nil && fmt.Sprint(req.Query) != fmt.Sprint(c.expected.Query) {
+			t.Errorf("%s: expected the query %v, got %v", c.name, c.expected.Query, req.Query)
+		}
+
+		if c.expected.AuthType != nil && *req.AuthType != *c.expected.AuthType {
+			t.Errorf("%s: expected the auth %+v, got %+v", c.name, c.expected.AuthType, req.AuthType)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/core/capture"
+	"github.com/abdfnx/resto/core/environment"
+	"github.com/abdfnx/resto/core/expect"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func TestCMD(f *factory.Factory) *cobra.Command {
+	opts := options.TestCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "test [<path>...] [flags]",
+		Short: "Send the requests of Restofiles and check their expect blocks",
+		Long: heredoc.Doc(`
+			Send every request of the Restofiles in order and check the assertions of their expect blocks,
+			the values captured by a request can be used by the next ones, also in the next Restofiles.
+
+			A path can be a Restofile or a directory, the Restofiles in a directory are found recursively.
+			The command exits with a non-zero status when a request fails.
+		`),
+		Example: heredoc.Doc(`
+			# Test the requests of ./Restofile
+			resto test
+
+			# Test all the Restofiles of the api directory with the variables of the staging environment
+			resto test api --env staging
+
+			# Write the results for the CI
+			resto test --junit report.xml --tap report.tap
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Paths = args
+
+			err := runTests(&opts)
+
+			// the failures are already reported, the usage doesn't help
+			if err == tools.SilentError {
+				cmd.SilenceUsage = true
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Env, "env", "e", "", "The environment of the variables, an env block of the Restofiles or an environment of " + environment.FileName)
+	cmd.Flags().StringVar(&opts.State, "state", "", "A file to read the captured values from, and to save them to")
+	cmd.Flags().StringVar(&opts.JUnit, "junit", "", "Write the results to a file as JUnit XML")
+	cmd.Flags().StringVar(&opts.TAP, "tap", "", "Write the results to a file in the Test Anything Protocol")
+
+	return cmd
+}
+
+func runTests(opts *options.TestCommandOptions) error {
+	paths, err := restofiles(opts.Paths)
+
+	if err != nil {
+		return errors.Errorf("Error: %v", err)
+	}
+
+	captured := map[string]string{}
+
+	if opts.State != "" {
+		captured, err = capture.ReadState(opts.State)
+
+		if err != nil {
+			return errors.Errorf("Error: %v", err)
+		}
+	}
+
+	var suites []expect.Suite
+
+	for _, path := range paths {
+		suite := testFile(opts, path, captured)
+
+		printSuite(opts, suite)
+
+		suites = append(suites, suite)
+	}
+
+	if opts.JUnit != "" {
+		if err := writeReport(opts.JUnit, suites, expect.WriteJUnit); err != nil {
+			return errors.Errorf("Error: %v", err)
+		}
+	}
+
+	if opts.TAP != "" {
+		if err := writeReport(opts.TAP, suites, expect.WriteTAP); err != nil {
+			return errors.Errorf("Error: %v", err)
+		}
+	}
+
+	if !printSummary(opts, suites) {
+		return tools.SilentError
+	}
+
+	return nil
+}
+
+// restofiles returns the Restofiles of paths, the directories are walked for files called Restofile
+func restofiles(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{"./Restofile"}, nil
+	}
+
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+
+			continue
+		}
+
+		var found []string
+
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() && p != path && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			if !info.IsDir() && info.Name() == "Restofile" {
+				found = append(found, p)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(found) == 0 {
+			return nil, fmt.Errorf("there's no Restofile in %s", path)
+		}
+
+		sort.Strings(found)
+
+		files = append(files, found...)
+	}
+
+	return files, nil
+}
+
+// testFile sends the requests of a Restofile and checks them, an error that stops the whole file is a single case
+func testFile(opts *options.TestCommandOptions, path string, captured map[string]string) expect.Suite {
+	start := time.Now()
+	suite := expect.Suite{Name: path}
+
+	fileError := func(err error) expect.Suite {
+		suite.Cases = []expect.Case{{Name: "(file)", Err: err}}
+		suite.Duration = time.Since(start)
+
+		return suite
+	}
+
+	file, err := restofile.Load(path)
+
+	if err != nil {
+		return fileError(err)
+	}
+
+	blocks, err := requestBlocks(file)
+
+	if err != nil {
+		return fileError(err)
+	}
+
+	envs, err := environment.ReadFile(filepath.Join(filepath.Dir(file.Path), environment.FileName))
+
+	if err != nil && !os.IsNotExist(err) {
+		return fileError(err)
+	}
+
+	// a file without environments only uses its vars blocks, so a directory can mix both kinds
+	if opts.Env != "" && (len(envs) > 0 || len(file.Blocks("env")) > 0) {
+		if err := checkEnv(file, opts.Env, envs); err != nil {
+			return fileError(err)
+		}
+	}
+
+	for _, block := range blocks {
+		suite.Cases = append(suite.Cases, testRequest(opts, file, block, envs, captured))
+	}
+
+	suite.Duration = time.Since(start)
+
+	return suite
+}
+
+func testRequest(opts *options.TestCommandOptions, file *restofile.File, block *restofile.Block, envs environment.Environments, captured map[string]string) expect.Case {
+	start := time.Now()
+	c := expect.Case{Name: requestName(block)}
+
+	v := newVars(file, block, opts.Env, envs, captured)
+
+	err := runBefore(file, block, v)
+
+	if err != nil {
+		c.Err = err
+		c.Duration = time.Since(start)
+
+		return c
+	}
+
+	req, err := readRequest(file, block, v)
+
+	if err != nil {
+		c.Err = err
+		c.Duration = time.Since(start)
+
+		return c
+	}
+
+	var assertions []expect.Assertion
+
+	if expectBlock := block.Block("expect"); expectBlock != nil {
+		assertions, err = expect.Parse(expectBlock, v.interpolate)
+
+		if err == nil {
+			err = v.err
+		}
+
+		if err != nil {
+			c.Err = err
+			c.Duration = time.Since(start)
+
+			return c
+		}
+	}
+
+	res, err := api.Exchange(req)
+
+	if err != nil {
+		c.Err = err
+		c.Duration = time.Since(start)
+
+		return c
+	}
+
+	c.Duration = res.Duration
+
+	if err := runAfter(file, block, v, req, res); err != nil {
+		c.Err = err
+
+		return c
+	}
+
+	if err := captureValues(opts.State, block, res, captured); err != nil {
+		c.Err = err
+
+		return c
+	}
+
+	for _, assertion := range assertions {
+		c.Results = append(c.Results, assertion.Check(res))
+	}
+
+	return c
+}
+
+func printSuite(opts *options.TestCommandOptions, suite expect.Suite) {
+	cs := opts.IO.ColorScheme()
+	out := opts.IO.Out
+
+	fmt.Fprintln(out, cs.Bold(suite.Name))
+
+	for _, c := range suite.Cases {
+		duration := cs.Gray(c.Duration.Round(time.Millisecond).String())
+
+		if c.Passed() {
+			fmt.Fprintf(out, "  %s %s %s\n", cs.SuccessIcon(), c.Name, duration)
+
+			continue
+		}
+
+		fmt.Fprintf(out, "  %s %s %s\n", cs.FailureIcon(), c.Name, duration)
+
+		if c.Err != nil {
+			fmt.Fprintln(out, indent(cs.Red(c.Err.Error()), "      "))
+
+			continue
+		}
+
+		for _, failure := range c.Failures() {
+			fmt.Fprintln(out, indent(cs.Gray(failure.Assertion.Pos.String() + ":") + " " + failure.Assertion.String(), "      "))
+			fmt.Fprintln(out, indent(colorDetail(cs.Red, cs.Green, failure.Detail()), "        "))
+		}
+	}
+
+	fmt.Fprintln(out, "")
+}
+
+// colorDetail colors the removed lines of a diff, and the added ones
+func colorDetail(removed, added func(string) string, detail string) string {
+	lines := strings.Split(detail, "\n")
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, "- ") {
+			lines[i] = removed(line)
+		} else if strings.HasPrefix(line, "+ ") {
+			lines[i] = added(line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n" + prefix)
+}
+
+// printSummary prints the counts of the requests, and reports whether all of them passed
+func printSummary(opts *options.TestCommandOptions, suites []expect.Suite) bool {
+	cs := opts.IO.ColorScheme()
+
+	total, passed, failed, errored := 0, 0, 0, 0
+
+	for _, suite := range suites {
+		for _, c := range suite.Cases {
+			total++
+
+			if c.Err != nil {
+				errored++
+			} else if c.Passed() {
+				passed++
+			} else {
+				failed++
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("%d %s, %d passed, %d failed, %d %s", total, plural(total, "request"), passed, failed, errored, plural(errored, "error"))
+
+	if passed == total {
+		fmt.Fprintln(opts.IO.Out, cs.Green(summary))
+
+		return true
+	}
+
+	fmt.Fprintln(opts.IO.Out, cs.Red(summary))
+
+	return false
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+
+	return word + "s"
+}
+
+func writeReport(path string, suites []expect.Suite, write func(w io.Writer, suites []expect.Suite) error) error {
+	f, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if err := write(f, suites); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
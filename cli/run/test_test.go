@@ -0,0 +1,233 @@
+package run
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/ios"
+	"github.com/abdfnx/resto/tools"
+)
+
+const testRestofile = `
+vars {
+  baseUrl "%s"
+}
+
+request "login" {
+  method "POST"
+  url "{{baseUrl}}/login"
+
+  capture {
+    token "json:token"
+  }
+
+  expect {
+    status 200
+    header "Content-Type" contains "json"
+    json "token" type "string"
+  }
+}
+
+request "me" {
+  method "GET"
+  url "{{baseUrl}}/me"
+
+  auth {
+    type "bearer"
+    token "{{token}}"
+  }
+
+  expect {
+    status 2xx
+    json "user" equals "{ \"id\": 1, \"name\": \"resto\" }"
+  }
+}
+
+request "missing" {
+  method "GET"
+  url "{{baseUrl}}/missing"
+
+  expect {
+    status 200
+  }
+}
+
+request "logout" {
+  method "POST"
+  url "{{baseUrl}}/logout/{{session}}"
+}
+`
+
+func TestRunTests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/login":
+			fmt.Fprint(w, `{"token": "t0k3n"}`)
+		case "/me":
+			if r.Header.Get("Authorization") != "Bearer t0k3n" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			fmt.Fprint(w, `{"user": {"id": 1, "name": "resto"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Restofile")
+
+	if err := ioutil.WriteFile(path, []byte(fmt.Sprintf(testRestofile, server.URL)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	io, _, out, _ := ios.Test()
+
+	opts := &options.TestCommandOptions{
+		IO:    io,
+		Paths: []string{dir},
+		JUnit: filepath.Join(dir, "report.xml"),
+		TAP:   filepath.Join(dir, "report.tap"),
+	}
+
+	if err := runTests(opts); err != tools.SilentError {
+		t.Fatalf("expected a SilentError for the failures, got %v", err)
+	}
+
+	for _, expected := range []string{
+		"✓ login",
+		"✓ me",
+		"X missing",
+		"Restofile:41:5: status 200",
+		"expected: 200",
+		"actual:   404",
+		"X logout",
+		`undefined variable "session"`,
+		"4 requests, 2 passed, 1 failed, 1 error",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the output to contain %q, got\n%s", expected, out.String())
+		}
+	}
+
+	junit, err := ioutil.ReadFile(opts.JUnit)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Errors   int `xml:"errors,attr"`
+	}
+
+	if err := xml.Unmarshal(junit, &report); err != nil || report.Tests != 4 || report.Failures != 1 || report.Errors != 1 {
+		t.Errorf("expected 4 tests, 1 failure and 1 error in the JUnit report, got %+v (%v)", report, err)
+	}
+
+	tap, err := ioutil.ReadFile(opts.TAP)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(tap), "TAP version 13\n1..4\nok 1 - "+path+" login\nok 2 - "+path+" me\nnot ok 3 - ") {
+		t.Errorf("unexpected TAP report\n%s", tap)
+	}
+}
+
+func TestRunTestsPass(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "Restofile")
+	src := fmt.Sprintf("request {\n  method \"GET\"\n  url %q\n\n  expect {\n    body equals \"ok\"\n  }\n}\n", server.URL)
+
+	if err := ioutil.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	io, _, out, _ := ios.Test()
+
+	if err := runTests(&options.TestCommandOptions{IO: io, Paths: []string{path}}); err != nil {
+		t.Fatalf("expected the tests to pass, got %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "1 request, 1 passed, 0 failed, 0 errors") {
+		t.Errorf("unexpected output\n%s", out.String())
+	}
+}
+
+func TestRunTestsInlineBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}))
+
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "Restofile")
+	src := fmt.Sprintf(`vars { name "alice" }
+
+request "heredoc" {
+   method "POST"
+   url %q
+   contentType "json"
+
+   body {
+      content <<EOF
+      {"name": "{{name}}"}
+      EOF
+   }
+
+   expect { body equals <<EOF
+      {"name": "alice"}
+      EOF
+   }
+}
+
+request "raw" {
+   method "POST"
+   url %q
+
+   body {
+      content """
+      Hello {{name}}
+      """
+   }
+
+   expect { body equals """Hello {{name}}""" }
+}
+`, server.URL, server.URL)
+
+	if err := ioutil.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	io, _, out, _ := ios.Test()
+
+	if err := runTests(&options.TestCommandOptions{IO: io, Paths: []string{path}}); err != nil {
+		t.Fatalf("expected the tests to pass, got %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "2 requests, 2 passed, 0 failed, 0 errors") {
+		t.Errorf("unexpected output\n%s", out.String())
+	}
+}
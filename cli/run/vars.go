@@ -0,0 +1,163 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abdfnx/resto/core/environment"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/tools"
+)
+
+// vars replaces the {{name}} variables of the values of a request, the first undefined variable is kept in err
+type vars struct {
+	values map[string]string
+
+	// captured are the values of the responses and of the before hook, they're never resolved so a server
+	// or a hook can't read the env variables or the vault
+	captured map[string]string
+
+	env string
+	err error
+}
+
+// newVars collects the variables of a request, from the lowest precedence to the highest: the top-level vars blocks,
+// the vars blocks of the request, the env block of the Restofile, the environment file and the captured values
+func newVars(file *restofile.File, block *restofile.Block, env string, envs environment.Environments, captured map[string]string) *vars {
+	v := &vars{values: map[string]string{}, captured: map[string]string{}, env: env}
+
+	blocks := append(file.Blocks("vars"), block.Blocks("vars")...)
+
+	if env != "" {
+		for _, envBlock := range file.Blocks("env") {
+			if envBlock.Label() == env {
+				blocks = append(blocks, envBlock)
+			}
+		}
+	}
+
+	for _, b := range blocks {
+		for _, entry := range b.Entries() {
+			v.values[entry.Key] = entry.Value()
+		}
+	}
+
+	for key, value := range envs[env] {
+		v.values[key] = value
+	}
+
+	for key, value := range captured {
+		v.captured[key] = value
+	}
+
+	return v
+}
+
+// checkEnv returns an error when env is neither an env block of the Restofile nor an environment of the environment file
+func checkEnv(file *restofile.File, env string, envs environment.Environments) error {
+	if _, ok := envs[env]; ok {
+		return nil
+	}
+
+	names := envs.Names()
+
+	for _, block := range file.Blocks("env") {
+		if block.Label() == env {
+			return nil
+		}
+
+		names = append(names, block.Label())
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("there's no %s environment, add an env %q { ... } block or a %s file", env, env, environment.FileName)
+	}
+
+	return fmt.Errorf("there's no %s environment, it must be one of %s", env, strings.Join(names, ", "))
+}
+
+// raw returns the value of key with its variables replaced, `env:` and `secret:` values are kept for the request engine,
+// so a value made from captured values can't be one. The variables of """ strings aren't replaced
+func (v *vars) raw(block *restofile.Block, key string) string {
+	entry := block.Entry(key)
+
+	if entry == nil {
+		return ""
+	}
+
+	if entry.Values[0].Raw {
+		return entry.Value()
+	}
+
+	value := v.interpolate(entry.Values[0].Pos, entry.Value())
+
+	if v.usesCaptured(entry.Value()) && tools.IsReference(value) && v.err == nil {
+		v.err = restofile.Errorf(entry.Values[0].Pos, "%s is made from captured values, it can't be an env: or secret: reference", key)
+	}
+
+	return value
+}
+
+// value returns the value of key with its variables replaced and its `env:` and `secret:` references resolved,
+// a reference that can't be resolved is kept in err
+func (v *vars) value(block *restofile.Block, key string) string {
+	entry := block.Entry(key)
+
+	if entry == nil {
+		return ""
+	}
+
+	return v.resolve(entry.Values[0])
+}
+
+// resolve returns a value with its variables replaced and its `env:` and `secret:` reference resolved.
+// The variables of """ strings aren't replaced, and values made from captured values are used as they are
+func (v *vars) resolve(value restofile.Value) string {
+	out := value.Text
+
+	if !value.Raw {
+		out = v.interpolate(value.Pos, value.Text)
+
+		if v.usesCaptured(value.Text) {
+			return out
+		}
+	}
+
+	resolved, err := tools.ResolveValue(out)
+
+	if err != nil && v.err == nil {
+		v.err = restofile.Errorf(value.Pos, "%v", err)
+	}
+
+	return resolved
+}
+
+// usesCaptured reports whether s has variables of the captured values
+func (v *vars) usesCaptured(s string) bool {
+	for _, name := range environment.References(s) {
+		if _, ok := v.captured[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// interpolate replaces the variables of s, pos is where s is written in the Restofile
+func (v *vars) interpolate(pos restofile.Pos, s string) string {
+	out, err := environment.InterpolateLiterals(s, v.values, v.captured)
+
+	if err != nil && v.err == nil {
+		if undefined, ok := err.(*environment.UndefinedError); ok {
+			if v.env == "" {
+				err = fmt.Errorf("%v, define it in a vars block, capture it or pick an environment with --env", undefined)
+			} else {
+				err = fmt.Errorf("%v, it's not in the vars blocks, the captured values or the %s environment", undefined, v.env)
+			}
+		}
+
+		v.err = restofile.Errorf(pos, "%v", err)
+	}
+
+	return out
+}
@@ -3,16 +3,19 @@ package cli
 import (
 	// "encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	// "strings"
 
 	httpClient "github.com/abdfnx/resto/client"
 	"github.com/abdfnx/resto/core/api"
 	"github.com/abdfnx/resto/core/editor"
 	"github.com/abdfnx/resto/core/editor/runtime"
+	"github.com/abdfnx/resto/core/export"
 	"github.com/abdfnx/resto/core/options"
 	"github.com/abdfnx/resto/tools"
 
@@ -22,20 +25,29 @@ import (
 )
 
 func runBasic(opts *options.CLIOptions, method string) error {
-	if opts.Method.AuthType.BasicAuthUsername != "" && opts.Method.AuthType.BasicAuthPassword != "" {
-		opts.Method.AuthType.Type = "basic"
-	} else if opts.Method.AuthType.TokenAuth != "" {
-		opts.Method.AuthType.Type = "bearer"
+	if err := checkAuth(opts.Method.AuthType); err != nil {
+		return err
+	}
+
+	api.WarnExpiredToken(opts.IO, opts.Method.AuthType)
+
+	if opts.Method.Print != "" {
+		return printRequest(
+			opts.IO.Out,
+			opts.Method.Print,
+			opts.URL,
+			method,
+			"",
+			"",
+			opts.Method.AuthType,
+		)
 	}
 
 	respone, status, requestHeaders, err := api.BasicGet(
 		opts.URL,
 		method,
-		opts.Method.AuthType.Type,
-		opts.Method.AuthType.TokenAuth,
-		opts.Method.AuthType.BasicAuthUsername,
-		opts.Method.AuthType.BasicAuthPassword,
-		true,
+		opts.Method.AuthType,
+		isColored(opts),
 		0,
 		nil,
 	)
@@ -44,51 +56,16 @@ func runBasic(opts *options.CLIOptions, method string) error {
 		return err
 	}
 
-	if opts.Method.JustShowHeaders {
-		fmt.Println(requestHeaders)
-		fmt.Println("")
-		fmt.Println(status)
-	} else if opts.Method.JustShowBody {
-		fmt.Println("")
-		fmt.Println(respone)
-	} else if opts.Method.SaveFile != "" {
-		respone, _, _, _ := api.BasicGet(
-			opts.URL,
-			method,
-			opts.Method.AuthType.Type,
-			opts.Method.AuthType.TokenAuth,
-			opts.Method.AuthType.BasicAuthUsername,
-			opts.Method.AuthType.BasicAuthPassword,
-			false,
-			0,
-			nil,
-		)
-
-		data := []byte(respone)
-
-		err := os.WriteFile(opts.Method.SaveFile, data, 0644)
-
-		if err != nil {
-			return err
-		}
-	} else {
-		fmt.Println(requestHeaders)
-		fmt.Println("")
-		fmt.Println(status)
-		fmt.Println("")
-		fmt.Println(string(respone))
-	}
-
-	return nil
+	return printResponse(opts, respone, status, requestHeaders)
 }
 
 func runWithBody(opts *options.CLIOptions, method string) error {
-	if opts.Method.AuthType.BasicAuthUsername != "" && opts.Method.AuthType.BasicAuthPassword != "" {
-		opts.Method.AuthType.Type = "basic"
-	} else if opts.Method.AuthType.TokenAuth != "" {
-		opts.Method.AuthType.Type = "bearer"
+	if err := checkAuth(opts.Method.AuthType); err != nil {
+		return err
 	}
 
+	api.WarnExpiredToken(opts.IO, opts.Method.AuthType)
+
 	fn := tools.CLIRequestFile("txt")
 	by := string(opts.Method.Body)
 	cType := ""
@@ -174,16 +151,25 @@ func runWithBody(opts *options.CLIOptions, method string) error {
 		by = string(std)
 	}
 
+	if opts.Method.Print != "" {
+		return printRequest(
+			opts.IO.Out,
+			opts.Method.Print,
+			opts.URL,
+			method,
+			cType,
+			by,
+			opts.Method.AuthType,
+		)
+	}
+
 	respone, status, requestHeaders, err := api.BasicRequestWithBody(
 		opts.URL,
 		method,
 		cType,
 		by,
-		opts.Method.AuthType.Type,
-		opts.Method.AuthType.TokenAuth,
-		opts.Method.AuthType.BasicAuthUsername,
-		opts.Method.AuthType.BasicAuthPassword,
-		true,
+		opts.Method.AuthType,
+		isColored(opts),
 		0,
 		nil,
 	)
@@ -192,43 +178,120 @@ func runWithBody(opts *options.CLIOptions, method string) error {
 		return err
 	}
 
-	if opts.Method.JustShowHeaders {
-		fmt.Println(requestHeaders)
-		fmt.Println("")
-		fmt.Println(status)
-	} else if opts.Method.JustShowBody {
-		fmt.Println("")
-		fmt.Println(respone)
-	} else if opts.Method.SaveFile != "" {
-		respone, _, _, _ := api.BasicRequestWithBody(
-			opts.URL,
-			method,
-			cType,
-			by,
-			opts.Method.AuthType.Type,
-			opts.Method.AuthType.TokenAuth,
-			opts.Method.AuthType.BasicAuthUsername,
-			opts.Method.AuthType.BasicAuthPassword,
-			false,
-			0,
-			nil,
-		)
+	return printResponse(opts, respone, status, requestHeaders)
+}
+
+// checkAuth guesses the auth type from the given credentials when --auth-type isn't set
+func checkAuth(auth *options.Auth) error {
+	if auth.Type == "" {
+		auth.Type = api.DetectAuthType(auth)
+
+		return nil
+	}
+
+	provider, ok := api.LookupAuthProvider(auth.Type)
+
+	if !ok {
+		return &tools.FlagError{Err: fmt.Errorf("unknown auth type %q, it must be %s", auth.Type, strings.Join(api.AuthTypes(), ", "))}
+	}
 
-		data := []byte(respone)
+	if err := provider.Validate(auth); err != nil {
+		return &tools.FlagError{Err: err}
+	}
+
+	return nil
+}
+
+// isColored reports whether the response can be colored while it's formatted,
+// saved and filtered responses need the plain body
+func isColored(opts *options.CLIOptions) bool {
+	return opts.IO.ColorEnabled() && opts.Method.SaveFile == "" && opts.Method.Filter == ""
+}
 
-		err := os.WriteFile(opts.Method.SaveFile, data, 0644)
+// printResponse shows or saves the response according to the output flags
+func printResponse(opts *options.CLIOptions, respone, status, requestHeaders string) error {
+	method := opts.Method
+
+	if method.SaveFile != "" {
+		if method.Filter != "" {
+			filtered, err := api.FilterResponse(respone, method.Filter, method.RawOutput)
+
+			if err != nil {
+				return err
+			}
+
+			respone = filtered
+		}
+
+		return os.WriteFile(method.SaveFile, []byte(respone), 0644)
+	}
+
+	if !method.NoPager {
+		if err := opts.IO.StartPager(); err != nil {
+			fmt.Fprintf(opts.IO.ErrOut, "error starting pager: %v\n", err)
+		}
+
+		defer opts.IO.StopPager()
+	}
+
+	out := opts.IO.Out
+
+	if method.JustShowHeaders {
+		fmt.Fprintln(out, requestHeaders)
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, status)
+	} else if method.Filter != "" {
+		filtered, err := api.FilterResponse(respone, method.Filter, method.RawOutput)
 
 		if err != nil {
 			return err
 		}
+
+		if method.RawOutput || !opts.IO.ColorEnabled() {
+			fmt.Fprintln(out, filtered)
+		} else {
+			fmt.Fprintln(out, api.Colorize(filtered))
+		}
+	} else if method.JustShowBody {
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, respone)
 	} else {
-		fmt.Println(requestHeaders)
-		fmt.Println("")
-		fmt.Println(status)
-		fmt.Println("")
-		fmt.Println(string(respone))
+		fmt.Fprintln(out, requestHeaders)
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, status)
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, respone)
+	}
+
+	return nil
+}
+
+// printRequest writes the request to out as a snippet of the given format instead of sending it
+func printRequest(out io.Writer, format, url, method, contentType, body string, auth *options.Auth) error {
+	req, err := api.BuildRequest(
+		url,
+		method,
+		contentType,
+		body,
+		auth,
+		0,
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	api.RedactRequest(req, auth)
+
+	snippet, err := export.Generate(format, req)
+
+	if err != nil {
+		return err
 	}
 
+	fmt.Fprintln(out, snippet)
+
 	return nil
 }
 
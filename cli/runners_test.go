@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/ios"
+)
+
+func TestPrintRequest(t *testing.T) {
+	var out bytes.Buffer
+
+	auth := &options.Auth{Type: "bearer", TokenAuth: "s3cr3t-t0k3n"}
+
+	if err := printRequest(&out, "curl", "https://api.example.com/users", "POST", "application/json", `{"name": "resto"}`, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"-X POST",
+		"'https://api.example.com/users'",
+		"-H 'Content-Type: application/json'",
+		`--data-raw '{"name": "resto"}'`,
+		"-H 'Authorization: Bearer s3cr3t-t0k3n'",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the snippet to contain %q, got\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestPrintRequestErrors(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := printRequest(&out, "cobol", "https://api.example.com", "GET", "", "", &options.Auth{}); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+
+	if err := printRequest(&out, "curl", "not a url", "GET", "", "", &options.Auth{}); err == nil {
+		t.Errorf("expected an error for an invalid url")
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to be printed, got %q", out.String())
+	}
+}
+
+func TestPrintResponseFilter(t *testing.T) {
+	response := `{"user": {"login": "resto"}}`
+
+	io, _, out, _ := ios.Test()
+
+	opts := &options.CLIOptions{IO: io, Method: &options.Method{Filter: "user.login", RawOutput: true}}
+
+	if err := printResponse(opts, response, "200 OK", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "resto\n" {
+		t.Errorf("expected the raw value, got %q", out.String())
+	}
+
+	io, _, out, _ = ios.Test()
+	io.SetColorEnabled(true)
+
+	opts = &options.CLIOptions{IO: io, Method: &options.Method{Filter: "user"}}
+
+	if err := printResponse(opts, response, "200 OK", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "\x1b[") || !strings.Contains(out.String(), "resto") {
+		t.Errorf("expected the filtered value to be colored, got %q", out.String())
+	}
+
+	io, _, _, _ = ios.Test()
+
+	opts = &options.CLIOptions{IO: io, Method: &options.Method{Filter: "user"}}
+
+	if err := printResponse(opts, "<html></html>", "200 OK", ""); err == nil {
+		t.Errorf("expected an error when filtering a response that isn't JSON")
+	}
+}
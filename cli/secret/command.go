@@ -0,0 +1,48 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/vault"
+	"github.com/abdfnx/resto/ios"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+func SecretCMD(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage the encrypted secret vault",
+		Long:  `Store secrets in ~/.resto/vault.json, encrypted with a passphrase, and reference them as secret:NAME in flags, settings, Restofiles and resto UI.`,
+	}
+
+	cmd.AddCommand(SecretSet(f), SecretGet(f), SecretList(f), SecretRemove(f))
+
+	return cmd
+}
+
+// OpenVault unlocks the vault, the passphrase of a new vault is asked twice so a typo doesn't lock it
+func OpenVault(io *ios.IOStreams) (*vault.Vault, error) {
+	if vault.Exists() || os.Getenv(vault.PassphraseEnv) != "" || !io.CanPrompt() {
+		return vault.Unlocked()
+	}
+
+	var passphrase, confirm string
+
+	if err := survey.AskOne(&survey.Password{Message: "New vault passphrase:"}, &passphrase, survey.WithValidator(survey.Required)); err != nil {
+		return nil, err
+	}
+
+	if err := survey.AskOne(&survey.Password{Message: "Confirm the passphrase:"}, &confirm); err != nil {
+		return nil, err
+	}
+
+	if passphrase != confirm {
+		return nil, fmt.Errorf("the passphrases don't match")
+	}
+
+	return vault.Unlock(passphrase)
+}
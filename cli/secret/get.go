@@ -0,0 +1,47 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/options"
+
+	"github.com/spf13/cobra"
+)
+
+func SecretGet(f *factory.Factory) *cobra.Command {
+	opts := options.SecretCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "Print a secret of the vault",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+
+			return runGet(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func runGet(opts *options.SecretCommandOptions) error {
+	v, err := OpenVault(opts.IO)
+
+	if err != nil {
+		return err
+	}
+
+	value, ok := v.Get(opts.Name)
+
+	if !ok {
+		return fmt.Errorf("no secret called %q", opts.Name)
+	}
+
+	fmt.Fprintln(opts.IO.Out, value)
+
+	return nil
+}
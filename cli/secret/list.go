@@ -0,0 +1,48 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/vault"
+
+	"github.com/spf13/cobra"
+)
+
+func SecretList(f *factory.Factory) *cobra.Command {
+	opts := options.SecretCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the names of the secrets in the vault",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func runList(opts *options.SecretCommandOptions) error {
+	if !vault.Exists() {
+		fmt.Fprintln(opts.IO.ErrOut, "no secrets, add some with `resto secret set <name>`")
+		return nil
+	}
+
+	v, err := OpenVault(opts.IO)
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range v.Names() {
+		fmt.Fprintln(opts.IO.Out, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/vault"
+
+	"github.com/spf13/cobra"
+)
+
+func SecretRemove(f *factory.Factory) *cobra.Command {
+	opts := options.SecretCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"remove"},
+		Short:   "Remove a secret from the vault",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+
+			return runRemove(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func runRemove(opts *options.SecretCommandOptions) error {
+	if !vault.Exists() {
+		return fmt.Errorf("no secret called %q", opts.Name)
+	}
+
+	v, err := OpenVault(opts.IO)
+
+	if err != nil {
+		return err
+	}
+
+	if !v.Remove(opts.Name) {
+		return fmt.Errorf("no secret called %q", opts.Name)
+	}
+
+	if err := v.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(opts.IO.Out, "%s Removed %s\n", opts.IO.ColorScheme().SuccessIcon(), opts.Name)
+
+	return nil
+}
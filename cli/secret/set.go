@@ -0,0 +1,88 @@
+package secret
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+func SecretSet(f *factory.Factory) *cobra.Command {
+	opts := options.SecretCommandOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "set <name> [value]",
+		Short: "Store a secret in the vault",
+		Long:  `Store a secret in the vault, the value is prompted when it's not given so it doesn't end up in your shell history.`,
+		Example: heredoc.Doc(`
+			resto secret set GITHUB_TOKEN
+			cat key.txt | resto secret set API_KEY --stdin
+
+			# then use it
+			resto get https://api.github.com/user --token secret:GITHUB_TOKEN
+		`),
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+
+			if len(args) > 1 {
+				opts.Value = args[1]
+			}
+
+			return runSet(&opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.ValueStdin, "stdin", false, "Read the value from stdin")
+
+	return cmd
+}
+
+func runSet(opts *options.SecretCommandOptions) error {
+	if strings.TrimSpace(opts.Name) == "" || strings.ContainsAny(opts.Name, " \t\r\n") {
+		return &tools.FlagError{Err: fmt.Errorf("invalid secret name %q, it can't contain spaces", opts.Name)}
+	}
+
+	if opts.ValueStdin {
+		value, err := ioutil.ReadAll(opts.IO.In)
+
+		if err != nil {
+			return err
+		}
+
+		opts.Value = strings.TrimRight(string(value), "\r\n")
+	} else if opts.Value == "" && opts.IO.CanPrompt() {
+		if err := survey.AskOne(&survey.Password{Message: opts.Name + ":"}, &opts.Value); err != nil {
+			return err
+		}
+	}
+
+	if opts.Value == "" {
+		return &tools.FlagError{Err: fmt.Errorf("a value or --stdin is required when resto can't prompt for it")}
+	}
+
+	v, err := OpenVault(opts.IO)
+
+	if err != nil {
+		return err
+	}
+
+	v.Set(opts.Name, opts.Value)
+
+	if err := v.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(opts.IO.Out, "%s Stored %s, use it as secret:%s\n", opts.IO.ColorScheme().SuccessIcon(), opts.Name, opts.Name)
+
+	return nil
+}
@@ -27,7 +27,24 @@ func SettingsSet() *cobra.Command {
 					value = false
 				}
 
-				if strings.Contains(args[0], "theme") || strings.Contains(args[0], "colorscheme") || strings.Contains(args[0], "request_body") {
+				if strings.Contains(args[0], "response_theme") {
+					if args[1] == "auto" || args[1] == "dark" || args[1] == "light" {
+						theme, err := sjson.Set(tools.SettingsContent(), "rs_settings.response_theme", args[1])
+
+						if err != nil {
+							panic(err)
+						}
+
+						terr := ioutil.WriteFile(tools.SettingsFile(), []byte(string(theme)), 0644)
+
+						if terr != nil {
+							panic(terr)
+						}
+					} else {
+						fmt.Println(ansi.Color("rs_settings.response_theme must be `auto`, `dark` or `light`", "red"))
+						os.Exit(1)
+					}
+				} else if strings.Contains(args[0], "theme") || strings.Contains(args[0], "colorscheme") || strings.Contains(args[0], "request_body") {
 					theme, err := sjson.Set(tools.SettingsContent(), "rs_settings.request_body.theme", args[1])
 
 					if err != nil {
@@ -39,6 +56,35 @@ func SettingsSet() *cobra.Command {
 					if serr != nil {
 						panic(serr)
 					}
+				} else if strings.Contains(args[0], "pager") {
+					pager, err := sjson.Set(tools.SettingsContent(), "rs_settings.pager", args[1])
+
+					if err != nil {
+						panic(err)
+					}
+
+					perr := ioutil.WriteFile(tools.SettingsFile(), []byte(string(pager)), 0644)
+
+					if perr != nil {
+						panic(perr)
+					}
+				} else if strings.Contains(args[0], "netrc_default") {
+					if string(args[1]) == "true" || string(args[1]) == "false" {
+						netrc, err := sjson.Set(tools.SettingsContent(), "rs_settings.netrc_default", value)
+
+						if err != nil {
+							panic(err)
+						}
+
+						nerr := ioutil.WriteFile(tools.SettingsFile(), []byte(string(netrc)), 0644)
+
+						if nerr != nil {
+							panic(nerr)
+						}
+					} else {
+						fmt.Println(ansi.Color("rs_settings.netrc_default must be `true` or `false`", "red"))
+						os.Exit(1)
+					}
 				} else if strings.Contains(args[0], "show_update") {
 					if string(args[1]) == "true" || string(args[1]) == "false" {
 						update, err := sjson.Set(tools.SettingsContent(), "rs_settings.show_update", value)
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"crypto/tls"
 	"net/http"
 	"time"
 )
@@ -10,3 +11,14 @@ func HttpClient() *http.Client {
 		Timeout: 10 * time.Second,
 	}
 }
+
+// InsecureHttpClient is like HttpClient but doesn't verify TLS certificates, like `curl -k`
+func InsecureHttpClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	client := HttpClient()
+	client.Transport = transport
+
+	return client
+}
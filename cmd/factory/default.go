@@ -1,7 +1,10 @@
 package factory
 
 import (
+	"os"
+
 	"github.com/abdfnx/resto/ios"
+	"github.com/abdfnx/resto/tools"
 )
 
 type Factory struct {
@@ -19,5 +22,13 @@ func New() *Factory {
 func ioStreams(f *Factory) *ios.IOStreams {
 	io := ios.System()
 
+	if pager := os.Getenv("RESTO_PAGER"); pager != "" {
+		io.SetPager(pager)
+	} else if pager := tools.Setting("pager").String(); pager != "" {
+		io.SetPager(pager)
+	} else if pager := os.Getenv("PAGER"); pager != "" {
+		io.SetPager(pager)
+	}
+
 	return io
 }
@@ -0,0 +1,48 @@
+package resto
+
+import (
+	"fmt"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/spf13/cobra"
+)
+
+// setupColors applies the --color flag and picks the palette of json responses
+func setupColors(f *factory.Factory, cmd *cobra.Command) error {
+	io := f.IOStreams
+	color, _ := cmd.Flags().GetString("color")
+
+	switch color {
+	case "always":
+		io.SetColorEnabled(true)
+	case "never":
+		io.SetColorEnabled(false)
+	case "auto", "":
+	default:
+		return &tools.FlagError{Err: fmt.Errorf("invalid value for --color: %q, it must be auto, always or never", color)}
+	}
+
+	// the TUI has its own colors, no need to query the terminal
+	if !io.ColorEnabled() || cmd == cmd.Root() {
+		return nil
+	}
+
+	theme := tools.Setting("response_theme").String()
+
+	if theme == "" || theme == "auto" {
+		theme = io.DetectTerminalTheme()
+	}
+
+	overrides := map[string]string{}
+
+	for part, spec := range tools.Setting("response_colors").Map() {
+		overrides[part] = spec.String()
+	}
+
+	api.SetResponseTheme(theme, overrides)
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package resto
+
+import (
+	"testing"
+
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/ios"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSetupColors(t *testing.T) {
+	for _, c := range []struct {
+		flag     string
+		enabled  bool
+		expected bool
+	}{
+		{flag: "auto", enabled: true, expected: true},
+		{flag: "auto", enabled: false, expected: false},
+		{flag: "always", enabled: false, expected: true},
+		{flag: "never", enabled: true, expected: false},
+	} {
+		io, _, _, _ := ios.Test()
+		io.SetColorEnabled(c.enabled)
+
+		root := &cobra.Command{Use: "resto"}
+		root.PersistentFlags().String("color", "auto", "")
+
+		if err := root.ParseFlags([]string{"--color", c.flag}); err != nil {
+			t.Fatal(err)
+		}
+
+		// the root command is the TUI, it doesn't pick a response theme
+		if err := setupColors(&factory.Factory{IOStreams: io}, root); err != nil {
+			t.Fatalf("%s: %v", c.flag, err)
+		}
+
+		if io.ColorEnabled() != c.expected {
+			t.Errorf("--color %s: expected the colors to be enabled: %v, got %v", c.flag, c.expected, io.ColorEnabled())
+		}
+	}
+
+	io, _, _, _ := ios.Test()
+
+	root := &cobra.Command{Use: "resto"}
+	root.PersistentFlags().String("color", "auto", "")
+
+	if err := root.ParseFlags([]string{"--color", "sometimes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := setupColors(&factory.Factory{IOStreams: io}, root).(*tools.FlagError); !ok {
+		t.Errorf("expected a FlagError for an invalid --color")
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"fmt"
 
 	"github.com/abdfnx/resto/tools"
+	"github.com/abdfnx/resto/core/environment"
 	"github.com/abdfnx/resto/core/layout"
 	"github.com/abdfnx/resto/cmd/factory"
 	"github.com/abdfnx/resto/cli"
+	authCmd "github.com/abdfnx/resto/cli/auth"
+	hmacCmd "github.com/abdfnx/resto/cli/hmac"
+	importCmd "github.com/abdfnx/resto/cli/import"
 	installCmd "github.com/abdfnx/resto/cli/install"
+	jwtCmd "github.com/abdfnx/resto/cli/jwt"
 	runCmd "github.com/abdfnx/resto/cli/run"
+	secretCmd "github.com/abdfnx/resto/cli/secret"
 	"github.com/abdfnx/resto/cli/settings"
 
 	"github.com/MakeNowJust/heredoc"
@@ -31,6 +37,9 @@ func Execute(f *factory.Factory, version string, buildDate string) *cobra.Comman
 			# Open Resto UI
 			resto
 
+			# Open Resto UI with the {{variables}} of the staging environment of resto.env.json
+			resto --env staging
+
 			# Send a request to a URL
 			resto get https://api.github.com
 
@@ -49,10 +58,30 @@ func Execute(f *factory.Factory, version string, buildDate string) *cobra.Comman
 			# Install binary app from script URL and run it.
 			resto i https://get.docker.com
 
+			# Import a curl command and send it
+			resto import curl 'curl -X POST https://api.xcode.codes -d "{}"'
+
+			# Login to an OAuth2 profile in the browser
+			resto auth login github
+
+			# Store a token in the encrypted vault and use it
+			resto secret set GITHUB_TOKEN
+			resto get https://api.github.com/user --token secret:GITHUB_TOKEN
+
+			# Decode a JWT and check when it expires
+			resto jwt decode env:TOKEN
+
 			# Send a request from Restofile
 			# after creating a Restofile
 			resto run
 
+			# Send the requests of the Restofiles of a directory and check their expect blocks
+			resto test api --junit report.xml
+
+			# Format and check Restofiles
+			resto fmt --check
+			resto lint
+
 			# Get the latest release/tag of a repository (github, gitlab, bitbucket)
 			resto get-latest microsoft/vscode
 
@@ -65,7 +94,19 @@ func Execute(f *factory.Factory, version string, buildDate string) *cobra.Comman
 			`),
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			layout.Layout(version)
+			var env *environment.Environment
+
+			if name, _ := cmd.Flags().GetString("env"); name != "" {
+				loaded, err := environment.Load(environment.FileName, name)
+
+				if err != nil {
+					return err
+				}
+
+				env = loaded
+			}
+
+			layout.Layout(version, env)
 
 			return nil
 		},
@@ -89,21 +130,36 @@ func Execute(f *factory.Factory, version string, buildDate string) *cobra.Comman
 		rootHelpFunc(cs, command, args)
 	}
 
+	rootCmd.Flags().StringP("env", "e", "", "The environment of " + environment.FileName + " to use in resto UI")
 	rootCmd.PersistentFlags().Bool("help", false, "Help for resto")
+	rootCmd.PersistentFlags().String("color", "auto", "When to color the output: auto, always or never")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		setupVault(f, cmd)
+
+		return setupColors(f, cmd)
+	}
 	rootCmd.SetHelpFunc(helpHelper)
 	rootCmd.SetUsageFunc(rootUsageFunc)
 	rootCmd.SetFlagErrorFunc(rootFlagErrorFunc)
 
 	// Add sub-commands to root command
 	rootCmd.AddCommand(
-		cli.GetCMD(),
-	    cli.PostCMD(),
-		cli.PutCMD(),
-		cli.PatchCMD(),
-		cli.DeleteCMD(),
-		cli.HeadCMD(),
+		cli.GetCMD(f),
+		cli.PostCMD(f),
+		cli.PutCMD(f),
+		cli.PatchCMD(f),
+		cli.DeleteCMD(f),
+		cli.HeadCMD(f),
 		installCmd.InstallCMD(),
-		runCmd.RunCMD(),
+		importCmd.ImportCMD(f, version),
+		runCmd.RunCMD(f),
+		runCmd.TestCMD(f),
+		runCmd.FmtCMD(f),
+		runCmd.LintCMD(f),
+		authCmd.AuthCMD(f),
+		jwtCmd.JwtCMD(f),
+		hmacCmd.HmacCMD(f),
+		secretCmd.SecretCMD(f),
 		cli.GetLatestCMD(),
 		settings.SettingsCMD(),
 		versionCmd,
@@ -0,0 +1,24 @@
+package resto
+
+import (
+	"github.com/abdfnx/resto/cmd/factory"
+	"github.com/abdfnx/resto/core/vault"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// setupVault lets `secret:NAME` values prompt for the vault passphrase, resto UI unlocks the vault itself
+func setupVault(f *factory.Factory, cmd *cobra.Command) {
+	if !f.IOStreams.CanPrompt() || cmd == cmd.Root() {
+		return
+	}
+
+	vault.Prompt = func() (string, error) {
+		var passphrase string
+
+		err := survey.AskOne(&survey.Password{Message: "Vault passphrase:"}, &passphrase)
+
+		return passphrase, err
+	}
+}
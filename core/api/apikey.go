@@ -0,0 +1,194 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+)
+
+// DefaultAPIKeyName is the header api keys are sent in when no name is given
+const DefaultAPIKeyName = "X-API-Key"
+
+// redacted replaces secrets in everything resto prints
+const redacted = "REDACTED"
+
+// apiKey returns the name, value and location of the api key, with `env:` values resolved
+func apiKey(auth *options.Auth) (string, string, string, error) {
+	name := auth.APIKey.Name
+	in := strings.ToLower(auth.APIKey.In)
+
+	if name == "" {
+		name = DefaultAPIKeyName
+	}
+
+	if in == "" {
+		in = "header"
+	}
+
+	if in != "header" && in != "query" && in != "cookie" {
+		return "", "", "", fmt.Errorf("apikey: unknown location %q, it must be header, query or cookie", auth.APIKey.In)
+	}
+
+	value, err := tools.ResolveValue(auth.APIKey.Value)
+
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return name, value, in, nil
+}
+
+// apiKeyProvider sends an api key in a header, a query param or a cookie
+type apiKeyProvider struct{}
+
+func (apiKeyProvider) Name() string  { return "apikey" }
+func (apiKeyProvider) Label() string { return "api key" }
+
+func (apiKeyProvider) Detect(auth *options.Auth) bool {
+	return auth.APIKey.Value != ""
+}
+
+func (apiKeyProvider) Validate(auth *options.Auth) error {
+	if auth.APIKey.Value == "" {
+		return fmt.Errorf("--api-key is required for apikey authentication")
+	}
+
+	_, _, _, err := apiKey(auth)
+
+	return err
+}
+
+func (apiKeyProvider) Authorize(req *http.Request, auth *options.Auth) error {
+	name, value, in, err := apiKey(auth)
+
+	if err != nil {
+		return err
+	}
+
+	switch in {
+	case "header":
+		req.Header.Set(name, value)
+	case "query":
+		req.URL.RawQuery = addQueryParam(req.URL.RawQuery, name, value)
+	case "cookie":
+		cookie := (&http.Cookie{Name: name, Value: value}).String()
+
+		if existing := req.Header.Get("Cookie"); existing != "" {
+			cookie = existing + "; " + cookie
+		}
+
+		req.Header.Set("Cookie", cookie)
+	}
+
+	return nil
+}
+
+func (apiKeyProvider) Fields() []AuthField {
+	return []AuthField{
+		{
+			Key: "name", Label: "Key Name", Default: DefaultAPIKeyName,
+			Flag: "api-key-name", Usage: "The header, query param or cookie name of the API key (Default: " + DefaultAPIKeyName + ")",
+			Value: func(auth *options.Auth) *string { return &auth.APIKey.Name },
+		},
+		{
+			Key: "key", Label: "API Key", Secret: true, Resolve: true,
+			Flag: "api-key", Usage: "The API key to send, env:NAME reads it from an env variable",
+			Value: func(auth *options.Auth) *string { return &auth.APIKey.Value },
+		},
+		{
+			Key: "in", Label: "Add To", Options: []string{"header", "query", "cookie"},
+			Flag: "api-key-in", Usage: "Where to send the API key: header, query or cookie (Default: header)",
+			Value: func(auth *options.Auth) *string { return &auth.APIKey.In },
+		},
+	}
+}
+
+// addQueryParam appends a param without re-encoding the existing ones
+func addQueryParam(rawQuery, name, value string) string {
+	param := url.QueryEscape(name) + "=" + url.QueryEscape(value)
+
+	if rawQuery == "" {
+		return param
+	}
+
+	return rawQuery + "&" + param
+}
+
+// RedactRequest hides the api key of auth in req, only the header, query param or cookie that carries it
+// is changed, so req can be printed or copied without leaking the key
+func RedactRequest(req *http.Request, auth *options.Auth) {
+	if auth == nil || auth.Type != "apikey" {
+		return
+	}
+
+	name, value, in, err := apiKey(auth)
+
+	if err != nil || value == "" {
+		return
+	}
+
+	switch in {
+	case "header":
+		if req.Header.Get(name) == value {
+			req.Header.Set(name, redacted)
+		}
+	case "query":
+		req.URL.RawQuery = redactQuery(req.URL.RawQuery, name, value)
+	case "cookie":
+		var cookies []string
+
+		for _, cookie := range req.Cookies() {
+			if cookie.Name == name && cookie.Value == value {
+				cookie.Value = redacted
+			}
+
+			cookies = append(cookies, cookie.String())
+		}
+
+		req.Header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+}
+
+// redactQuery hides the value of the name=value params of rawQuery, the other params are kept as they are
+func redactQuery(rawQuery, name, value string) string {
+	params := strings.Split(rawQuery, "&")
+
+	for i, param := range params {
+		pair := strings.SplitN(param, "=", 2)
+
+		if len(pair) != 2 {
+			continue
+		}
+
+		key, keyErr := url.QueryUnescape(pair[0])
+		val, valErr := url.QueryUnescape(pair[1])
+
+		if keyErr == nil && valErr == nil && key == name && val == value {
+			params[i] = pair[0] + "=" + redacted
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// redactError hides an api key sent in the query from the urls of err, headers and cookies aren't part of errors
+func redactError(err error, auth *options.Auth) error {
+	if err == nil || auth == nil || auth.Type != "apikey" {
+		return err
+	}
+
+	name, value, in, keyErr := apiKey(auth)
+
+	if keyErr != nil || value == "" || in != "query" {
+		return err
+	}
+
+	param := url.QueryEscape(name) + "="
+
+	return errors.New(strings.Replace(err.Error(), param+url.QueryEscape(value), param+redacted, -1))
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+
+	"github.com/rivo/tview"
+)
+
+var apiKeyCases = []struct {
+	name     string
+	key      options.APIKey
+	url      string
+	header   string
+	expected string
+}{
+	{
+		name:     "default header",
+		key:      options.APIKey{Value: "s3cr3t"},
+		url:      "https://api.example.com/v1/items",
+		header:   "X-API-Key",
+		expected: "s3cr3t",
+	},
+	{
+		name:     "query",
+		key:      options.APIKey{Name: "api_key", Value: "s3cr3t", In: "query"},
+		url:      "https://api.example.com/v1/items?page=2",
+		expected: "https://api.example.com/v1/items?page=2&api_key=s3cr3t",
+	},
+	{
+		name:     "cookie",
+		key:      options.APIKey{Name: "session", Value: "s3cr3t", In: "cookie"},
+		url:      "https://api.example.com/v1/items",
+		header:   "Cookie",
+		expected: "session=s3cr3t",
+	},
+	{
+		name:     "env",
+		key:      options.APIKey{Name: "Authorization", Value: "env:RESTO_TEST_API_KEY"},
+		url:      "https://api.example.com",
+		header:   "Authorization",
+		expected: "from-env",
+	},
+}
+
+func TestAPIKey(t *testing.T) {
+	t.Setenv("RESTO_TEST_API_KEY", "from-env")
+
+	for _, c := range apiKeyCases {
+		auth := &options.Auth{Type: "apikey", APIKey: c.key}
+
+		req, err := BuildRequest(c.url, "GET", "", "", auth, 0, nil)
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		got := req.URL.String()
+
+		if c.header != "" {
+			got = req.Header.Get(c.header)
+		}
+
+		if got != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, got)
+		}
+	}
+
+	_, err := BuildRequest("https://api.example.com", "GET", "", "", &options.Auth{Type: "apikey", APIKey: options.APIKey{Value: "x", In: "body"}}, 0, nil)
+
+	if err == nil {
+		t.Errorf("expected an error for an unknown location")
+	}
+}
+
+var redactCases = []struct {
+	name     string
+	key      options.APIKey
+	url      string
+	header   string
+	expected string
+}{
+	{
+		name:     "header",
+		key:      options.APIKey{Value: "abc"},
+		url:      "https://api.example.com/abc?q=abc",
+		header:   "X-API-Key",
+		expected: "https://api.example.com/abc?q=abc REDACTED",
+	},
+	{
+		name:     "query",
+		key:      options.APIKey{Name: "key", Value: "env:RESTO_TEST_API_KEY", In: "query"},
+		url:      "https://api.example.com/a%20b?q=a+b%2Fc",
+		expected: "https://api.example.com/a%20b?q=a+b%2Fc&key=REDACTED",
+	},
+	{
+		name:     "cookie",
+		key:      options.APIKey{Name: "session", Value: "abc", In: "cookie"},
+		url:      "https://api.example.com/abc",
+		header:   "Cookie",
+		expected: "https://api.example.com/abc theme=abc; session=REDACTED",
+	},
+}
+
+func TestRedactRequest(t *testing.T) {
+	t.Setenv("RESTO_TEST_API_KEY", "a b/c")
+
+	for _, c := range redactCases {
+		auth := &options.Auth{Type: "apikey", APIKey: c.key}
+
+		form := tview.NewForm().
+			AddInputField("Cookie", "theme=abc", 20, nil, nil).
+			AddInputField("X-Other", "abc", 20, nil, nil)
+
+		req, err := BuildRequest(c.url, "GET", "", "", auth, 2, form)
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		RedactRequest(req, auth)
+
+		got := req.URL.String()
+
+		if c.header != "" {
+			got += " " + req.Header.Get(c.header)
+		}
+
+		if got != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, got)
+		}
+
+		// only the value that carries the key is redacted
+		if req.Header.Get("X-Other") != "abc" {
+			t.Errorf("%s: expected the other headers to be kept, got %q", c.name, req.Header.Get("X-Other"))
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	t.Setenv("RESTO_TEST_API_KEY", "a b/c")
+
+	auth := &options.Auth{Type: "apikey", APIKey: options.APIKey{Value: "env:RESTO_TEST_API_KEY", In: "query"}}
+
+	err := redactError(errors.New("Get https://api.example.com/a+b%2Fc?q=a+b%2Fc&X-API-Key=a+b%2Fc: timeout"), auth)
+
+	if err.Error() != "Get https://api.example.com/a+b%2Fc?q=a+b%2Fc&X-API-Key=REDACTED: timeout" {
+		t.Errorf("unexpected error %q", err)
+	}
+
+	header := &options.Auth{Type: "apikey", APIKey: options.APIKey{Value: "abc"}}
+
+	if err := redactError(errors.New("Get https://abc.example.com: timeout"), header); err.Error() != "Get https://abc.example.com: timeout" {
+		t.Errorf("expected the error to be kept for a key sent in a header, got %q", err)
+	}
+}
@@ -0,0 +1,488 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/abdfnx/resto/core/oauth2"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+)
+
+// AuthProvider is an authentication scheme, the `type` of an options.Auth picks the provider that authorizes the request
+type AuthProvider interface {
+	// Name is the auth type of the provider, as given to --auth-type and in Restofiles
+	Name() string
+
+	// Label is how resto UI shows the provider
+	Label() string
+
+	// Detect reports whether auth holds the credentials of the provider, it's used when no type is given
+	Detect(auth *options.Auth) bool
+
+	// Validate checks the credentials of auth before anything is sent
+	Validate(auth *options.Auth) error
+
+	// Authorize adds the credentials to req or signs it, it's called once all the other headers are set
+	Authorize(req *http.Request, auth *options.Auth) error
+
+	// Fields are the entries of the auth blocks of Restofiles and the inputs of resto UI for the provider
+	Fields() []AuthField
+}
+
+// AuthField is a setting of a provider, Key is its entry in the auth block of a Restofile and Label its input in resto UI
+type AuthField struct {
+	Key   string
+	Label string
+
+	// Secret fields are masked in resto UI, and lint warns when they're written in plain text
+	Secret bool
+
+	// Resolve fields are read as written, their `env:` and `secret:` values are resolved when the request is built
+	Resolve bool
+
+	// Options are the values a field can take, the first one is the default
+	Options []string
+
+	// Default is the value of a field without options that isn't set
+	Default string
+
+	// Flag is the flag of the request commands for the field (Default: the name of the provider and the key, like ticket-key),
+	// Shorthand its one letter form and Usage its help
+	Flag      string
+	Shorthand string
+	Usage     string
+
+	// Value returns the field of auth the setting is kept in
+	Value func(auth *options.Auth) *string
+}
+
+// AuthFlag is a flag of the request commands that sets a field of a provider
+type AuthFlag struct {
+	Name      string
+	Shorthand string
+	Usage     string
+	Value     func(auth *options.Auth) *string
+}
+
+// authChallenger is implemented by the providers that answer a 401 response to req,
+// Challenge returns the request to retry or nil to keep the response
+type authChallenger interface {
+	Challenge(req *http.Request, res *http.Response, auth *options.Auth) (*http.Request, error)
+}
+
+var (
+	authProvidersMu sync.RWMutex
+	authProviders   []AuthProvider
+)
+
+func init() {
+	RegisterAuthProvider(basicProvider{})
+	RegisterAuthProvider(bearerProvider{})
+	RegisterAuthProvider(digestProvider{})
+	RegisterAuthProvider(oauth2Provider{})
+	RegisterAuthProvider(sigv4Provider{})
+	RegisterAuthProvider(apiKeyProvider{})
+	RegisterAuthProvider(hmacProvider{})
+}
+
+// RegisterAuthProvider adds provider to the auth types of resto, a provider with the same name is replaced,
+// the keys of its fields become known entries of the auth blocks of Restofiles
+func RegisterAuthProvider(provider AuthProvider) {
+	authProvidersMu.Lock()
+	defer authProvidersMu.Unlock()
+
+	for _, field := range provider.Fields() {
+		restofile.AddKeys("auth", field.Key)
+	}
+
+	for i, registered := range authProviders {
+		if registered.Name() == provider.Name() {
+			authProviders[i] = provider
+			return
+		}
+	}
+
+	authProviders = append(authProviders, provider)
+}
+
+// AuthProviders returns the registered providers, in the order they were registered
+func AuthProviders() []AuthProvider {
+	authProvidersMu.RLock()
+	defer authProvidersMu.RUnlock()
+
+	return append([]AuthProvider{}, authProviders...)
+}
+
+// LookupAuthProvider returns the provider of the auth type name
+func LookupAuthProvider(name string) (AuthProvider, bool) {
+	for _, provider := range AuthProviders() {
+		if provider.Name() == name {
+			return provider, true
+		}
+	}
+
+	return nil, false
+}
+
+// AuthTypes returns the names of the registered providers
+func AuthTypes() []string {
+	var names []string
+
+	for _, provider := range AuthProviders() {
+		names = append(names, provider.Name())
+	}
+
+	return names
+}
+
+// AuthFlags returns the flags of the fields of the registered providers, a field shared by providers,
+// like the username of basic and digest, has one flag
+func AuthFlags() []AuthFlag {
+	var flags []AuthFlag
+
+	seen := map[string]bool{}
+
+	for _, provider := range AuthProviders() {
+		for _, field := range provider.Fields() {
+			flag := AuthFlag{Name: field.Flag, Shorthand: field.Shorthand, Usage: field.Usage, Value: field.Value}
+
+			if flag.Name == "" {
+				flag.Name = provider.Name() + "-" + flagName(field.Key)
+			}
+
+			if flag.Usage == "" {
+				flag.Usage = "The " + strings.ToLower(field.Label) + " of " + provider.Label() + " authentication"
+			}
+
+			if seen[flag.Name] {
+				continue
+			}
+
+			seen[flag.Name] = true
+			flags = append(flags, flag)
+		}
+	}
+
+	return flags
+}
+
+// flagName turns the key of a field into a flag name, like timestampHeader into timestamp-header
+func flagName(key string) string {
+	var name strings.Builder
+
+	for i, r := range key {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name.WriteByte('-')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		name.WriteRune(r)
+	}
+
+	return name.String()
+}
+
+// DetectAuthType returns the type of the first provider that recognizes the credentials of auth
+func DetectAuthType(auth *options.Auth) string {
+	for _, provider := range AuthProviders() {
+		if provider.Detect(auth) {
+			return provider.Name()
+		}
+	}
+
+	return ""
+}
+
+// authProvider returns the provider of auth, or nil when the request has no auth
+func authProvider(auth *options.Auth) (AuthProvider, error) {
+	if auth == nil || auth.Type == "" || auth.Type == "none" {
+		return nil, nil
+	}
+
+	provider, ok := LookupAuthProvider(auth.Type)
+
+	if !ok {
+		return nil, fmt.Errorf("unknown auth type %q", auth.Type)
+	}
+
+	return provider, nil
+}
+
+// authPreviewer is implemented by the providers that go over the network to authorize a request,
+// Preview authorizes it with what they have locally instead
+type authPreviewer interface {
+	Preview(req *http.Request, auth *options.Auth) error
+}
+
+// previewAuth applies the provider of auth to a request that's shown and not sent, it never does network I/O
+func previewAuth(req *http.Request, auth *options.Auth) error {
+	provider, err := authProvider(auth)
+
+	if err != nil || provider == nil {
+		return err
+	}
+
+	if previewer, ok := provider.(authPreviewer); ok {
+		return previewer.Preview(req, auth)
+	}
+
+	return provider.Authorize(req, auth)
+}
+
+// authTransport authorizes the requests it sends, and retries once when the provider answers a 401 challenge
+type authTransport struct {
+	Auth     *options.Auth
+	Provider AuthProvider
+	Base     http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorized := req.Clone(req.Context())
+
+	// credentials aren't sent to the other hosts a request is redirected to
+	if originalHost(req) != req.URL.Host {
+		return t.Base.RoundTrip(authorized)
+	}
+
+	if err := t.Provider.Authorize(authorized, t.Auth); err != nil {
+		return nil, err
+	}
+
+	res, err := t.Base.RoundTrip(authorized)
+
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	challenger, ok := t.Provider.(authChallenger)
+
+	if !ok {
+		return res, nil
+	}
+
+	retry, err := challenger.Challenge(authorized, res, t.Auth)
+
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	if retry == nil {
+		return res, nil
+	}
+
+	res.Body.Close()
+
+	return t.Base.RoundTrip(retry)
+}
+
+// originalHost returns the host of the first request of a redirect chain
+func originalHost(req *http.Request) string {
+	for req.Response != nil && req.Response.Request != nil {
+		req = req.Response.Request
+	}
+
+	return req.URL.Host
+}
+
+// withAuth makes client authorize the requests it sends with the provider of auth
+func withAuth(client *http.Client, auth *options.Auth) (*http.Client, error) {
+	provider, err := authProvider(auth)
+
+	if err != nil || provider == nil {
+		return client, err
+	}
+
+	base := client.Transport
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	authorized := *client
+	authorized.Transport = &authTransport{Auth: auth, Provider: provider, Base: base}
+
+	return &authorized, nil
+}
+
+// retryRequest clones req for a second attempt, with a fresh body
+func retryRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+
+		if err != nil {
+			return nil, err
+		}
+
+		retry.Body = body
+	}
+
+	return retry, nil
+}
+
+type basicProvider struct{}
+
+func (basicProvider) Name() string  { return "basic" }
+func (basicProvider) Label() string { return "basic auth" }
+
+func (basicProvider) Detect(auth *options.Auth) bool {
+	return auth.BasicAuthUsername != "" && auth.BasicAuthPassword != ""
+}
+
+func (basicProvider) Validate(auth *options.Auth) error {
+	if auth.BasicAuthUsername == "" {
+		return fmt.Errorf("--username is required for basic authentication")
+	}
+
+	return nil
+}
+
+func (basicProvider) Authorize(req *http.Request, auth *options.Auth) error {
+	req.Header.Set("Authorization", "Basic "+basicAuth(auth.BasicAuthUsername, auth.BasicAuthPassword))
+
+	return nil
+}
+
+func (basicProvider) Fields() []AuthField {
+	return usernamePasswordFields
+}
+
+// usernamePasswordFields are the fields of basic and digest auth
+var usernamePasswordFields = []AuthField{
+	{
+		Key: "username", Label: "Username", Resolve: true,
+		Flag: "username", Shorthand: "u", Usage: "The username to use for basic authentication",
+		Value: func(auth *options.Auth) *string { return &auth.BasicAuthUsername },
+	},
+	{
+		Key: "password", Label: "Password", Secret: true, Resolve: true,
+		Flag: "password", Shorthand: "p", Usage: "The password to use for basic authentication",
+		Value: func(auth *options.Auth) *string { return &auth.BasicAuthPassword },
+	},
+}
+
+type bearerProvider struct{}
+
+func (bearerProvider) Name() string  { return "bearer" }
+func (bearerProvider) Label() string { return "bearer token" }
+
+func (bearerProvider) Detect(auth *options.Auth) bool {
+	return auth.TokenAuth != ""
+}
+
+func (bearerProvider) Validate(auth *options.Auth) error {
+	if auth.TokenAuth == "" {
+		return fmt.Errorf("--token is required for bearer authentication")
+	}
+
+	return nil
+}
+
+func (bearerProvider) Authorize(req *http.Request, auth *options.Auth) error {
+	req.Header.Set("Authorization", "Bearer "+auth.TokenAuth)
+
+	return nil
+}
+
+func (bearerProvider) Fields() []AuthField {
+	return []AuthField{
+		{
+			Key: "token", Label: "Token", Secret: true, Resolve: true,
+			Flag: "token", Shorthand: "t", Usage: "The bearer token to use for authentication",
+			Value: func(auth *options.Auth) *string { return &auth.TokenAuth },
+		},
+	}
+}
+
+type oauth2Provider struct{}
+
+func (oauth2Provider) Name() string  { return "oauth2" }
+func (oauth2Provider) Label() string { return "oauth2" }
+
+func (oauth2Provider) Detect(auth *options.Auth) bool {
+	return auth.OAuth2.Profile != "" || auth.OAuth2.TokenURL != ""
+}
+
+func (oauth2Provider) Validate(auth *options.Auth) error {
+	if auth.OAuth2.Profile == "" && auth.OAuth2.TokenURL == "" {
+		return fmt.Errorf("--oauth2-profile or --token-url is required for oauth2 authentication")
+	}
+
+	return nil
+}
+
+func (oauth2Provider) Authorize(req *http.Request, auth *options.Auth) error {
+	cfg := auth.OAuth2
+	token, err := oauth2.AccessToken(&cfg)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
+
+func (oauth2Provider) Fields() []AuthField {
+	return []AuthField{
+		{
+			Key: "profile", Label: "Profile",
+			Flag: "oauth2-profile", Usage: "The OAuth2 profile from the oauth2 settings to get the token with",
+			Value: func(auth *options.Auth) *string { return &auth.OAuth2.Profile },
+		},
+		{
+			Key: "tokenUrl", Label: "Token URL",
+			Flag: "token-url", Usage: "The OAuth2 token endpoint",
+			Value: func(auth *options.Auth) *string { return &auth.OAuth2.TokenURL },
+		},
+		{
+			Key: "clientId", Label: "Client ID", Resolve: true,
+			Flag: "client-id", Usage: "The OAuth2 client id",
+			Value: func(auth *options.Auth) *string { return &auth.OAuth2.ClientID },
+		},
+		{
+			Key: "clientSecret", Label: "Client Secret", Secret: true, Resolve: true,
+			Flag: "client-secret", Usage: "The OAuth2 client secret, env:NAME reads it from an env variable",
+			Value: func(auth *options.Auth) *string { return &auth.OAuth2.ClientSecret },
+		},
+		{
+			Key: "scopes", Label: "Scopes",
+			Flag: "scopes", Usage: "The OAuth2 scopes to request, separated by spaces or commas",
+			Value: func(auth *options.Auth) *string { return &auth.OAuth2.Scopes },
+		},
+		{
+			Key: "audience", Label: "Audience",
+			Flag: "audience", Usage: "The OAuth2 audience to request the token for",
+			Value: func(auth *options.Auth) *string { return &auth.OAuth2.Audience },
+		},
+		{
+			Key: "refreshToken", Label: "Refresh Token", Secret: true, Resolve: true,
+			Flag: "refresh-token", Usage: "The OAuth2 refresh token to get the access token with",
+			Value: func(auth *options.Auth) *string { return &auth.OAuth2.RefreshToken },
+		},
+	}
+}
+
+// Preview uses the cached token, a placeholder stands for the token that would be fetched when it's sent
+func (oauth2Provider) Preview(req *http.Request, auth *options.Auth) error {
+	cfg := auth.OAuth2
+	token, ok := oauth2.CachedToken(&cfg)
+
+	if !ok {
+		token = "<oauth2 access token>"
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
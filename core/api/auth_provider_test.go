@@ -0,0 +1,250 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/restofile"
+)
+
+// ticketProvider sends a ticket header, and asks for a new ticket when the server refuses it
+type ticketProvider struct{}
+
+func (ticketProvider) Name() string                      { return "ticket" }
+func (ticketProvider) Label() string                     { return "ticket" }
+func (ticketProvider) Detect(auth *options.Auth) bool    { return false }
+func (ticketProvider) Validate(auth *options.Auth) error { return nil }
+
+func (ticketProvider) Authorize(req *http.Request, auth *options.Auth) error {
+	req.Header.Set("X-Ticket", auth.TokenAuth)
+
+	return nil
+}
+
+func (ticketProvider) Fields() []AuthField {
+	return []AuthField{
+		{Key: "ticket", Label: "Ticket", Secret: true, Resolve: true, Value: func(auth *options.Auth) *string { return &auth.TokenAuth }},
+	}
+}
+
+func (ticketProvider) Challenge(req *http.Request, res *http.Response, auth *options.Auth) (*http.Request, error) {
+	retry, err := retryRequest(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	retry.Header.Set("X-Ticket", res.Header.Get("X-New-Ticket"))
+
+	return retry, nil
+}
+
+func TestAuthProviderRegistry(t *testing.T) {
+	expected := []string{"basic", "bearer", "digest", "oauth2", "aws-sigv4", "apikey", "hmac"}
+
+	if types := AuthTypes(); fmt.Sprint(types) != fmt.Sprint(expected) {
+		t.Errorf("expected the built-in providers %v, got %v", expected, types)
+	}
+
+	detected := map[string]*options.Auth{
+		"basic":     {BasicAuthUsername: "user", BasicAuthPassword: "pass"},
+		"bearer":    {TokenAuth: "t0ken"},
+		"oauth2":    {OAuth2: options.OAuth2{Profile: "example"}},
+		"aws-sigv4": {AWS: options.AWS{Profile: "minio"}},
+		"apikey":    {APIKey: options.APIKey{Value: "k3y"}},
+		"hmac":      {HMAC: options.HMAC{Key: "k3y"}},
+		"":          {},
+	}
+
+	for name, auth := range detected {
+		if got := DetectAuthType(auth); got != name {
+			t.Errorf("expected %q to be detected, got %q", name, got)
+		}
+	}
+
+	if _, err := BuildRequest("https://api.example.com", "GET", "", "", &options.Auth{Type: "unknown"}, 0, nil); err == nil {
+		t.Errorf("expected an error for an unknown auth type")
+	}
+}
+
+func TestAuthProviderFields(t *testing.T) {
+	RegisterAuthProvider(ticketProvider{})
+
+	defer func() {
+		authProvidersMu.Lock()
+		authProviders = authProviders[:len(authProviders)-1]
+		authProvidersMu.Unlock()
+	}()
+
+	for _, provider := range AuthProviders() {
+		for _, field := range provider.Fields() {
+			if !restofile.Schemas["auth"].HasKey(field.Key) {
+				t.Errorf("%s: expected %q to be a key of the auth blocks", provider.Name(), field.Key)
+			}
+
+			if field.Options != nil && field.Default != "" {
+				t.Errorf("%s: expected the first option of %q to be its default, got %q", provider.Name(), field.Key, field.Default)
+			}
+		}
+	}
+
+	// only the resolved fields of the provider of the request are resolved
+	t.Setenv("RESTO_TEST_API_KEY", "k3y")
+
+	resolved, err := resolveAuth(&options.Auth{
+		Type:      "apikey",
+		TokenAuth: "env:RESTO_TEST_API_KEY",
+		APIKey:    options.APIKey{Value: "env:RESTO_TEST_API_KEY", Name: "env:RESTO_TEST_API_KEY"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resolved.APIKey.Value != "k3y" || resolved.APIKey.Name != "env:RESTO_TEST_API_KEY" || resolved.TokenAuth != "env:RESTO_TEST_API_KEY" {
+		t.Errorf("expected only the api key to be resolved, got %+v", resolved)
+	}
+}
+
+func TestAuthFlags(t *testing.T) {
+	RegisterAuthProvider(ticketProvider{})
+
+	defer func() {
+		authProvidersMu.Lock()
+		authProviders = authProviders[:len(authProviders)-1]
+		authProvidersMu.Unlock()
+	}()
+
+	flags := map[string]AuthFlag{}
+
+	for _, flag := range AuthFlags() {
+		if _, ok := flags[flag.Name]; ok {
+			t.Errorf("expected one %q flag", flag.Name)
+		}
+
+		flags[flag.Name] = flag
+	}
+
+	for _, name := range []string{"username", "password", "token", "oauth2-profile", "aws-region", "api-key", "hmac-template"} {
+		if _, ok := flags[name]; !ok {
+			t.Errorf("expected a %q flag", name)
+		}
+	}
+
+	// a provider without flags of its own gets one for each field
+	ticket, ok := flags["ticket-ticket"]
+
+	if !ok {
+		t.Fatalf("expected a flag for the field of the ticket provider, got %v", flags)
+	}
+
+	if ticket.Usage != "The ticket of ticket authentication" {
+		t.Errorf("unexpected usage %q", ticket.Usage)
+	}
+
+	auth := &options.Auth{}
+	*ticket.Value(auth) = "t1cket"
+
+	if auth.TokenAuth != "t1cket" {
+		t.Errorf("expected the flag to set the field of the provider, got %+v", auth)
+	}
+
+	if name := flagName("timestampHeader"); name != "timestamp-header" {
+		t.Errorf("expected timestamp-header, got %q", name)
+	}
+}
+
+func TestAuthTransportWithoutChallenge(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	defer server.Close()
+
+	client, err := withAuth(&http.Client{}, &options.Auth{Type: "bearer", TokenAuth: "t0ken"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.Get(server.URL)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusUnauthorized || requests != 1 {
+		t.Errorf("expected the 401 response to be kept, got status %d after %d requests", res.StatusCode, requests)
+	}
+}
+
+func TestAuthTransport(t *testing.T) {
+	RegisterAuthProvider(ticketProvider{})
+
+	defer func() {
+		authProvidersMu.Lock()
+		authProviders = authProviders[:len(authProviders)-1]
+		authProvidersMu.Unlock()
+	}()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("X-Ticket"))
+	}))
+
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redirect":
+			http.Redirect(w, r, other.URL, http.StatusFound)
+		case r.Header.Get("X-Ticket") != "fresh":
+			w.Header().Set("X-New-Ticket", "fresh")
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			fmt.Fprint(w, "ok")
+		}
+	}))
+
+	defer server.Close()
+
+	client, err := withAuth(&http.Client{}, &options.Auth{Type: "ticket", TokenAuth: "stale"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.Get(server.URL + "/items")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected the challenge to be answered, got status %d", res.StatusCode)
+	}
+
+	res, err = client.Get(server.URL + "/redirect")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	body := make([]byte, 16)
+	n, _ := res.Body.Read(body)
+
+	if string(body[:n]) != "" {
+		t.Errorf("expected no credentials for the redirected host, got %q", body[:n])
+	}
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// awsCredentials fills the missing credentials and region of cfg from the AWS env variables,
+// then from the shared credentials and config files of the profile
+func awsCredentials(cfg options.AWS) (options.AWS, error) {
+	for _, field := range []*string{&cfg.AccessKeyID, &cfg.SecretAccessKey, &cfg.SessionToken} {
+		value, err := tools.ResolveValue(*field)
+
+		if err != nil {
+			return cfg, err
+		}
+
+		*field = value
+	}
+
+	if cfg.AccessKeyID == "" && cfg.Profile == "" {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	profile := cfg.Profile
+
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+
+	if profile == "" {
+		profile = "default"
+	}
+
+	if cfg.AccessKeyID == "" {
+		credentials, err := readAWSFile(awsFile("AWS_SHARED_CREDENTIALS_FILE", "credentials"), profile)
+
+		if err != nil {
+			return cfg, err
+		}
+
+		cfg.AccessKeyID = credentials["aws_access_key_id"]
+		cfg.SecretAccessKey = credentials["aws_secret_access_key"]
+		cfg.SessionToken = credentials["aws_session_token"]
+	}
+
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	if cfg.Region == "" {
+		// the config file names its sections `[profile NAME]`, except the default one
+		section := "profile " + profile
+
+		if profile == "default" {
+			section = profile
+		}
+
+		config, err := readAWSFile(awsFile("AWS_CONFIG_FILE", "config"), section)
+
+		if err != nil {
+			return cfg, err
+		}
+
+		cfg.Region = config["region"]
+	}
+
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return cfg, fmt.Errorf("aws-sigv4: no credentials found in the flags, the AWS env variables or the %q profile", profile)
+	}
+
+	return cfg, nil
+}
+
+func awsFile(env, name string) string {
+	if path := os.Getenv(env); path != "" {
+		return path
+	}
+
+	home, _ := homedir.Dir()
+
+	return filepath.Join(home, ".aws", name)
+}
+
+// readAWSFile returns the keys of a section of an ini file, a missing file has no keys
+func readAWSFile(path, section string) (map[string]string, error) {
+	values := map[string]string{}
+
+	file, err := os.Open(path)
+
+	if os.IsNotExist(err) {
+		return values, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		if current != section {
+			continue
+		}
+
+		if i := strings.Index(line, "="); i != -1 {
+			values[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+
+	return values, scanner.Err()
+}
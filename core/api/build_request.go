@@ -0,0 +1,213 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+	"github.com/abdfnx/resto/validation"
+
+	"github.com/rivo/tview"
+)
+
+// BuildRequest creates the request resto would send for the given parameters, without sending it or fetching its token
+func BuildRequest(
+		httpURL,
+		method,
+		contentType,
+		reqBody string,
+		auth *options.Auth,
+		headersCount int,
+		headersForm *tview.Form,
+	) (*http.Request, error) {
+	auth, err := resolveAuth(auth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := buildRequest(httpURL, method, contentType, reqBody, FormHeaders(headersCount, headersForm), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := previewAuth(req, auth); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// Build creates the request resto would send for r, with its headers and query params, without sending it or fetching its token
+func Build(r *options.Request) (*http.Request, error) {
+	auth, err := resolveAuth(r.AuthType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := buildRequest(r.URL, r.Method, r.ContentType, r.Body, r.Headers, r.Query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := previewAuth(req, auth); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// Do sends r with its headers and query params and formats its response, for the flags and resto UI
+func Do(r *options.Request, isCommand bool) (string, string, string, error) {
+	res, err := roundTrip(r)
+
+	if err != nil {
+		return "", "", "", err
+	}
+
+	defer res.Body.Close()
+
+	return formatResponse(res, isCommand)
+}
+
+// buildRequest creates the request without its auth, the client of withAuth authorizes it when it's sent
+func buildRequest(
+		httpURL,
+		method,
+		contentType,
+		reqBody string,
+		headers,
+		query map[string]string,
+	) (*http.Request, error) {
+	url, err := validation.CheckURL(httpURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	url, err = withQuery(url, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+
+	if contentType == "application/graphql" {
+		// graphql requests are sent as a json encoded query, like the graphql client does
+		payload, err = json.Marshal(map[string]interface{}{
+			"query":     reqBody,
+			"variables": nil,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		method = http.MethodPost
+	} else if reqBody != "" {
+		payload = []byte(reqBody)
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(payload))
+
+	if err != nil {
+		return nil, fmt.Errorf("Error creating request: %s", err.Error())
+	}
+
+	if contentType == "application/graphql" {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("Accept", "application/json; charset=utf-8")
+	} else if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// FormHeaders returns the headers of the headers form of resto UI
+func FormHeaders(headersCount int, headersForm *tview.Form) map[string]string {
+	if headersForm == nil || headersCount == 0 {
+		return nil
+	}
+
+	headers := map[string]string{}
+
+	for i := 0; i < headersCount; i++ {
+		key := headersForm.GetFormItem(i).(*tview.InputField).GetLabel()
+		value := headersForm.GetFormItem(i).(*tview.InputField).GetText()
+
+		headers[key] = value
+	}
+
+	return headers
+}
+
+// withQuery adds the query params to httpURL, after the ones it already has
+func withQuery(httpURL string, query map[string]string) (string, error) {
+	if len(query) == 0 {
+		return httpURL, nil
+	}
+
+	u, err := url.Parse(httpURL)
+
+	if err != nil {
+		return "", err
+	}
+
+	values := url.Values{}
+
+	for key, value := range query {
+		values.Set(key, value)
+	}
+
+	if u.RawQuery != "" {
+		u.RawQuery += "&"
+	}
+
+	u.RawQuery += values.Encode()
+
+	return u.String(), nil
+}
+
+// resolveAuth returns a copy of auth with the `env:` and `secret:` references of its credentials resolved
+func resolveAuth(auth *options.Auth) (*options.Auth, error) {
+	if auth == nil {
+		return nil, nil
+	}
+
+	resolved := *auth
+
+	provider, err := authProvider(auth)
+
+	if err != nil || provider == nil {
+		return &resolved, err
+	}
+
+	for _, field := range provider.Fields() {
+		if !field.Resolve {
+			continue
+		}
+
+		value, err := tools.ResolveValue(*field.Value(&resolved))
+
+		if err != nil {
+			return nil, err
+		}
+
+		*field.Value(&resolved) = value
+	}
+
+	return &resolved, nil
+}
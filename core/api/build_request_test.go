@@ -0,0 +1,106 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+
+	"github.com/rivo/tview"
+)
+
+func TestBuildHeadersAndQuery(t *testing.T) {
+	req, err := Build(&options.Request{
+		Method:      "POST",
+		URL:         "https://api.example.com/v1/users?sort=name",
+		ContentType: "application/json",
+		Body:        `{"name":"alice"}`,
+		Headers:     map[string]string{"X-Tenant": "acme", "Content-Type": "application/vnd.api+json"},
+		Query:       map[string]string{"page": "2", "q": "a b&c"},
+		AuthType:    &options.Auth{Type: "apikey", APIKey: options.APIKey{Name: "key", Value: "s3cr3t", In: "query"}},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "https://api.example.com/v1/users?sort=name&page=2&q=a+b%26c&key=s3cr3t"; req.URL.String() != expected {
+		t.Errorf("expected %s, got %s", expected, req.URL)
+	}
+
+	if req.Header.Get("X-Tenant") != "acme" {
+		t.Errorf("expected the X-Tenant header, got %q", req.Header.Get("X-Tenant"))
+	}
+
+	// the headers override the content type
+	if req.Header.Get("Content-Type") != "application/vnd.api+json" {
+		t.Errorf("expected the Content-Type of the headers, got %q", req.Header.Get("Content-Type"))
+	}
+}
+
+func TestBuildWithoutQuery(t *testing.T) {
+	req, err := Build(&options.Request{Method: "GET", URL: "https://api.example.com/v1/users?sort=name"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.URL.String() != "https://api.example.com/v1/users?sort=name" {
+		t.Errorf("expected the url to be kept, got %s", req.URL)
+	}
+}
+
+func TestBuildRequest(t *testing.T) {
+	form := tview.NewForm().AddInputField("X-Tenant", "acme", 20, nil, nil)
+
+	req, err := BuildRequest("https://api.example.com/graphql", "GET", "application/graphql", "{ users { id } }", &options.Auth{Type: "basic", BasicAuthUsername: "alice", BasicAuthPassword: "p4ss"}, 1, form)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// graphql queries are posted as json, like they're sent
+	if req.Method != "POST" || req.Header.Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Errorf("expected a json POST, got %s %q", req.Method, req.Header.Get("Content-Type"))
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+
+	if string(body) != `{"query":"{ users { id } }","variables":null}` {
+		t.Errorf("unexpected body %s", body)
+	}
+
+	if req.Header.Get("X-Tenant") != "acme" {
+		t.Errorf("expected the header of the form, got %q", req.Header.Get("X-Tenant"))
+	}
+
+	if username, password, ok := req.BasicAuth(); !ok || username != "alice" || password != "p4ss" {
+		t.Errorf("expected the basic auth to be set, got %q %q", username, password)
+	}
+
+	if _, err := BuildRequest("api.example.com:bad:port", "GET", "", "", nil, 0, nil); err == nil {
+		t.Errorf("expected an error for an invalid url")
+	}
+}
+
+func TestBuildOAuth2WithoutFetching(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("building the request fetched a token from %s", r.URL)
+	}))
+
+	defer server.Close()
+
+	auth := &options.Auth{Type: "oauth2", OAuth2: options.OAuth2{TokenURL: server.URL, ClientID: "resto", ClientSecret: "s3cr3t"}}
+
+	req, err := BuildRequest(server.URL+"/items", "GET", "", "", auth, 0, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer <oauth2 access token>" {
+		t.Errorf("expected a placeholder for the token, got %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/mgutz/ansi"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/pretty"
+)
+
+// responseStyles are the palettes for json responses, dark is the classic pretty.TerminalStyle
+var responseStyles = map[string]*pretty.Style{
+	"dark": pretty.TerminalStyle,
+	"light": {
+		Key:      [2]string{"\x1B[1m\x1B[34m", "\x1B[0m"},
+		String:   [2]string{"\x1B[32m", "\x1B[0m"},
+		Number:   [2]string{"\x1B[35m", "\x1B[0m"},
+		True:     [2]string{"\x1B[34m", "\x1B[0m"},
+		False:    [2]string{"\x1B[34m", "\x1B[0m"},
+		Null:     [2]string{"\x1B[90m", "\x1B[0m"},
+		Escape:   [2]string{"\x1B[31m", "\x1B[0m"},
+		Brackets: [2]string{"\x1B[1m", "\x1B[0m"},
+		Append:   pretty.TerminalStyle.Append,
+	},
+}
+
+var responseStyle = pretty.TerminalStyle
+
+// SetResponseTheme picks the palette used to color json responses ("dark" or "light"),
+// overrides maps parts of the json (key, string, number, true, false, null, escape, brackets)
+// to ansi color specs like "blue+b"
+func SetResponseTheme(theme string, overrides map[string]string) {
+	base, ok := responseStyles[theme]
+
+	if !ok {
+		base = pretty.TerminalStyle
+	}
+
+	style := *base
+
+	for part, spec := range overrides {
+		color := [2]string{ansi.ColorCode(spec), ansi.Reset}
+
+		switch strings.ToLower(part) {
+		case "key":
+			style.Key = color
+		case "string":
+			style.String = color
+		case "number":
+			style.Number = color
+		case "true":
+			style.True = color
+		case "false":
+			style.False = color
+		case "null":
+			style.Null = color
+		case "escape":
+			style.Escape = color
+		case "brackets":
+			style.Brackets = color
+		}
+	}
+
+	responseStyle = &style
+}
+
+// Colorize adds terminal colors to a json value, anything else is returned as is
+func Colorize(value string) string {
+	if !gjson.Valid(value) {
+		return value
+	}
+
+	return string(pretty.Color([]byte(value), responseStyle))
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mgutz/ansi"
+)
+
+func TestSetResponseTheme(t *testing.T) {
+	defer SetResponseTheme("dark", nil)
+
+	SetResponseTheme("dark", nil)
+	dark := Colorize(`{"login": "resto"}`)
+
+	SetResponseTheme("light", nil)
+	light := Colorize(`{"login": "resto"}`)
+
+	if dark == light {
+		t.Errorf("expected the light theme to use other colors than the dark one, got %q", light)
+	}
+
+	SetResponseTheme("solarized", nil)
+
+	if got := Colorize(`{"login": "resto"}`); got != dark {
+		t.Errorf("expected an unknown theme to fall back to the dark one, got %q", got)
+	}
+
+	SetResponseTheme("light", map[string]string{"Key": "red+b", "string": "yellow", "unknown": "blue"})
+	colored := Colorize(`{"login": "resto"}`)
+
+	for _, expected := range []string{
+		ansi.ColorCode("red+b") + `"login"`,
+		ansi.ColorCode("yellow") + `"resto"`,
+	} {
+		if !strings.Contains(colored, expected) {
+			t.Errorf("expected the colors to be overridden with %q, got %q", expected, colored)
+		}
+	}
+}
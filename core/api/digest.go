@@ -0,0 +1,305 @@
+package api
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/abdfnx/resto/core/options"
+)
+
+// digestChallenge is a parsed `WWW-Authenticate: Digest ...` header
+type digestChallenge struct {
+	Realm     string
+	Nonce     string
+	Opaque    string
+	Algorithm string
+	Qop       string
+	Stale     bool
+
+	// nc counts the requests sent with this nonce
+	nc int
+}
+
+var (
+	digestMu         sync.Mutex
+	digestChallenges = map[string]*digestChallenge{}
+)
+
+// digestProvider answers HTTP Digest challenges (RFC 7616), the last challenge of
+// each host is remembered so the next requests authenticate without a 401 round trip
+type digestProvider struct{}
+
+func (digestProvider) Name() string  { return "digest" }
+func (digestProvider) Label() string { return "digest auth" }
+
+// Detect is false, digest credentials look like basic ones so the type must be given
+func (digestProvider) Detect(auth *options.Auth) bool {
+	return false
+}
+
+func (digestProvider) Validate(auth *options.Auth) error {
+	if auth.BasicAuthUsername == "" {
+		return fmt.Errorf("--username is required for digest authentication")
+	}
+
+	return nil
+}
+
+func (digestProvider) Fields() []AuthField {
+	return usernamePasswordFields
+}
+
+func (p digestProvider) Authorize(req *http.Request, auth *options.Auth) error {
+	digestMu.Lock()
+	challenge := digestChallenges[req.URL.Host]
+	digestMu.Unlock()
+
+	if challenge == nil {
+		return nil
+	}
+
+	return p.authorize(req, auth, challenge)
+}
+
+func (p digestProvider) Challenge(req *http.Request, res *http.Response, auth *options.Auth) (*http.Request, error) {
+	challenge, ok := parseDigestChallenges(res.Header.Values("WWW-Authenticate"))
+
+	if !ok {
+		return nil, nil
+	}
+
+	// the request already answered this nonce, so the credentials are wrong, unless the server says it's stale.
+	// a new nonce, like after the server restarted or the remembered one expired, is answered once
+	answered := req.Header.Get("Authorization")
+
+	if !challenge.Stale && len(answered) > 7 && strings.EqualFold(answered[:7], "digest ") && parseAuthParams(answered[7:])["nonce"] == challenge.Nonce {
+		return nil, nil
+	}
+
+	digestMu.Lock()
+	digestChallenges[req.URL.Host] = challenge
+	digestMu.Unlock()
+
+	retry, err := retryRequest(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.authorize(retry, auth, challenge); err != nil {
+		return nil, err
+	}
+
+	return retry, nil
+}
+
+func (digestProvider) authorize(req *http.Request, auth *options.Auth, challenge *digestChallenge) error {
+	digestMu.Lock()
+	challenge.nc++
+	nc := challenge.nc
+	digestMu.Unlock()
+
+	cnonce, err := newCnonce()
+
+	if err != nil {
+		return err
+	}
+
+	authorization, err := digestAuthorization(challenge, req.Method, req.URL.RequestURI(), auth.BasicAuthUsername, auth.BasicAuthPassword, nc, cnonce)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", authorization)
+
+	return nil
+}
+
+// digestAuthorization computes the Authorization header that answers challenge
+func digestAuthorization(challenge *digestChallenge, method, uri, username, password string, nc int, cnonce string) (string, error) {
+	algorithm := strings.ToUpper(challenge.Algorithm)
+
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+
+	var newHash func() hash.Hash
+
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %q", challenge.Algorithm)
+	}
+
+	h := func(s string) string {
+		digest := newHash()
+		digest.Write([]byte(s))
+
+		return hex.EncodeToString(digest.Sum(nil))
+	}
+
+	ncValue := fmt.Sprintf("%08x", nc)
+	ha1 := h(username + ":" + challenge.Realm + ":" + password)
+
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = h(ha1 + ":" + challenge.Nonce + ":" + cnonce)
+	}
+
+	ha2 := h(method + ":" + uri)
+
+	var response string
+
+	if challenge.Qop == "" {
+		// RFC 2069 compatibility, servers that don't send a qop
+		response = h(ha1 + ":" + challenge.Nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + challenge.Nonce + ":" + ncValue + ":" + cnonce + ":" + challenge.Qop + ":" + ha2)
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, quoteAuthParam(username)),
+		fmt.Sprintf(`realm="%s"`, quoteAuthParam(challenge.Realm)),
+		fmt.Sprintf(`nonce="%s"`, quoteAuthParam(challenge.Nonce)),
+		fmt.Sprintf(`uri="%s"`, quoteAuthParam(uri)),
+		fmt.Sprintf(`algorithm=%s`, algorithm),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+
+	if challenge.Qop != "" {
+		parts = append(parts,
+			fmt.Sprintf(`qop=%s`, challenge.Qop),
+			fmt.Sprintf(`nc=%s`, ncValue),
+			fmt.Sprintf(`cnonce="%s"`, cnonce),
+		)
+	}
+
+	if challenge.Opaque != "" {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, quoteAuthParam(challenge.Opaque)))
+	}
+
+	return "Digest " + strings.Join(parts, ", "), nil
+}
+
+// parseDigestChallenges picks the strongest digest challenge we support out of the WWW-Authenticate headers
+func parseDigestChallenges(headers []string) (*digestChallenge, bool) {
+	var best *digestChallenge
+
+	for _, header := range headers {
+		if len(header) < 7 || !strings.EqualFold(header[:7], "digest ") {
+			continue
+		}
+
+		params := parseAuthParams(header[7:])
+		challenge := &digestChallenge{
+			Realm:     params["realm"],
+			Nonce:     params["nonce"],
+			Opaque:    params["opaque"],
+			Algorithm: params["algorithm"],
+			Stale:     strings.EqualFold(params["stale"], "true"),
+		}
+
+		if qop, ok := params["qop"]; ok {
+			for _, option := range strings.Split(qop, ",") {
+				if strings.TrimSpace(option) == "auth" {
+					challenge.Qop = "auth"
+				}
+			}
+
+			// only auth-int is offered, we don't hash bodies into the response
+			if challenge.Qop == "" {
+				continue
+			}
+		}
+
+		algorithm := strings.TrimSuffix(strings.ToUpper(challenge.Algorithm), "-SESS")
+
+		if algorithm != "" && algorithm != "MD5" && algorithm != "SHA-256" {
+			continue
+		}
+
+		if best == nil || algorithm == "SHA-256" {
+			best = challenge
+		}
+	}
+
+	return best, best != nil
+}
+
+// parseAuthParams parses `key=value, key="quoted, value"` lists of auth headers
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,\t")
+		eq := strings.Index(s, "=")
+
+		if eq == -1 {
+			break
+		}
+
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value string
+
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+
+				b.WriteByte(s[i])
+			}
+
+			value = b.String()
+
+			if i < len(s) {
+				i++
+			}
+
+			s = s[i:]
+		} else {
+			end := strings.Index(s, ",")
+
+			if end == -1 {
+				end = len(s)
+			}
+
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+
+		params[key] = value
+	}
+
+	return params
+}
+
+// quoteAuthParam escapes the quotes and backslashes of a quoted-string value, parseAuthParams unescapes them
+func quoteAuthParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+func newCnonce() (string, error) {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
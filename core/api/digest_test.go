@@ -0,0 +1,210 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+)
+
+var digestCases = []struct {
+	name      string
+	challenge digestChallenge
+	username  string
+	password  string
+	cnonce    string
+	expected  string
+}{
+	{
+		name: "RFC 2617 MD5",
+		challenge: digestChallenge{
+			Realm: "testrealm@host.com",
+			Nonce: "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+			Qop:   "auth",
+		},
+		username: "Mufasa",
+		password: "Circle Of Life",
+		cnonce:   "0a4f113b",
+		expected: "6629fae49393a05397450978507c4ef1",
+	},
+	{
+		name: "RFC 7616 MD5",
+		challenge: digestChallenge{
+			Realm:     "http-auth@example.org",
+			Nonce:     "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+			Algorithm: "MD5",
+			Qop:       "auth",
+		},
+		username: "Mufasa",
+		password: "Circle of Life",
+		cnonce:   "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ",
+		expected: "8ca523f5e9506fed4657c9700eebdbec",
+	},
+	{
+		name: "RFC 7616 SHA-256",
+		challenge: digestChallenge{
+			Realm:     "http-auth@example.org",
+			Nonce:     "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+			Algorithm: "SHA-256",
+			Qop:       "auth",
+		},
+		username: "Mufasa",
+		password: "Circle of Life",
+		cnonce:   "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ",
+		expected: "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1",
+	},
+}
+
+func TestDigestAuthorization(t *testing.T) {
+	for _, tt := range digestCases {
+		auth, err := digestAuthorization(&tt.challenge, "GET", "/dir/index.html", tt.username, tt.password, 1, tt.cnonce)
+
+		if err != nil {
+			t.Fatalf("%s :: %s", tt.name, err.Error())
+		}
+
+		if !strings.Contains(auth, `response="`+tt.expected+`"`) {
+			t.Errorf("%s :: unexpected header %s", tt.name, auth)
+		}
+	}
+}
+
+func TestParseDigestChallenges(t *testing.T) {
+	challenge, ok := parseDigestChallenges([]string{
+		`Basic realm="api"`,
+		`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=MD5, nonce="abc", opaque="xyz"`,
+		`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=SHA-256, nonce="abc", opaque="xyz"`,
+	})
+
+	if !ok {
+		t.Fatal("no challenge found")
+	}
+
+	if challenge.Algorithm != "SHA-256" || challenge.Qop != "auth" || challenge.Opaque != "xyz" || challenge.Realm != "http-auth@example.org" {
+		t.Errorf("unexpected challenge %+v", challenge)
+	}
+}
+
+func TestDigestTransport(t *testing.T) {
+	var nonces []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+
+		if auth == "" {
+			w.Header().Set("WWW-Authenticate", `Digest realm="resto", qop="auth", algorithm=SHA-256, nonce="n0nce", opaque="op"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		params := parseAuthParams(strings.TrimPrefix(auth, "Digest "))
+		challenge := &digestChallenge{Realm: "resto", Nonce: "n0nce", Algorithm: "SHA-256", Qop: "auth"}
+
+		var nc int
+		fmt.Sscanf(params["nc"], "%x", &nc)
+
+		expected, _ := digestAuthorization(challenge, r.Method, r.URL.RequestURI(), "user", "secret", nc, params["cnonce"])
+
+		if !strings.Contains(expected, `response="`+params["response"]+`"`) || params["opaque"] != "op" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		nonces = append(nonces, params["nc"])
+		fmt.Fprint(w, "ok")
+	}))
+
+	defer server.Close()
+
+	client, err := withAuth(&http.Client{}, &options.Auth{Type: "digest", BasicAuthUsername: "user", BasicAuthPassword: "secret"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := client.Post(server.URL+"/items?page=1", "text/plain", strings.NewReader("body"))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("request %d :: got status %d", i, res.StatusCode)
+		}
+	}
+
+	if len(nonces) != 2 || nonces[0] != "00000001" || nonces[1] != "00000002" {
+		t.Errorf("nonce count isn't incremented: %v", nonces)
+	}
+}
+
+func TestDigestTransportNewNonce(t *testing.T) {
+	nonce := "first"
+	var attempts int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		params := parseAuthParams(strings.TrimPrefix(r.Header.Get("Authorization"), "Digest "))
+
+		if params["nonce"] != nonce || params["username"] != `us"er\` {
+			w.Header().Set("WWW-Authenticate", `Digest realm="resto", qop="auth", nonce="`+nonce+`"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, "ok")
+	}))
+
+	defer server.Close()
+
+	client, err := withAuth(&http.Client{}, &options.Auth{Type: "digest", BasicAuthUsername: `us"er\`, BasicAuthPassword: "secret"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() int {
+		res, err := client.Get(server.URL)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	if status := get(); status != http.StatusOK {
+		t.Fatalf("expected the first challenge to be answered, got status %d", status)
+	}
+
+	// the remembered nonce is rejected without stale=true, the new one is answered once
+	nonce = "second"
+	attempts = 0
+
+	if status := get(); status != http.StatusOK || attempts != 2 {
+		t.Errorf("expected the new nonce to be answered, got status %d after %d attempts", status, attempts)
+	}
+
+	// the credentials are wrong when the answer to the current nonce is rejected
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		w.Header().Set("WWW-Authenticate", `Digest realm="resto", qop="auth", nonce="`+nonce+`"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	attempts = 0
+
+	if status := get(); status != http.StatusUnauthorized || attempts != 1 {
+		t.Errorf("expected no retry for the same nonce, got status %d after %d attempts", status, attempts)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/abdfnx/resto/core/options"
+)
+
+// Response is a response as it's received, before it's formatted
+type Response struct {
+	Status     string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// Duration is the time from sending the request to reading the whole body
+	Duration time.Duration
+}
+
+// Exchange sends r and returns its response as it's received, graphql requests are sent as a json encoded query
+func Exchange(r *options.Request) (*Response, error) {
+	start := time.Now()
+
+	res, err := roundTrip(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Status:     res.Status,
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+		Duration:   time.Since(start),
+	}, nil
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abdfnx/resto/core/jwt"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/ios"
+	"github.com/abdfnx/resto/tools"
+)
+
+// WarnExpiredToken tells right away when the bearer token of auth is a JWT that already expired,
+// for the request commands and resto run
+func WarnExpiredToken(io *ios.IOStreams, auth *options.Auth) {
+	if auth == nil || auth.Type != "bearer" {
+		return
+	}
+
+	// a token that can't be resolved fails when the request is sent
+	token, err := tools.ResolveValue(auth.TokenAuth)
+
+	if err != nil {
+		return
+	}
+
+	for _, warning := range jwt.ExpiredWarnings(token, time.Now()) {
+		fmt.Fprintf(io.ErrOut, "%s %s, see `resto jwt decode`\n", io.ColorScheme().WarningIcon(), warning)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/pretty"
+)
+
+// FilterResponse extracts the value at a gjson path from a json response,
+// with rawOutput strings are returned without quotes like `jq -r` does
+func FilterResponse(response, path string, rawOutput bool) (string, error) {
+	if path == "" {
+		return response, nil
+	}
+
+	if !gjson.Valid(response) {
+		return "", fmt.Errorf("the response is not valid JSON, can't apply filter %q", path)
+	}
+
+	result := gjson.Get(response, path)
+
+	if !result.Exists() {
+		return "null", nil
+	}
+
+	if rawOutput && result.Type == gjson.String {
+		return result.String(), nil
+	}
+
+	return strings.TrimSpace(string(pretty.Pretty([]byte(result.Raw)))), nil
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+const filterResponse = `{"user": {"login": "resto", "id": 42, "admin": false}, "repos": [{"name": "cli", "stars": 10}, {"name": "ui", "stars": 3}]}`
+
+var filterCases = []struct {
+	name      string
+	path      string
+	rawOutput bool
+	expected  string
+}{
+	{
+		name:     "no filter",
+		path:     "",
+		expected: filterResponse,
+	},
+	{
+		name:     "string",
+		path:     "user.login",
+		expected: `"resto"`,
+	},
+	{
+		name:      "raw string",
+		path:      "user.login",
+		rawOutput: true,
+		expected:  "resto",
+	},
+	{
+		name:      "raw number",
+		path:      "user.id",
+		rawOutput: true,
+		expected:  "42",
+	},
+	{
+		name:     "array index",
+		path:     "repos.1.name",
+		expected: `"ui"`,
+	},
+	{
+		name:     "array values",
+		path:     "repos.#.stars",
+		expected: "[10, 3]",
+	},
+	{
+		name:     "query",
+		path:     `repos.#(stars>5).name`,
+		expected: `"cli"`,
+	},
+	{
+		name:     "object",
+		path:     "user",
+		expected: "{\n  \"login\": \"resto\",\n  \"id\": 42,\n  \"admin\": false\n}",
+	},
+	{
+		name:      "raw object",
+		path:      "user",
+		rawOutput: true,
+		expected:  "{\n  \"login\": \"resto\",\n  \"id\": 42,\n  \"admin\": false\n}",
+	},
+	{
+		name:     "missing",
+		path:     "user.email",
+		expected: "null",
+	},
+}
+
+func TestFilterResponse(t *testing.T) {
+	for _, c := range filterCases {
+		got, err := FilterResponse(filterResponse, c.path, c.rawOutput)
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if got != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, got)
+		}
+	}
+
+	if _, err := FilterResponse("<html></html>", "user", false); err == nil {
+		t.Errorf("expected an error for a response that isn't JSON")
+	}
+
+	if got, err := FilterResponse("<html></html>", "", false); err != nil || got != "<html></html>" {
+		t.Errorf("expected the response as is without a filter, got %q, %v", got, err)
+	}
+}
+
+func TestColorize(t *testing.T) {
+	SetResponseTheme("dark", nil)
+
+	colored := Colorize(`{"login": "resto"}`)
+
+	if !strings.Contains(colored, "\x1b[") {
+		t.Errorf("expected the json to be colored, got %q", colored)
+	}
+
+	if plain := stripColors(colored); plain != `{"login": "resto"}` {
+		t.Errorf("expected the colors to wrap the json as is, got %q", plain)
+	}
+
+	if got := Colorize("not json"); got != "not json" {
+		t.Errorf("expected text that isn't json to be left as is, got %q", got)
+	}
+}
+
+// stripColors removes the ansi sequences of a colored value
+func stripColors(value string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\x1b' {
+			for i < len(value) && value[i] != 'm' {
+				i++
+			}
+
+			continue
+		}
+
+		b.WriteByte(value[i])
+	}
+
+	return b.String()
+}
@@ -19,15 +19,27 @@ var (
 
 // formatResponse formats the Response with Indents and Colors
 func formatResponse(resp *http.Response, isCommand bool) (string, string, string, error) {
-	heads := fmt.Sprint(resp.Header)
-	toReturn := ""
-
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return "", "", "", err
 	}
 
-	str := string(body)
+	respone, status, headers := Format(&Response{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, isCommand)
+
+	return respone, status, headers, nil
+}
+
+// Format formats a response like the ones resto sends, it returns the body, the status table and the headers table
+func Format(resp *Response, isCommand bool) (string, string, string) {
+	heads := fmt.Sprint(resp.Header)
+	toReturn := ""
+
+	str := string(resp.Body)
 
 	statusTable := table.NewWriter()
 	statusTable.AppendHeader(statusRowHeader)
@@ -52,7 +64,7 @@ func formatResponse(resp *http.Response, isCommand bool) (string, string, string
 
 	if strings.Contains(heads, "json") {
 		if isCommand {
-			toReturn = string(pretty.Color(pretty.Pretty([]byte(str)), nil))
+			toReturn = string(pretty.Color(pretty.Pretty([]byte(str)), responseStyle))
 		} else {
 			toReturn = string(pretty.Pretty([]byte(str)))
 		}
@@ -68,5 +80,5 @@ func formatResponse(resp *http.Response, isCommand bool) (string, string, string
 		toReturn = s
 	}
 
-	return toReturn, statusTable.Render(), headersTable.Render(), nil
+	return toReturn, statusTable.Render(), headersTable.Render()
 }
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"fmt"
 	"time"
 	"io/ioutil"
 	"net/http"
@@ -12,24 +11,28 @@ import (
 	"github.com/briandowns/spinner"
 )
 
+// GetLatest returns the latest released version of resto, or an empty string if it can't be checked,
+// it prints nothing so the output of the command it runs after stays as it is
 func GetLatest() string {
 	url := "https://api.github.com/repos/abdfnx/resto/releases/latest"
 
 	req, err := http.NewRequest("GET", url, nil)
 
 	if err != nil {
-		fmt.Errorf("Error creating request: %s", err.Error())
+		return ""
 	}
 
 	s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
 	s.Suffix = " 🔍 Checking for updates..."
 	s.Start()
 
+	defer s.Stop()
+
 	client := httpClient.HttpClient()
 	res, err := client.Do(req)
 
 	if err != nil {
-		fmt.Printf("Error sending request: %s", err.Error())
+		return ""
 	}
 
 	defer res.Body.Close()
@@ -37,7 +40,7 @@ func GetLatest() string {
 	b, err := ioutil.ReadAll(res.Body)
 
 	if err != nil {
-		fmt.Printf("Error reading response: %s", err.Error())
+		return ""
 	}
 
 	body := string(b)
@@ -46,7 +49,5 @@ func GetLatest() string {
 
 	latestVersion := tag_name.String()
 
-	s.Stop()
-
 	return latestVersion
 }
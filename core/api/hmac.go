@@ -0,0 +1,116 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/abdfnx/resto/core/hmac"
+	"github.com/abdfnx/resto/core/options"
+)
+
+var hmacNow = time.Now
+
+// hmacProvider signs a canonical string of the request, for webhook style APIs
+type hmacProvider struct{}
+
+func (hmacProvider) Name() string  { return "hmac" }
+func (hmacProvider) Label() string { return "hmac signature" }
+
+func (hmacProvider) Detect(auth *options.Auth) bool {
+	return auth.HMAC.Key != ""
+}
+
+func (hmacProvider) Validate(auth *options.Auth) error {
+	if auth.HMAC.Key == "" {
+		return fmt.Errorf("--hmac-key is required for hmac authentication")
+	}
+
+	return hmac.Validate(auth.HMAC)
+}
+
+func (hmacProvider) Authorize(req *http.Request, auth *options.Auth) error {
+	cfg := hmac.Config(auth.HMAC)
+
+	msg, err := hmacMessage(req)
+
+	if err != nil {
+		return err
+	}
+
+	if hmac.UsesTimestamp(cfg.Template) {
+		msg.Timestamp = strconv.FormatInt(hmacNow().Unix(), 10)
+		req.Header.Set(cfg.TimestampHeader, msg.Timestamp)
+	}
+
+	signature, err := hmac.Sign(cfg, hmac.Canonical(cfg.Template, msg))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(cfg.Header, signature)
+
+	return nil
+}
+
+func (hmacProvider) Fields() []AuthField {
+	return []AuthField{
+		{
+			Key: "key", Label: "HMAC Key", Secret: true, Resolve: true,
+			Flag: "hmac-key", Usage: "The key to sign the request with HMAC, env:NAME reads it from an env variable",
+			Value: func(auth *options.Auth) *string { return &auth.HMAC.Key },
+		},
+		{
+			Key: "algorithm", Label: "Algorithm", Options: []string{"sha256", "sha512", "sha1"},
+			Flag: "hmac-algorithm", Usage: "The HMAC hash: sha256, sha512 or sha1 (Default: sha256)",
+			Value: func(auth *options.Auth) *string { return &auth.HMAC.Algorithm },
+		},
+		{
+			Key: "header", Label: "Signature Header", Default: hmac.DefaultHeader,
+			Flag: "hmac-header", Usage: "The header of the HMAC signature (Default: " + hmac.DefaultHeader + ")",
+			Value: func(auth *options.Auth) *string { return &auth.HMAC.Header },
+		},
+		{
+			Key: "template", Label: "Canonical String", Default: hmac.DefaultTemplate,
+			Flag: "hmac-template", Usage: "The canonical string to sign, with {method}, {host}, {path}, {query}, {uri}, {timestamp}, {body} and {body_sha256} (Default: {method}\\n{path}\\n{timestamp}\\n{body})",
+			Value: func(auth *options.Auth) *string { return &auth.HMAC.Template },
+		},
+		{
+			Key: "timestampHeader", Label: "Timestamp Header", Default: hmac.DefaultTimestampHeader,
+			Flag: "hmac-timestamp-header", Usage: "The header of the unix timestamp of signed requests (Default: " + hmac.DefaultTimestampHeader + ")",
+			Value: func(auth *options.Auth) *string { return &auth.HMAC.TimestampHeader },
+		},
+		{
+			Key: "encoding", Label: "Encoding", Options: []string{"hex", "base64"},
+			Flag: "hmac-encoding", Usage: "The encoding of the HMAC signature: hex or base64 (Default: hex)",
+			Value: func(auth *options.Auth) *string { return &auth.HMAC.Encoding },
+		},
+	}
+}
+
+// hmacMessage reads the signed parts of req, the body is read from a copy so it can still be sent
+func hmacMessage(req *http.Request) (hmac.Message, error) {
+	msg := hmac.Message{
+		Method: req.Method,
+		Host:   req.URL.Host,
+		Path:   req.URL.EscapedPath(),
+		Query:  req.URL.RawQuery,
+	}
+
+	if req.GetBody != nil {
+		reader, err := req.GetBody()
+
+		if err != nil {
+			return msg, err
+		}
+
+		if msg.Body, err = ioutil.ReadAll(reader); err != nil {
+			return msg, err
+		}
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abdfnx/resto/core/hmac"
+	"github.com/abdfnx/resto/core/options"
+)
+
+func TestHMACProvider(t *testing.T) {
+	hmacNow = func() time.Time { return time.Unix(1700000000, 0) }
+	defer func() { hmacNow = time.Now }()
+
+	auth := &options.Auth{Type: "hmac", HMAC: options.HMAC{Key: "s3cr3t", Header: "X-Hub-Signature"}}
+
+	req, err := BuildRequest("https://hooks.example.com/orders?dry_run=1", "POST", "application/json", `{"id":42}`, auth, 0, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Header.Get("X-Timestamp") != "1700000000" {
+		t.Errorf("expected the timestamp header, got %q", req.Header.Get("X-Timestamp"))
+	}
+
+	msg := hmac.Message{Method: "POST", Path: "/orders", Query: "dry_run=1", Timestamp: "1700000000", Body: []byte(`{"id":42}`)}
+	result, err := hmac.Verify(auth.HMAC, msg, req.Header.Get("X-Hub-Signature"))
+
+	if err != nil || !result.Match {
+		t.Errorf("expected a valid signature, got %q for %q", req.Header.Get("X-Hub-Signature"), result.Canonical)
+	}
+
+	auth.HMAC.Template = `{method} {uri}`
+
+	req, _ = BuildRequest("https://hooks.example.com/orders", "GET", "", "", auth, 0, nil)
+
+	if req.Header.Get("X-Timestamp") != "" {
+		t.Errorf("expected no timestamp when the template doesn't use it")
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	httpClient "github.com/abdfnx/resto/client"
+	"github.com/abdfnx/resto/core/credentials"
+	"github.com/abdfnx/resto/core/options"
+)
+
+// roundTrip sends every request resto builds, r is authorized with its credentials or the stored ones of its host,
+// the body of the response is closed by the caller
+func roundTrip(r *options.Request) (*http.Response, error) {
+	auth, err := resolveAuth(hostAuth(r.URL, r.AuthType))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := buildRequest(r.URL, r.Method, r.ContentType, r.Body, r.Headers, r.Query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := httpClient.HttpClient()
+
+	if r.Insecure {
+		client = httpClient.InsecureHttpClient()
+	}
+
+	client, err = withAuth(client, auth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+
+	if err != nil {
+		return nil, redactError(fmt.Errorf("Error sending request: %s", err.Error()), auth)
+	}
+
+	return res, nil
+}
+
+// hostAuth uses the credentials stored for the host of httpURL when the request has no auth,
+// from `resto auth add` or the .netrc file
+func hostAuth(httpURL string, auth *options.Auth) *options.Auth {
+	if auth != nil && auth.Type != "" {
+		return auth
+	}
+
+	if stored, ok := credentials.Lookup(httpURL); ok {
+		return stored
+	}
+
+	return auth
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abdfnx/resto/core/options"
+)
+
+const sigv4Algorithm = "AWS4-HMAC-SHA256"
+
+var sigv4Now = time.Now
+
+// sigv4UnsignedHeaders may be changed by proxies or the transport, so they aren't signed
+var sigv4UnsignedHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"expect":          true,
+	"content-length":  true,
+	"x-amzn-trace-id": true,
+}
+
+// signSigV4 signs req with AWS Signature Version 4, the region and service are guessed from the host when they aren't set
+func signSigV4(req *http.Request, cfg options.AWS) error {
+	cfg, err := awsCredentials(cfg)
+
+	if err != nil {
+		return err
+	}
+
+	service, region := awsScope(req.URL.Hostname())
+
+	if cfg.Service == "" {
+		cfg.Service = service
+	}
+
+	if cfg.Region == "" {
+		cfg.Region = region
+	}
+
+	if cfg.Service == "" {
+		return fmt.Errorf("aws-sigv4: can't guess the service of %s, set it with --aws-service", req.URL.Host)
+	}
+
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	body := []byte{}
+
+	if req.GetBody != nil {
+		reader, err := req.GetBody()
+
+		if err != nil {
+			return err
+		}
+
+		body, err = ioutil.ReadAll(reader)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	sigv4Sign(req, body, cfg, sigv4Now().UTC())
+
+	return nil
+}
+
+// sigv4Provider signs requests with AWS Signature Version 4
+type sigv4Provider struct{}
+
+func (sigv4Provider) Name() string  { return "aws-sigv4" }
+func (sigv4Provider) Label() string { return "aws sigv4" }
+
+func (sigv4Provider) Detect(auth *options.Auth) bool {
+	return auth.AWS.AccessKeyID != "" || auth.AWS.Profile != ""
+}
+
+// Validate accepts empty credentials, they can also come from the AWS env variables or the shared credentials file
+func (sigv4Provider) Validate(auth *options.Auth) error {
+	return nil
+}
+
+func (sigv4Provider) Authorize(req *http.Request, auth *options.Auth) error {
+	return signSigV4(req, auth.AWS)
+}
+
+func (sigv4Provider) Fields() []AuthField {
+	return []AuthField{
+		{
+			Key: "profile", Label: "Profile",
+			Flag: "aws-profile", Usage: "The profile of the AWS shared credentials file (Default: AWS_PROFILE or default)",
+			Value: func(auth *options.Auth) *string { return &auth.AWS.Profile },
+		},
+		{
+			Key: "accessKeyId", Label: "Access Key ID", Resolve: true,
+			Flag: "aws-access-key-id", Usage: "The AWS access key id (Default: AWS_ACCESS_KEY_ID or the shared credentials file)",
+			Value: func(auth *options.Auth) *string { return &auth.AWS.AccessKeyID },
+		},
+		{
+			Key: "secretAccessKey", Label: "Secret Access Key", Secret: true, Resolve: true,
+			Flag: "aws-secret-access-key", Usage: "The AWS secret access key (Default: AWS_SECRET_ACCESS_KEY or the shared credentials file)",
+			Value: func(auth *options.Auth) *string { return &auth.AWS.SecretAccessKey },
+		},
+		{
+			Key: "sessionToken", Label: "Session Token", Secret: true, Resolve: true,
+			Flag: "aws-session-token", Usage: "The AWS session token of temporary credentials",
+			Value: func(auth *options.Auth) *string { return &auth.AWS.SessionToken },
+		},
+		{
+			Key: "region", Label: "Region",
+			Flag: "aws-region", Usage: "The AWS region to sign for (Default: from the host, AWS_REGION or the config file)",
+			Value: func(auth *options.Auth) *string { return &auth.AWS.Region },
+		},
+		{
+			Key: "service", Label: "Service",
+			Flag: "aws-service", Usage: "The AWS service to sign for, like s3, es or execute-api (Default: from the host)",
+			Value: func(auth *options.Auth) *string { return &auth.AWS.Service },
+		},
+	}
+}
+
+func sigv4Sign(req *http.Request, body []byte, cfg options.AWS, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := hexSHA256(body)
+
+	req.Header.Del("Authorization")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	if cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+
+	if cfg.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers, signedHeaders := sigv4Headers(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigv4Path(req.URL.Path, cfg.Service),
+		sigv4Query(req.URL.RawQuery),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, cfg.Region, cfg.Service, "aws4_request"}, "/")
+
+	stringToSign := strings.Join([]string{
+		sigv4Algorithm,
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, cfg.Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigv4Algorithm, cfg.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// sigv4Headers returns the canonical headers and the signed headers list
+func sigv4Headers(req *http.Request) (string, string) {
+	values := map[string]string{}
+
+	host := req.Host
+
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	values["host"] = host
+
+	for key, header := range req.Header {
+		name := strings.ToLower(key)
+
+		if sigv4UnsignedHeaders[name] {
+			continue
+		}
+
+		trimmed := make([]string, len(header))
+
+		for i, value := range header {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+
+		values[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonical strings.Builder
+
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+
+	return canonical.String(), strings.Join(names, ";")
+}
+
+// sigv4Path encodes each segment of the path, other services than S3 also normalize it
+func sigv4Path(path, service string) string {
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	cleaned := []string{}
+
+	for _, segment := range segments {
+		if service != "s3" {
+			if segment == "." {
+				continue
+			}
+
+			if segment == ".." {
+				if len(cleaned) > 1 {
+					cleaned = cleaned[:len(cleaned)-1]
+				}
+
+				continue
+			}
+		}
+
+		cleaned = append(cleaned, sigv4Escape(segment))
+	}
+
+	encoded := strings.Join(cleaned, "/")
+
+	if service != "s3" {
+		for strings.Contains(encoded, "//") {
+			encoded = strings.Replace(encoded, "//", "/", -1)
+		}
+	}
+
+	if !strings.HasPrefix(encoded, "/") {
+		encoded = "/" + encoded
+	}
+
+	return encoded
+}
+
+// sigv4Query sorts the query params by name and value, encoded the AWS way
+func sigv4Query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	params := []string{}
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		key, value := pair, ""
+
+		if i := strings.Index(pair, "="); i != -1 {
+			key, value = pair[:i], pair[i+1:]
+		}
+
+		params = append(params, sigv4Escape(queryUnescape(key))+"="+sigv4Escape(queryUnescape(value)))
+	}
+
+	sort.Strings(params)
+
+	return strings.Join(params, "&")
+}
+
+func queryUnescape(value string) string {
+	unescaped, err := url.QueryUnescape(value)
+
+	if err != nil {
+		return value
+	}
+
+	return unescaped
+}
+
+// sigv4Escape percent encodes everything except the unreserved characters of RFC 3986
+func sigv4Escape(value string) string {
+	var encoded strings.Builder
+
+	for _, b := range []byte(value) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+
+	return encoded.String()
+}
+
+// awsScope guesses the service and region of an AWS endpoint, like `execute-api.eu-west-1.amazonaws.com`
+func awsScope(host string) (string, string) {
+	if !strings.HasSuffix(host, ".amazonaws.com") {
+		return "", ""
+	}
+
+	labels := strings.Split(strings.TrimSuffix(host, ".amazonaws.com"), ".")
+	n := len(labels)
+
+	isRegion := func(label string) bool {
+		return strings.Count(label, "-") >= 2
+	}
+
+	// <domain>.<region>.es.amazonaws.com
+	if n >= 3 && (labels[n-1] == "es" || labels[n-1] == "aoss") && isRegion(labels[n-2]) {
+		return labels[n-1], labels[n-2]
+	}
+
+	// <service>.<region>.amazonaws.com, <bucket>.s3.<region>.amazonaws.com
+	if n >= 2 && isRegion(labels[n-1]) {
+		service := labels[n-2]
+
+		if strings.HasPrefix(service, "s3") {
+			service = "s3"
+		}
+
+		return service, labels[n-1]
+	}
+
+	// global endpoints like iam.amazonaws.com or s3.amazonaws.com
+	service := labels[n-1]
+
+	if strings.HasPrefix(service, "s3") {
+		service = "s3"
+	}
+
+	return service, "us-east-1"
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdfnx/resto/core/options"
+)
+
+var sigv4Credentials = options.AWS{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	Region:          "us-east-1",
+}
+
+// cases from the AWS Signature Version 4 test suite and the IAM docs
+var sigv4Cases = []struct {
+	name     string
+	method   string
+	url      string
+	service  string
+	headers  map[string]string
+	expected string
+}{
+	{
+		name:     "get-vanilla",
+		method:   "GET",
+		url:      "https://example.amazonaws.com/",
+		service:  "service",
+		expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+	},
+	{
+		name:    "iam ListUsers",
+		method:  "GET",
+		url:     "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+		service: "iam",
+		headers: map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded; charset=utf-8",
+		},
+		expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+	},
+}
+
+func TestSigV4Sign(t *testing.T) {
+	date := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	for _, c := range sigv4Cases {
+		req, _ := http.NewRequest(c.method, c.url, nil)
+
+		for key, value := range c.headers {
+			req.Header.Set(key, value)
+		}
+
+		cfg := sigv4Credentials
+		cfg.Service = c.service
+
+		sigv4Sign(req, nil, cfg, date)
+
+		if got := req.Header.Get("Authorization"); got != c.expected {
+			t.Errorf("%s:\nexpected %s\n     got %s", c.name, c.expected, got)
+		}
+
+		if req.Header.Get("X-Amz-Date") != "20150830T123600Z" {
+			t.Errorf("%s: unexpected X-Amz-Date %q", c.name, req.Header.Get("X-Amz-Date"))
+		}
+	}
+}
+
+func TestSigV4Canonical(t *testing.T) {
+	paths := map[string]string{
+		"":                  "/",
+		"/example/../space": "/space",
+		"/./a//b/ሴ":         "/a/b/%E1%88%B4",
+	}
+
+	for path, expected := range paths {
+		if got := sigv4Path(path, "service"); got != expected {
+			t.Errorf("path %q: expected %q, got %q", path, expected, got)
+		}
+	}
+
+	if got := sigv4Path("/bucket/a//b", "s3"); got != "/bucket/a//b" {
+		t.Errorf("s3 paths shouldn't be normalized, got %q", got)
+	}
+
+	if got := sigv4Query("b=2&a=2&a=1&c=x+y&d"); got != "a=1&a=2&b=2&c=x%20y&d=" {
+		t.Errorf("unexpected canonical query %q", got)
+	}
+}
+
+func TestAWSScope(t *testing.T) {
+	hosts := map[string][2]string{
+		"abc123.execute-api.eu-west-1.amazonaws.com": {"execute-api", "eu-west-1"},
+		"search-logs-abc.us-west-2.es.amazonaws.com": {"es", "us-west-2"},
+		"bucket.s3.eu-central-1.amazonaws.com":       {"s3", "eu-central-1"},
+		"iam.amazonaws.com":                          {"iam", "us-east-1"},
+		"localhost":                                  {"", ""},
+	}
+
+	for host, expected := range hosts {
+		service, region := awsScope(host)
+
+		if service != expected[0] || region != expected[1] {
+			t.Errorf("%s: expected %v, got %s %s", host, expected, service, region)
+		}
+	}
+}
+
+func TestAWSSharedCredentials(t *testing.T) {
+	dir := t.TempDir()
+	credentials := filepath.Join(dir, "credentials")
+	config := filepath.Join(dir, "config")
+
+	os.WriteFile(credentials, []byte(strings.Join([]string{
+		"[default]",
+		"aws_access_key_id = DEFAULTKEY",
+		"aws_secret_access_key = defaultsecret",
+		"",
+		"[minio]",
+		"aws_access_key_id = minioadmin",
+		"aws_secret_access_key = miniosecret",
+	}, "\n")), 0600)
+
+	os.WriteFile(config, []byte("[profile minio]\nregion = eu-west-3\n"), 0600)
+
+	for _, env := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		t.Setenv(env, "")
+	}
+
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentials)
+	t.Setenv("AWS_CONFIG_FILE", config)
+
+	cfg, err := awsCredentials(options.AWS{Profile: "minio"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.AccessKeyID != "minioadmin" || cfg.SecretAccessKey != "miniosecret" || cfg.Region != "eu-west-3" {
+		t.Errorf("unexpected credentials: %+v", cfg)
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+
+	cfg, err = awsCredentials(options.AWS{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.AccessKeyID != "ENVKEY" || cfg.SecretAccessKey != "envsecret" {
+		t.Errorf("expected the env credentials, got %+v", cfg)
+	}
+}
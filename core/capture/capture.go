@@ -0,0 +1,115 @@
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/abdfnx/resto/core/api"
+
+	"github.com/tidwall/gjson"
+)
+
+// Value returns the value selected by source in a response:
+//
+//	json:data.access_token   a gjson path of the body, strings are returned without quotes
+//	header:ETag              a header
+//	regex:token=(\w+)        the first group of a regex of the body, or the whole match
+//	status                   the status code
+func Value(source string, res *api.Response) (string, error) {
+	kind, arg := source, ""
+
+	if i := strings.Index(source, ":"); i != -1 {
+		kind, arg = source[:i], source[i+1:]
+	}
+
+	switch kind {
+	case "json":
+		if !gjson.ValidBytes(res.Body) {
+			return "", fmt.Errorf("the response is not valid JSON, can't get %q", arg)
+		}
+
+		result := gjson.GetBytes(res.Body, arg)
+
+		if !result.Exists() {
+			return "", fmt.Errorf("there's no %q in the response", arg)
+		}
+
+		if result.Type == gjson.String {
+			return result.String(), nil
+		}
+
+		return result.Raw, nil
+	case "header":
+		if len(res.Header.Values(arg)) == 0 {
+			return "", fmt.Errorf("there's no %s header in the response", arg)
+		}
+
+		return res.Header.Get(arg), nil
+	case "regex":
+		re, err := regexp.Compile(arg)
+
+		if err != nil {
+			return "", fmt.Errorf("invalid regex %q: %v", arg, err)
+		}
+
+		match := re.FindSubmatch(res.Body)
+
+		if match == nil {
+			return "", fmt.Errorf("the regex %q doesn't match the response", arg)
+		}
+
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+
+		return string(match[0]), nil
+	case "status":
+		return strconv.Itoa(res.StatusCode), nil
+	}
+
+	return "", fmt.Errorf("unknown capture %q, it must start with json:, header: or regex:, or be status", source)
+}
+
+// ReadState reads the captured values saved by WriteState, a missing file has no values
+func ReadState(path string) (map[string]string, error) {
+	values := map[string]string{}
+
+	data, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return values, nil
+}
+
+// WriteState saves captured values to path, only the owner can read it since they can be tokens
+func WriteState(path string, values map[string]string) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,72 @@
+package capture
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/abdfnx/resto/core/api"
+)
+
+var valueCases = []struct {
+	source   string
+	expected string
+	err      bool
+}{
+	{source: "json:data.access_token", expected: "t0k3n"},
+	{source: "json:data.user.id", expected: "42"},
+	{source: "json:data.user", expected: `{"id":42}`},
+	{source: "json:data.missing", err: true},
+	{source: "header:etag", expected: `W/"abc"`},
+	{source: "header:X-Missing", err: true},
+	{source: `regex:"access_token":"(\w+)"`, expected: "t0k3n"},
+	{source: `regex:t0k\d+`, expected: "t0k3"},
+	{source: "regex:nope", err: true},
+	{source: "regex:(", err: true},
+	{source: "status", expected: "201"},
+	{source: "cookie:session", err: true},
+}
+
+func TestValue(t *testing.T) {
+	res := &api.Response{
+		StatusCode: 201,
+		Header:     http.Header{"Etag": {`W/"abc"`}},
+		Body:       []byte(`{"data":{"access_token":"t0k3n","user":{"id":42}}}`),
+	}
+
+	for _, c := range valueCases {
+		got, err := Value(c.source, res)
+
+		if c.err {
+			if err == nil {
+				t.Errorf("%s :: expected an error, got %q", c.source, got)
+			}
+
+			continue
+		}
+
+		if err != nil || got != c.expected {
+			t.Errorf("%s :: expected %q, got %q (%v)", c.source, c.expected, got, err)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "resto.state.json")
+
+	values, err := ReadState(path)
+
+	if err != nil || len(values) != 0 {
+		t.Fatalf("expected no values for a missing file, got %v (%v)", values, err)
+	}
+
+	if err := WriteState(path, map[string]string{"token": "t0k3n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err = ReadState(path)
+
+	if err != nil || values["token"] != "t0k3n" {
+		t.Errorf("expected the saved values, got %v (%v)", values, err)
+	}
+}
@@ -17,6 +17,10 @@ func Check(buildVersion string) {
 	cmdFactory := factory.New()
 	stderr := cmdFactory.IOStreams.ErrOut
 
+	if !gjson.Get(tools.SettingsContent(), "rs_settings.show_update").Bool() {
+		return
+	}
+
 	latestVersion := api.GetLatest()
 	isFromHomebrewTap := isUnderHomebrew()
 	isFromGo := isUnderGo()
@@ -40,7 +44,7 @@ func Check(buildVersion string) {
 		return ""
 	}
 
-	if buildVersion != latestVersion && gjson.Get(tools.SettingsContent(), "rs_settings.show_update").Bool() != false {
+	if latestVersion != "" && buildVersion != latestVersion {
 		fmt.Fprintf(stderr, "%s %s → %s\n",
 		ansi.Color("There's a new version of ", "yellow") + ansi.Color("resto", "cyan") + ansi.Color(" is avalaible:", "yellow"),
 		ansi.Color(buildVersion, "cyan"),
@@ -0,0 +1,150 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/tools"
+)
+
+const netrc = `
+machine api.example.com
+  login alice
+  password s3cret
+
+macdef init
+  cd /pub
+  bin
+
+machine ftp.example.com login bob password hunter2
+default login anonymous password guest
+`
+
+func TestParseNetrc(t *testing.T) {
+	entries := ParseNetrc(netrc)
+
+	expected := []NetrcEntry{
+		{Machine: "api.example.com", Login: "alice", Password: "s3cret"},
+		{Machine: "ftp.example.com", Login: "bob", Password: "hunter2"},
+		{Machine: "", Login: "anonymous", Password: "guest"},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %+v", len(expected), entries)
+	}
+
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+}
+
+func useTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	hosts := filepath.Join(dir, "hosts.json")
+	netrcFile := filepath.Join(dir, "netrc")
+
+	os.WriteFile(netrcFile, []byte("machine api.example.com login alice password s3cret"), 0600)
+
+	t.Setenv("NETRC", netrcFile)
+	hostsFile = func() string { return hosts }
+
+	t.Cleanup(func() {
+		hostsFile = tools.HostsFile
+	})
+}
+
+// memorySecrets is a SecretStore that keeps the secrets in memory
+type memorySecrets struct {
+	secrets map[string]string
+	saved   bool
+}
+
+func (m *memorySecrets) Set(name, value string) {
+	m.secrets[name] = value
+}
+
+func (m *memorySecrets) Save() error {
+	m.saved = true
+
+	return nil
+}
+
+func TestLookup(t *testing.T) {
+	useTempFiles(t)
+
+	auth, ok := Lookup("https://api.example.com/v1/items")
+
+	if !ok || auth.Type != "basic" || auth.BasicAuthUsername != "alice" || auth.BasicAuthPassword != "s3cret" {
+		t.Errorf("expected the .netrc credentials, got %+v", auth)
+	}
+
+	if err := Add("https://API.example.com/", Host{Type: "bearer", Token: "env:RESTO_TEST_TOKEN"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	auth, ok = Lookup("https://api.example.com/v1/items")
+
+	if !ok || auth.Type != "bearer" || auth.TokenAuth != "env:RESTO_TEST_TOKEN" {
+		t.Errorf("expected the stored credentials to win over .netrc, got %+v", auth)
+	}
+
+	secrets := &memorySecrets{secrets: map[string]string{}}
+
+	if err := Add("localhost:8080", Host{Type: "digest", Username: "admin", Password: "pw"}, secrets); err != nil {
+		t.Fatal(err)
+	}
+
+	if auth, ok := Lookup("http://localhost:8080/status"); !ok || auth.Type != "digest" || auth.BasicAuthPassword != "secret:localhost:8080/password" {
+		t.Errorf("expected the credentials of localhost:8080 with a reference to the password, got %+v", auth)
+	}
+
+	if secrets.secrets["localhost:8080/password"] != "pw" || !secrets.saved {
+		t.Errorf("expected the password to be saved in the secrets, got %+v", secrets)
+	}
+
+	if data, _ := os.ReadFile(hostsFile()); strings.Contains(string(data), `"pw"`) {
+		t.Errorf("expected the password to stay out of hosts.json, got %s", data)
+	}
+
+	if err := Add("localhost:8080", Host{Type: "basic", Username: "admin", Password: "pw"}, nil); err == nil {
+		t.Errorf("expected an error storing a password without a vault")
+	}
+
+	if _, ok := Lookup("http://localhost:9090/status"); ok {
+		t.Errorf("expected no credentials for another port")
+	}
+
+	if err := Remove("api.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Remove("api.example.com"); err == nil {
+		t.Errorf("expected an error removing a missing host")
+	}
+
+	if err := Add("api.example.com", Host{Type: "basic"}, nil); err == nil {
+		t.Errorf("expected an error without a username")
+	}
+}
+
+func TestLookupNetrcDefault(t *testing.T) {
+	useTempFiles(t)
+
+	os.WriteFile(os.Getenv("NETRC"), []byte(netrc), 0600)
+
+	if entry, ok := netrcLookup("api.github.com", false); ok {
+		t.Errorf("expected the default entry to be skipped, got %+v", entry)
+	}
+
+	if entry, ok := netrcLookup("api.github.com", true); !ok || entry.Login != "anonymous" {
+		t.Errorf("expected the default entry when it's opted in, got %+v", entry)
+	}
+
+	if entry, ok := netrcLookup("ftp.example.com", false); !ok || entry.Login != "bob" {
+		t.Errorf("expected the entry of the machine, got %+v", entry)
+	}
+}
@@ -0,0 +1,200 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+)
+
+// Host is the credentials of a host in ~/.resto/hosts.json, the password and the token are
+// `secret:` references to the vault or `env:` references
+type Host struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// hostsFile is where Add and Remove persist the credentials, useTempFiles swaps it out
+var hostsFile = tools.HostsFile
+
+// Hosts returns the stored hosts
+func Hosts() (map[string]Host, error) {
+	hosts := map[string]Host{}
+
+	data, err := ioutil.ReadFile(hostsFile())
+
+	if os.IsNotExist(err) {
+		return hosts, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &hosts); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", hostsFile(), err.Error())
+	}
+
+	return hosts, nil
+}
+
+// HostNames returns the stored hosts sorted
+func HostNames(hosts map[string]Host) []string {
+	names := make([]string, 0, len(hosts))
+
+	for name := range hosts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// SecretStore keeps the passwords and tokens of the hosts, it's the vault outside of tests
+type SecretStore interface {
+	Set(name, value string)
+	Save() error
+}
+
+// SecretName is the name of the secret that keeps field of host, like api.example.com/password
+func SecretName(host, field string) string {
+	return NormalizeHost(host) + "/" + field
+}
+
+// IsReference reports whether value is an `env:` or `secret:` reference, which is stored as it is
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, "env:") || strings.HasPrefix(value, "secret:")
+}
+
+// Add stores the credentials of host, replacing the previous ones. A password or token that isn't
+// a reference is moved to secrets, hosts.json only keeps its `secret:` reference
+func Add(host string, credentials Host, secrets SecretStore) error {
+	switch credentials.Type {
+	case "basic", "digest":
+		if credentials.Username == "" {
+			return fmt.Errorf("a username is required for %s authentication", credentials.Type)
+		}
+	case "bearer":
+		if credentials.Token == "" {
+			return fmt.Errorf("a token is required for bearer authentication")
+		}
+	default:
+		return fmt.Errorf("unknown auth type %q, it must be basic, bearer or digest", credentials.Type)
+	}
+
+	hosts, err := Hosts()
+
+	if err != nil {
+		return err
+	}
+
+	stored := false
+
+	for field, value := range map[string]*string{"password": &credentials.Password, "token": &credentials.Token} {
+		if *value == "" || IsReference(*value) {
+			continue
+		}
+
+		if secrets == nil {
+			return fmt.Errorf("the %s can only be stored in the vault, or given as an env: or secret: reference", field)
+		}
+
+		name := SecretName(host, field)
+		secrets.Set(name, *value)
+		*value = "secret:" + name
+		stored = true
+	}
+
+	if stored {
+		if err := secrets.Save(); err != nil {
+			return err
+		}
+	}
+
+	hosts[NormalizeHost(host)] = credentials
+
+	return save(hosts)
+}
+
+// Remove deletes the credentials of host
+func Remove(host string) error {
+	hosts, err := Hosts()
+
+	if err != nil {
+		return err
+	}
+
+	host = NormalizeHost(host)
+
+	if _, ok := hosts[host]; !ok {
+		return fmt.Errorf("no credentials stored for %s", host)
+	}
+
+	delete(hosts, host)
+
+	return save(hosts)
+}
+
+// NormalizeHost accepts a host, a host:port or a url
+func NormalizeHost(host string) string {
+	if strings.Contains(host, "://") {
+		if u, err := url.Parse(host); err == nil {
+			host = u.Host
+		}
+	}
+
+	return strings.ToLower(strings.TrimSuffix(host, "/"))
+}
+
+// Lookup returns the credentials of the host of rawURL, from hosts.json then .netrc,
+// `env:` and `secret:` references are resolved when the request is built
+func Lookup(rawURL string) (*options.Auth, bool) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+
+	hosts, err := Hosts()
+
+	if err == nil {
+		for _, key := range []string{strings.ToLower(u.Host), strings.ToLower(u.Hostname())} {
+			if host, ok := hosts[key]; ok {
+				return &options.Auth{
+					Type:              host.Type,
+					BasicAuthUsername: host.Username,
+					BasicAuthPassword: host.Password,
+					TokenAuth:         host.Token,
+				}, true
+			}
+		}
+	}
+
+	// the default entry of .netrc is opt-in with the netrc_default setting
+	if entry, ok := netrcLookup(u.Hostname(), tools.Setting("netrc_default").Bool()); ok && entry.Login != "" {
+		return &options.Auth{
+			Type:              "basic",
+			BasicAuthUsername: entry.Login,
+			BasicAuthPassword: entry.Password,
+		}, true
+	}
+
+	return nil, false
+}
+
+func save(hosts map[string]Host) error {
+	data, err := json.MarshalIndent(hosts, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(hostsFile(), data, 0600)
+}
@@ -0,0 +1,109 @@
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// NetrcEntry is a `machine` or `default` entry of a .netrc file
+type NetrcEntry struct {
+	Machine  string
+	Login    string
+	Password string
+}
+
+// NetrcFile is the path of the .netrc file, from `NETRC` or the home directory
+func NetrcFile() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+
+	home, _ := homedir.Dir()
+
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+
+	return filepath.Join(home, ".netrc")
+}
+
+// ParseNetrc reads the entries of a .netrc file, the `default` entry has an empty machine
+func ParseNetrc(data string) []NetrcEntry {
+	entries := []NetrcEntry{}
+	fields := strings.Fields(data)
+
+	var current *NetrcEntry
+
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine", "default":
+			entries = append(entries, NetrcEntry{})
+			current = &entries[len(entries)-1]
+
+			if fields[i] == "machine" && i+1 < len(fields) {
+				i++
+				current.Machine = fields[i]
+			}
+		case "login", "password", "account":
+			if i+1 >= len(fields) {
+				break
+			}
+
+			i++
+
+			if current == nil {
+				continue
+			}
+
+			if fields[i-1] == "login" {
+				current.Login = fields[i]
+			} else if fields[i-1] == "password" {
+				current.Password = fields[i]
+			}
+		case "macdef":
+			// macros run until an empty line, they're skipped up to the next entry
+			current = nil
+
+			for i+1 < len(fields) && fields[i+1] != "machine" && fields[i+1] != "default" {
+				i++
+			}
+		}
+	}
+
+	return entries
+}
+
+// netrcLookup returns the entry of host, the default entry is only used with useDefault,
+// it would send the same credentials to every host
+func netrcLookup(host string, useDefault bool) (NetrcEntry, bool) {
+	data, err := ioutil.ReadFile(NetrcFile())
+
+	if err != nil {
+		return NetrcEntry{}, false
+	}
+
+	var fallback *NetrcEntry
+
+	entries := ParseNetrc(string(data))
+
+	for i, entry := range entries {
+		if entry.Machine == host {
+			return entry, true
+		}
+
+		if entry.Machine == "" && fallback == nil {
+			fallback = &entries[i]
+		}
+	}
+
+	if fallback != nil && useDefault {
+		return *fallback, true
+	}
+
+	return NetrcEntry{}, false
+}
@@ -0,0 +1,384 @@
+package curl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// Header is a single request header, kept in the order it was given
+type Header struct {
+	Key   string
+	Value string
+}
+
+// FormField is a multipart field passed with -F, File is set for `name=@path` fields
+type FormField struct {
+	Name  string
+	Value string
+	File  string
+}
+
+// Request is a curl command line parsed into its request parts
+type Request struct {
+	Method     string
+	URL        string
+	Headers    []Header
+	Body       string
+	Username   string
+	Password   string
+	Form       []FormField
+	Compressed bool
+	Insecure   bool
+}
+
+type option struct {
+	takesValue bool
+	apply      func(p *parser, value string) error
+}
+
+type parser struct {
+	req     *Request
+	data    []string
+	useGet  bool
+	isHead  bool
+	hasUser bool
+}
+
+var options = map[string]option{
+	"-X":               {true, func(p *parser, v string) error { p.req.Method = strings.ToUpper(v); return nil }},
+	"--request":        {true, func(p *parser, v string) error { p.req.Method = strings.ToUpper(v); return nil }},
+	"--url":            {true, func(p *parser, v string) error { p.req.URL = v; return nil }},
+	"-H":               {true, (*parser).header},
+	"--header":         {true, (*parser).header},
+	"-A":               {true, func(p *parser, v string) error { return p.header("User-Agent: " + v) }},
+	"--user-agent":     {true, func(p *parser, v string) error { return p.header("User-Agent: " + v) }},
+	"-e":               {true, func(p *parser, v string) error { return p.header("Referer: " + v) }},
+	"--referer":        {true, func(p *parser, v string) error { return p.header("Referer: " + v) }},
+	"-b":               {true, func(p *parser, v string) error { return p.header("Cookie: " + v) }},
+	"--cookie":         {true, func(p *parser, v string) error { return p.header("Cookie: " + v) }},
+	"-d":               {true, func(p *parser, v string) error { return p.addData(v, true, true) }},
+	"--data":           {true, func(p *parser, v string) error { return p.addData(v, true, true) }},
+	"--data-ascii":     {true, func(p *parser, v string) error { return p.addData(v, true, true) }},
+	"--data-binary":    {true, func(p *parser, v string) error { return p.addData(v, true, false) }},
+	"--data-raw":       {true, func(p *parser, v string) error { return p.addData(v, false, false) }},
+	"--data-urlencode": {true, (*parser).addURLEncoded},
+	"-F":               {true, (*parser).form},
+	"--form":           {true, (*parser).form},
+	"-u":               {true, (*parser).user},
+	"--user":           {true, (*parser).user},
+	"-G":               {false, func(p *parser, v string) error { p.useGet = true; return nil }},
+	"--get":            {false, func(p *parser, v string) error { p.useGet = true; return nil }},
+	"-I":               {false, func(p *parser, v string) error { p.isHead = true; return nil }},
+	"--head":           {false, func(p *parser, v string) error { p.isHead = true; return nil }},
+	"--compressed":     {false, func(p *parser, v string) error { p.req.Compressed = true; return nil }},
+	"-k":               {false, func(p *parser, v string) error { p.req.Insecure = true; return nil }},
+	"--insecure":       {false, func(p *parser, v string) error { p.req.Insecure = true; return nil }},
+
+	// options that only change how curl itself behaves
+	"-s":                {false, nil},
+	"--silent":          {false, nil},
+	"-S":                {false, nil},
+	"--show-error":      {false, nil},
+	"-L":                {false, nil},
+	"--location":        {false, nil},
+	"-v":                {false, nil},
+	"--verbose":         {false, nil},
+	"-i":                {false, nil},
+	"--include":         {false, nil},
+	"-f":                {false, nil},
+	"--fail":            {false, nil},
+	"-g":                {false, nil},
+	"--globoff":         {false, nil},
+	"--http1.1":         {false, nil},
+	"--http2":           {false, nil},
+	"-o":                {true, nil},
+	"--output":          {true, nil},
+	"-m":                {true, nil},
+	"--max-time":        {true, nil},
+	"--connect-timeout": {true, nil},
+	"-w":                {true, nil},
+	"--write-out":       {true, nil},
+	"--retry":           {true, nil},
+}
+
+// Parse parses a curl command line, as copied from browser devtools or API docs
+func Parse(command string) (*Request, error) {
+	// join lines continued with a trailing backslash
+	command = strings.NewReplacer("\\\r\n", " ", "\\\n", " ").Replace(command)
+
+	args, err := shlex.Split(command)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not split the curl command: %s", err.Error())
+	}
+
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, fmt.Errorf("not a curl command, it must start with `curl`")
+	}
+
+	p := &parser{req: &Request{}}
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if p.req.URL != "" {
+				return nil, fmt.Errorf("unexpected argument %q, the url is already set to %q", arg, p.req.URL)
+			}
+
+			p.req.URL = arg
+			continue
+		}
+
+		if !strings.HasPrefix(arg, "--") {
+			used, err := p.shortOptions(arg[1:], args[i+1:])
+
+			if err != nil {
+				return nil, err
+			}
+
+			i += used
+			continue
+		}
+
+		name, value, hasValue := arg, "", false
+
+		if eq := strings.Index(arg, "="); eq != -1 {
+			name, value, hasValue = arg[:eq], arg[eq+1:], true
+		}
+
+		opt, ok := options[name]
+
+		if !ok {
+			return nil, fmt.Errorf("unsupported curl option %q", name)
+		}
+
+		if opt.takesValue && !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("curl option %q needs a value", name)
+			}
+
+			i++
+			value = args[i]
+		}
+
+		if err := p.apply(opt, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.finish()
+}
+
+// shortOptions applies a group of short options like -sSL, the first one that takes a value takes the rest of the group,
+// like the POST of -sXPOST, or the next argument. It returns how many of the next arguments it used
+func (p *parser) shortOptions(group string, next []string) (int, error) {
+	for i, c := range group {
+		name := "-" + string(c)
+		opt, ok := options[name]
+
+		if !ok {
+			return 0, fmt.Errorf("unsupported curl option %q", name)
+		}
+
+		if !opt.takesValue {
+			if err := p.apply(opt, ""); err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		if value := group[i+len(string(c)):]; value != "" {
+			return 0, p.apply(opt, value)
+		}
+
+		if len(next) == 0 {
+			return 0, fmt.Errorf("curl option %q needs a value", name)
+		}
+
+		return 1, p.apply(opt, next[0])
+	}
+
+	return 0, nil
+}
+
+func (p *parser) apply(opt option, value string) error {
+	if opt.apply == nil {
+		return nil
+	}
+
+	return opt.apply(p, value)
+}
+
+func (p *parser) finish() (*Request, error) {
+	req := p.req
+
+	if req.URL == "" {
+		return nil, fmt.Errorf("the curl command has no url")
+	}
+
+	if !strings.Contains(req.URL, "://") {
+		req.URL = "http://" + req.URL
+	}
+
+	if len(p.data) > 0 && len(req.Form) > 0 {
+		return nil, fmt.Errorf("-d and -F can't be used together")
+	}
+
+	body := strings.Join(p.data, "&")
+
+	if p.useGet {
+		if body != "" {
+			sep := "?"
+
+			if strings.Contains(req.URL, "?") {
+				sep = "&"
+			}
+
+			req.URL += sep + body
+		}
+
+		body = ""
+
+		if req.Method == "" {
+			req.Method = "GET"
+		}
+	}
+
+	req.Body = body
+
+	if req.Method == "" {
+		if p.isHead {
+			req.Method = "HEAD"
+		} else if req.Body != "" || len(req.Form) > 0 {
+			req.Method = "POST"
+		} else {
+			req.Method = "GET"
+		}
+	}
+
+	if req.Body != "" && req.Header("Content-Type") == "" {
+		req.Headers = append(req.Headers, Header{Key: "Content-Type", Value: "application/x-www-form-urlencoded"})
+	}
+
+	return req, nil
+}
+
+func (p *parser) header(v string) error {
+	colon := strings.Index(v, ":")
+
+	if colon == -1 {
+		// `-H "X-Foo;"` sends an empty header, `-H "X-Foo"` alone removes one; both mean no value here
+		key := strings.TrimSuffix(strings.TrimSpace(v), ";")
+		p.req.Headers = append(p.req.Headers, Header{Key: key})
+
+		return nil
+	}
+
+	p.req.Headers = append(p.req.Headers, Header{
+		Key:   strings.TrimSpace(v[:colon]),
+		Value: strings.TrimSpace(v[colon+1:]),
+	})
+
+	return nil
+}
+
+// addData handles the -d family, readFile allows `@file` and stripNewlines mimics -d reading files in text mode
+func (p *parser) addData(v string, readFile, stripNewlines bool) error {
+	if readFile && strings.HasPrefix(v, "@") {
+		data, err := ioutil.ReadFile(v[1:])
+
+		if err != nil {
+			return err
+		}
+
+		v = string(data)
+
+		if stripNewlines {
+			v = strings.NewReplacer("\r", "", "\n", "").Replace(v)
+		}
+	}
+
+	p.data = append(p.data, v)
+
+	return nil
+}
+
+func (p *parser) addURLEncoded(v string) error {
+	if eq := strings.Index(v, "="); eq != -1 {
+		p.data = append(p.data, v[:eq+1]+url.QueryEscape(v[eq+1:]))
+	} else {
+		p.data = append(p.data, url.QueryEscape(v))
+	}
+
+	return nil
+}
+
+func (p *parser) form(v string) error {
+	eq := strings.Index(v, "=")
+
+	if eq == -1 {
+		return fmt.Errorf("invalid -F value %q, expected name=value", v)
+	}
+
+	field := FormField{Name: v[:eq]}
+	value := v[eq+1:]
+
+	if strings.HasPrefix(value, "@") {
+		field.File = strings.Split(value[1:], ";")[0]
+	} else {
+		field.Value = value
+	}
+
+	p.req.Form = append(p.req.Form, field)
+
+	return nil
+}
+
+func (p *parser) user(v string) error {
+	if colon := strings.Index(v, ":"); colon != -1 {
+		p.req.Username, p.req.Password = v[:colon], v[colon+1:]
+	} else {
+		p.req.Username = v
+	}
+
+	return nil
+}
+
+// Header returns the value of the last header named key, case insensitive
+func (r *Request) Header(key string) string {
+	value := ""
+
+	for _, h := range r.Headers {
+		if strings.EqualFold(h.Key, key) {
+			value = h.Value
+		}
+	}
+
+	return value
+}
+
+// ContentType returns the media type of the body without parameters like charset
+func (r *Request) ContentType() string {
+	if len(r.Form) > 0 {
+		return "multipart/form-data"
+	}
+
+	return strings.TrimSpace(strings.Split(r.Header("Content-Type"), ";")[0])
+}
+
+// BearerToken returns the token of an `Authorization: Bearer` header, if any
+func (r *Request) BearerToken() string {
+	auth := r.Header("Authorization")
+
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+
+	return ""
+}
@@ -0,0 +1,131 @@
+package curl
+
+import (
+	"reflect"
+	"testing"
+)
+
+var parseCases = []struct {
+	name     string
+	command  string
+	expected *Request
+}{
+	{
+		name:    "plain get",
+		command: "curl https://api.github.com",
+		expected: &Request{
+			Method: "GET",
+			URL:    "https://api.github.com",
+		},
+	},
+	{
+		name:    "post with json body",
+		command: `curl -X POST 'https://api.xcode.codes/users' -H 'Content-Type: application/json' --data-raw '{"name":"resto"}'`,
+		expected: &Request{
+			Method:  "POST",
+			URL:     "https://api.xcode.codes/users",
+			Headers: []Header{{Key: "Content-Type", Value: "application/json"}},
+			Body:    `{"name":"resto"}`,
+		},
+	},
+	{
+		name:    "data implies post and form encoding",
+		command: "curl https://url.io -d a=1 -d b=2",
+		expected: &Request{
+			Method:  "POST",
+			URL:     "https://url.io",
+			Headers: []Header{{Key: "Content-Type", Value: "application/x-www-form-urlencoded"}},
+			Body:    "a=1&b=2",
+		},
+	},
+	{
+		name:    "devtools style with continuations",
+		command: "curl 'https://url.io/api' \\\n  -H 'accept: */*' \\\n  -H 'authorization: Bearer abc' \\\n  --compressed",
+		expected: &Request{
+			Method:     "GET",
+			URL:        "https://url.io/api",
+			Headers:    []Header{{Key: "accept", Value: "*/*"}, {Key: "authorization", Value: "Bearer abc"}},
+			Compressed: true,
+		},
+	},
+	{
+		name:    "grouped short options and basic auth",
+		command: "curl -sSLk -XPUT -u user:p@ss https://url.io",
+		expected: &Request{
+			Method:   "PUT",
+			URL:      "https://url.io",
+			Username: "user",
+			Password: "p@ss",
+			Insecure: true,
+		},
+	},
+	{
+		name:    "a group ends with the option that takes a value",
+		command: "curl -sXPOST https://url.io -kLH 'X-Tenant: acme' -sd a=1",
+		expected: &Request{
+			Method:   "POST",
+			URL:      "https://url.io",
+			Headers:  []Header{{Key: "X-Tenant", Value: "acme"}, {Key: "Content-Type", Value: "application/x-www-form-urlencoded"}},
+			Body:     "a=1",
+			Insecure: true,
+		},
+	},
+	{
+		name:    "multipart form",
+		command: "curl -F name=resto -F file=@logo.png https://url.io/upload",
+		expected: &Request{
+			Method: "POST",
+			URL:    "https://url.io/upload",
+			Form:   []FormField{{Name: "name", Value: "resto"}, {Name: "file", File: "logo.png"}},
+		},
+	},
+	{
+		name:    "get with data goes to the query",
+		command: "curl -G https://url.io/search?x=1 --data-urlencode 'q=a b'",
+		expected: &Request{
+			Method: "GET",
+			URL:    "https://url.io/search?x=1&q=a+b",
+		},
+	},
+	{
+		name:    "long options with equals",
+		command: "curl --request=DELETE --url=https://url.io/1 --header='X-Tenant: acme'",
+		expected: &Request{
+			Method:  "DELETE",
+			URL:     "https://url.io/1",
+			Headers: []Header{{Key: "X-Tenant", Value: "acme"}},
+		},
+	},
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range parseCases {
+		out, err := Parse(tt.command)
+
+		if err != nil {
+			t.Errorf("%s :: %s", tt.name, err.Error())
+			continue
+		}
+
+		if !reflect.DeepEqual(out, tt.expected) {
+			t.Errorf("%s :: got %+v, expected %+v", tt.name, out, tt.expected)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, command := range []string{
+		"",
+		"wget https://url.io",
+		"curl",
+		"curl https://url.io --proxy-magic",
+		"curl https://url.io -H",
+		"curl https://url.io -sX",
+		"curl https://url.io -sZ",
+		"curl https://url.io -d a=1 -F b=2",
+	} {
+		if _, err := Parse(command); err == nil {
+			t.Errorf("expected an error for %q", command)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package curl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HTTPRequest converts the parsed command into the request curl would have sent
+func (r *Request) HTTPRequest() (*http.Request, error) {
+	var body bytes.Buffer
+	contentType := ""
+
+	if len(r.Form) > 0 {
+		writer := multipart.NewWriter(&body)
+
+		for _, field := range r.Form {
+			if field.File == "" {
+				if err := writer.WriteField(field.Name, field.Value); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			if err := writeFile(writer, field); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		contentType = writer.FormDataContentType()
+	} else {
+		body.WriteString(r.Body)
+	}
+
+	req, err := http.NewRequest(r.Method, r.URL, &body)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error creating request: %s", err.Error())
+	}
+
+	for _, h := range r.Headers {
+		// with --compressed, the go transport asks for gzip and decompresses the response itself
+		if r.Compressed && strings.EqualFold(h.Key, "Accept-Encoding") {
+			continue
+		}
+
+		req.Header.Add(h.Key, h.Value)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if r.Username != "" {
+		req.SetBasicAuth(r.Username, r.Password)
+	}
+
+	return req, nil
+}
+
+func writeFile(writer *multipart.Writer, field FormField) error {
+	file, err := os.Open(field.File)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	part, err := writer.CreateFormFile(field.Name, filepath.Base(field.File))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(part, file)
+
+	return err
+}
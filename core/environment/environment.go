@@ -0,0 +1,184 @@
+package environment
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/abdfnx/resto/tools"
+)
+
+// FileName is the environment file next to a Restofile, or in the working directory for resto UI
+const FileName = "resto.env.json"
+
+// Environment is a named set of variables, like the base url of the staging servers
+type Environment struct {
+	Name string
+	Vars map[string]string
+}
+
+// Environments maps the names of the environments of a file to their variables
+type Environments map[string]map[string]string
+
+var varPattern = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*}}`)
+
+// ReadFile reads an environment file, a JSON object of environment names to objects of variables:
+//
+//	{
+//	  "local": { "baseUrl": "http://localhost:8080" },
+//	  "staging": { "baseUrl": "https://staging.example.com", "token": "env:STAGING_TOKEN" }
+//	}
+func ReadFile(path string) (Environments, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]map[string]interface{}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v, it must be an object of environments to objects of variables", path, err)
+	}
+
+	envs := Environments{}
+
+	for name, vars := range raw {
+		envs[name] = map[string]string{}
+
+		for key, value := range vars {
+			switch value := value.(type) {
+			case string:
+				envs[name][key] = value
+			case float64, bool:
+				envs[name][key] = fmt.Sprint(value)
+			default:
+				return nil, fmt.Errorf("%s: the %s variable of %s must be a string, a number or a boolean", path, key, name)
+			}
+		}
+	}
+
+	return envs, nil
+}
+
+// Names returns the sorted names of the environments
+func (e Environments) Names() []string {
+	var names []string
+
+	for name := range e {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Load returns the environment called name of the environment file at path
+func Load(path, name string) (*Environment, error) {
+	envs, err := ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vars, ok := envs[name]
+
+	if !ok {
+		return nil, fmt.Errorf("there's no %s environment in %s, it must be one of %s", name, path, strings.Join(envs.Names(), ", "))
+	}
+
+	return &Environment{Name: name, Vars: vars}, nil
+}
+
+// UndefinedError is returned by Interpolate for a variable that has no value
+type UndefinedError struct {
+	Name string
+}
+
+func (e *UndefinedError) Error() string {
+	return fmt.Sprintf("undefined variable %q", e.Name)
+}
+
+// Interpolate replaces the {{name}} variables of s with their values. A value that is the whole of s is kept as it is,
+// so `env:` and `secret:` references are resolved when they're used, in the middle of s they're resolved right away
+func Interpolate(s string, vars map[string]string) (string, error) {
+	return InterpolateLiterals(s, vars, nil)
+}
+
+// InterpolateLiterals is Interpolate with literals, the values that come from outside the Restofile like the captured ones.
+// They take precedence over vars and are used as they are, their `env:` and `secret:` references are never resolved
+func InterpolateLiterals(s string, vars, literals map[string]string) (string, error) {
+	if match := varPattern.FindStringSubmatch(s); match != nil && match[0] == s {
+		if value, ok := literals[match[1]]; ok {
+			return value, nil
+		}
+
+		value, ok := vars[match[1]]
+
+		if !ok {
+			return s, &UndefinedError{Name: match[1]}
+		}
+
+		return value, nil
+	}
+
+	var err error
+
+	out := varPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := varPattern.FindStringSubmatch(ref)[1]
+
+		if value, ok := literals[name]; ok {
+			return value
+		}
+
+		value, ok := vars[name]
+
+		if !ok {
+			if err == nil {
+				err = &UndefinedError{Name: name}
+			}
+
+			return ref
+		}
+
+		resolved, resolveErr := tools.ResolveValue(value)
+
+		if resolveErr != nil && err == nil {
+			err = resolveErr
+		}
+
+		return resolved
+	})
+
+	return out, err
+}
+
+// Expand is Interpolate for values that are used right away, the `env:` and `secret:` references of whole values are resolved too
+func Expand(s string, vars map[string]string) (string, error) {
+	out, err := Interpolate(s, vars)
+
+	if err != nil || out == s {
+		return out, err
+	}
+
+	if match := varPattern.FindString(s); match == s {
+		return tools.ResolveValue(out)
+	}
+
+	return out, nil
+}
+
+// References returns the names of the variables used in s
+func References(s string) []string {
+	var names []string
+
+	for _, match := range varPattern.FindAllStringSubmatch(s, -1) {
+		names = append(names, match[1])
+	}
+
+	return names
+}
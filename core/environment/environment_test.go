@@ -0,0 +1,78 @@
+package environment
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var interpolateCases = []struct {
+	input    string
+	expected string
+	err      string
+}{
+	{input: "{{baseUrl}}/users", expected: "https://staging.example.com/users"},
+	{input: "{{ baseUrl }}/users?tenant={{tenant}}", expected: "https://staging.example.com/users?tenant=acme"},
+	{input: "Bearer {{token}}", expected: "Bearer from-env"},
+	{input: "{{token}}", expected: "env:RESTO_TEST_TOKEN"},
+	{input: "no variables {here}", expected: "no variables {here}"},
+	{input: "{{baseUrl}}/{{version}}", expected: "https://staging.example.com/{{version}}", err: `undefined variable "version"`},
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("RESTO_TEST_TOKEN", "from-env")
+
+	vars := map[string]string{
+		"baseUrl": "https://staging.example.com",
+		"tenant":  "acme",
+		"token":   "env:RESTO_TEST_TOKEN",
+	}
+
+	for _, c := range interpolateCases {
+		got, err := Interpolate(c.input, vars)
+
+		if got != c.expected {
+			t.Errorf("%s :: expected %q, got %q", c.input, c.expected, got)
+		}
+
+		if c.err == "" && err != nil {
+			t.Errorf("%s :: unexpected error %v", c.input, err)
+		}
+
+		if c.err != "" {
+			if _, ok := err.(*UndefinedError); !ok || err.Error() != c.err {
+				t.Errorf("%s :: expected the error %q, got %v", c.input, c.err, err)
+			}
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	ioutil.WriteFile(path, []byte(`{
+		"local": { "baseUrl": "http://localhost:8080", "port": 8080, "debug": true },
+		"staging": { "baseUrl": "https://staging.example.com" }
+	}`), 0644)
+
+	env, err := Load(path, "local")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if env.Vars["baseUrl"] != "http://localhost:8080" || env.Vars["port"] != "8080" || env.Vars["debug"] != "true" {
+		t.Errorf("unexpected variables %v", env.Vars)
+	}
+
+	if _, err := Load(path, "prod"); err == nil || !strings.Contains(err.Error(), "it must be one of local, staging") {
+		t.Errorf("expected an unknown environment error, got %v", err)
+	}
+
+	ioutil.WriteFile(path, []byte(`{ "local": { "servers": ["a", "b"] } }`), 0644)
+
+	if _, err := Load(path, "local"); err == nil {
+		t.Errorf("expected an error for a list variable")
+	}
+}
@@ -0,0 +1,409 @@
+package expect
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/core/restofile"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/pretty"
+)
+
+// Assertion is an entry of an expect block:
+//
+//	status 200                                   the status code, or one of the codes, "2xx" matches a class
+//	time 500ms                                   the response time is under a duration
+//	header "Content-Type" contains "json"        a header equals, contains, matches a regex or exists
+//	json "data.id" exists                        a gjson path equals, contains, matches a regex, exists or has a type
+//	body contains "ok"                           the body equals, contains or matches a regex
+type Assertion struct {
+	Pos     restofile.Pos
+	Subject string
+	Target  string
+	Op      string
+	Values  []string
+}
+
+// Result is the result of an assertion, Expected and Actual are set for the failures
+type Result struct {
+	Assertion Assertion
+	Passed    bool
+	Expected  string
+	Actual    string
+
+	// Message explains the failures that have no actual value, like a missing header
+	Message string
+}
+
+var (
+	subjects = []string{"status", "time", "header", "json", "body"}
+	types    = []string{"string", "number", "boolean", "object", "array", "null"}
+
+	statusPattern = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+)
+
+// Parse returns the assertions of an expect block, interpolate replaces the variables of the values, it can be nil
+func Parse(block *restofile.Block, interpolate func(pos restofile.Pos, s string) string) ([]Assertion, error) {
+	var assertions []Assertion
+
+	if nested := block.Blocks(""); len(nested) > 0 {
+		return nil, restofile.Errorf(nested[0].Pos, "the expect block can't hold a %s block", nested[0].Name)
+	}
+
+	for _, entry := range block.Entries() {
+		var values []string
+
+		for _, value := range entry.Values {
+			if interpolate != nil && !value.Raw {
+				values = append(values, interpolate(value.Pos, value.Text))
+			} else {
+				values = append(values, value.Text)
+			}
+		}
+
+		a := Assertion{Pos: entry.Pos, Subject: entry.Key}
+
+		switch entry.Key {
+		case "status":
+			for _, value := range values {
+				if !statusPattern.MatchString(strings.ToLower(value)) {
+					return nil, restofile.Errorf(entry.Pos, "invalid status %q, it must be a code like 200 or a class like 2xx", value)
+				}
+			}
+
+			a.Op, a.Values = "equals", values
+		case "time":
+			if len(values) != 1 {
+				return nil, restofile.Errorf(entry.Pos, "time takes a duration, like time 500ms")
+			}
+
+			if _, err := time.ParseDuration(values[0]); err != nil {
+				return nil, restofile.Errorf(entry.Values[0].Pos, "invalid duration %q, it must be like 500ms or 2s", values[0])
+			}
+
+			a.Op, a.Values = "under", values
+		case "header", "json":
+			if len(values) < 2 {
+				return nil, restofile.Errorf(entry.Pos, "%s takes a %s and an assertion, like %s", entry.Key, target(entry.Key), example(entry.Key))
+			}
+
+			a.Target, a.Op, a.Values = values[0], values[1], values[2:]
+
+			if err := checkOp(entry, a); err != nil {
+				return nil, err
+			}
+		case "body":
+			if len(values) < 1 {
+				return nil, restofile.Errorf(entry.Pos, "body takes an assertion, like %s", example("body"))
+			}
+
+			a.Op, a.Values = values[0], values[1:]
+
+			if err := checkOp(entry, a); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, restofile.Errorf(entry.Pos, "unknown assertion %q, it must be %s", entry.Key, strings.Join(subjects, ", "))
+		}
+
+		assertions = append(assertions, a)
+	}
+
+	return assertions, nil
+}
+
+func checkOp(entry *restofile.Entry, a Assertion) error {
+	ops := []string{"equals", "contains", "matches", "exists"}
+
+	if a.Subject == "json" {
+		ops = append(ops, "type")
+	} else if a.Subject == "body" {
+		ops = ops[:3]
+	}
+
+	valid := false
+
+	for _, op := range ops {
+		valid = valid || op == a.Op
+	}
+
+	if !valid {
+		return restofile.Errorf(entry.Pos, "unknown %s assertion %q, it must be %s", a.Subject, a.Op, strings.Join(ops, ", "))
+	}
+
+	if a.Op == "exists" {
+		if len(a.Values) != 0 {
+			return restofile.Errorf(entry.Pos, "exists doesn't take a value")
+		}
+
+		return nil
+	}
+
+	if len(a.Values) != 1 {
+		return restofile.Errorf(entry.Pos, "%s takes a single value, like %s", a.Op, example(a.Subject))
+	}
+
+	if a.Op == "matches" {
+		if _, err := regexp.Compile(a.Values[0]); err != nil {
+			return restofile.Errorf(entry.Pos, "invalid regex %q: %v", a.Values[0], err)
+		}
+	}
+
+	if a.Op == "type" && !contains(types, a.Values[0]) {
+		return restofile.Errorf(entry.Pos, "unknown type %q, it must be %s", a.Values[0], strings.Join(types, ", "))
+	}
+
+	return nil
+}
+
+func target(subject string) string {
+	if subject == "header" {
+		return "header name"
+	}
+
+	return "gjson path"
+}
+
+func example(subject string) string {
+	switch subject {
+	case "header":
+		return `header "Content-Type" contains "json"`
+	case "json":
+		return `json "data.id" exists`
+	}
+
+	return `body contains "ok"`
+}
+
+// String returns the assertion like it's written in an expect block
+func (a Assertion) String() string {
+	parts := []string{a.Subject}
+
+	switch a.Subject {
+	case "status", "time":
+		return a.Subject + " " + strings.Join(a.Values, " ")
+	case "header", "json":
+		parts = append(parts, strconv.Quote(a.Target))
+	}
+
+	parts = append(parts, a.Op)
+
+	for _, value := range a.Values {
+		parts = append(parts, strconv.Quote(value))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Check runs the assertion on a response
+func (a Assertion) Check(res *api.Response) Result {
+	switch a.Subject {
+	case "status":
+		actual := strconv.Itoa(res.StatusCode)
+
+		for _, value := range a.Values {
+			value = strings.ToLower(value)
+
+			if value == actual || strings.HasSuffix(value, "xx") && value[0] == actual[0] {
+				return a.pass()
+			}
+		}
+
+		return a.fail(strings.Join(a.Values, " or "), actual)
+	case "time":
+		limit, _ := time.ParseDuration(a.Values[0])
+
+		if res.Duration <= limit {
+			return a.pass()
+		}
+
+		return a.fail("under "+limit.String(), res.Duration.Round(time.Millisecond).String())
+	case "header":
+		values := res.Header.Values(a.Target)
+
+		if len(values) == 0 {
+			return a.missing(fmt.Sprintf("there's no %s header in the response", a.Target))
+		}
+
+		return a.compare(strings.Join(values, ", "), nil)
+	case "json":
+		if !gjson.ValidBytes(res.Body) {
+			return a.missing("the response is not valid JSON")
+		}
+
+		result := gjson.GetBytes(res.Body, a.Target)
+
+		if !result.Exists() {
+			return a.missing(fmt.Sprintf("there's no %q in the response", a.Target))
+		}
+
+		if a.Op == "type" {
+			if actual := typeOf(result); actual != a.Values[0] {
+				return a.fail(a.Values[0], actual)
+			}
+
+			return a.pass()
+		}
+
+		return a.compare(jsonText(result), &result)
+	}
+
+	return a.compare(string(res.Body), nil)
+}
+
+// compare checks the equals, contains, matches and exists assertions, result is set for json values
+func (a Assertion) compare(actual string, result *gjson.Result) Result {
+	if a.Op == "exists" {
+		return a.pass()
+	}
+
+	expected := a.Values[0]
+
+	switch a.Op {
+	case "equals":
+		if result != nil && result.Type == gjson.JSON && gjson.Valid(expected) {
+			// objects and arrays are compared without their formatting
+			if string(pretty.Ugly([]byte(expected))) == string(pretty.Ugly([]byte(result.Raw))) {
+				return a.pass()
+			}
+
+			return a.fail(indentJSON(expected), indentJSON(result.Raw))
+		}
+
+		if actual == expected {
+			return a.pass()
+		}
+	case "contains":
+		if result != nil && result.IsArray() {
+			for _, item := range result.Array() {
+				if jsonText(item) == expected || string(pretty.Ugly([]byte(item.Raw))) == string(pretty.Ugly([]byte(expected))) {
+					return a.pass()
+				}
+			}
+		} else if strings.Contains(actual, expected) {
+			return a.pass()
+		}
+	case "matches":
+		if regexp.MustCompile(expected).MatchString(actual) {
+			return a.pass()
+		}
+	}
+
+	if result != nil && result.Type == gjson.JSON {
+		actual = indentJSON(result.Raw)
+	}
+
+	return a.fail(expected, actual)
+}
+
+func (a Assertion) pass() Result {
+	return Result{Assertion: a, Passed: true}
+}
+
+func (a Assertion) fail(expected, actual string) Result {
+	return Result{Assertion: a, Expected: expected, Actual: actual}
+}
+
+func (a Assertion) missing(message string) Result {
+	return Result{Assertion: a, Message: message}
+}
+
+func indentJSON(s string) string {
+	return strings.TrimSuffix(string(pretty.Pretty([]byte(s))), "\n")
+}
+
+// jsonText returns strings without quotes, and the raw json of the other values
+func jsonText(result gjson.Result) string {
+	if result.Type == gjson.String {
+		return result.String()
+	}
+
+	return result.Raw
+}
+
+func typeOf(result gjson.Result) string {
+	switch result.Type {
+	case gjson.String:
+		return "string"
+	case gjson.Number:
+		return "number"
+	case gjson.True, gjson.False:
+		return "boolean"
+	case gjson.Null:
+		return "null"
+	}
+
+	if result.IsArray() {
+		return "array"
+	}
+
+	return "object"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Diff returns the lines of expected and actual, the removed ones start with "- " and the added ones with "+ "
+func Diff(expected, actual string) []string {
+	a := strings.Split(strings.TrimRight(expected, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(actual, "\n"), "\n")
+
+	// lengths of the longest common subsequences of the suffixes
+	lcs := make([][]int, len(a)+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			lines = append(lines, "- "+a[i])
+			i++
+		} else {
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		lines = append(lines, "- "+a[i])
+	}
+
+	for ; j < len(b); j++ {
+		lines = append(lines, "+ "+b[j])
+	}
+
+	return lines
+}
@@ -0,0 +1,175 @@
+package expect
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/core/restofile"
+)
+
+func parse(t *testing.T, src string) ([]Assertion, error) {
+	t.Helper()
+
+	file, err := restofile.Parse("Restofile", []byte(src))
+
+	if err != nil {
+		t.Fatalf("parse %q: %v", src, err)
+	}
+
+	return Parse(file.Block("expect"), nil)
+}
+
+var checkCases = []struct {
+	src    string
+	passed bool
+}{
+	{src: `status 200`, passed: true},
+	{src: `status 201 200`, passed: true},
+	{src: `status 2xx`, passed: true},
+	{src: `status 404`},
+	{src: `status 4xx`},
+	{src: `time 1s`, passed: true},
+	{src: `time 10ms`},
+	{src: `header "content-type" contains "json"`, passed: true},
+	{src: `header "Content-Type" equals "application/json"`, passed: true},
+	{src: `header "Content-Type" matches "^application/"`, passed: true},
+	{src: `header "ETag" exists`},
+	{src: `json "data.id" equals "42"`, passed: true},
+	{src: `json "data.name" equals "resto"`, passed: true},
+	{src: `json "data.name" equals "\"resto\""`},
+	{src: `json "data.tags" equals "[ \"cli\", \"http\" ]"`, passed: true},
+	{src: `json "data.tags" contains "http"`, passed: true},
+	{src: `json "data.tags" contains "tui"`},
+	{src: `json "data.name" contains "est"`, passed: true},
+	{src: `json "data.name" matches "^r"`, passed: true},
+	{src: `json "data.missing" exists`},
+	{src: `json "data" type "object"`, passed: true},
+	{src: `json "data.tags" type "array"`, passed: true},
+	{src: `json "data.id" type "number"`, passed: true},
+	{src: `json "data.ok" type "boolean"`, passed: true},
+	{src: `json "data.none" type "null"`, passed: true},
+	{src: `json "data.id" type "string"`},
+	{src: `body contains "resto"`, passed: true},
+	{src: `body matches "\"id\":\\s*42"`, passed: true},
+	{src: `body equals "ok"`},
+}
+
+func TestCheck(t *testing.T) {
+	res := &api.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"data":{"id":42,"name":"resto","tags":["cli","http"],"ok":true,"none":null}}`),
+		Duration:   50 * time.Millisecond,
+	}
+
+	for _, c := range checkCases {
+		assertions, err := parse(t, "expect {\n"+c.src+"\n}")
+
+		if err != nil || len(assertions) != 1 {
+			t.Errorf("%s :: expected an assertion, got %v (%v)", c.src, assertions, err)
+
+			continue
+		}
+
+		if result := assertions[0].Check(res); result.Passed != c.passed {
+			t.Errorf("%s :: expected passed to be %v, got %+v", c.src, c.passed, result)
+		}
+	}
+}
+
+func TestCheckFailure(t *testing.T) {
+	res := &api.Response{StatusCode: 500, Header: http.Header{}, Body: []byte(`not json`)}
+
+	assertions, err := parse(t, "expect {\n  status 2xx\n  json \"id\" exists\n  header \"ETag\" exists\n}")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := assertions[0].Check(res)
+
+	if status.Expected != "2xx" || status.Actual != "500" {
+		t.Errorf("expected 2xx and 500, got %q and %q", status.Expected, status.Actual)
+	}
+
+	if result := assertions[1].Check(res); result.Message != "the response is not valid JSON" {
+		t.Errorf("expected a message about invalid JSON, got %q", result.Message)
+	}
+
+	if result := assertions[2].Check(res); !strings.Contains(result.Message, "ETag") {
+		t.Errorf("expected a message about the missing header, got %q", result.Message)
+	}
+
+	if assertions[2].Pos.Line != 4 {
+		t.Errorf("expected the assertion on line 4, got %v", assertions[2].Pos)
+	}
+}
+
+var parseErrorCases = []struct {
+	src string
+	err string
+}{
+	{src: `status ok`, err: "Restofile:2:1: invalid status"},
+	{src: `time soon`, err: "Restofile:2:6: invalid duration"},
+	{src: `header "ETag"`, err: "header takes a header name and an assertion"},
+	{src: `json "id" is "1"`, err: `unknown json assertion "is"`},
+	{src: `body type "object"`, err: `unknown body assertion "type"`},
+	{src: `json "id" exists "1"`, err: "exists doesn't take a value"},
+	{src: `json "id" equals`, err: "equals takes a single value"},
+	{src: `json "id" type "date"`, err: `unknown type "date"`},
+	{src: `body matches "("`, err: "invalid regex"},
+	{src: `cookie "session" exists`, err: `unknown assertion "cookie"`},
+	{src: `json { "id" "1" }`, err: "the expect block can't hold a json block"},
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, c := range parseErrorCases {
+		_, err := parse(t, "expect {\n"+c.src+"\n}")
+
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s :: expected an error containing %q, got %v", c.src, c.err, err)
+		}
+	}
+}
+
+func TestParseInterpolate(t *testing.T) {
+	file, err := restofile.Parse("Restofile", []byte(`expect { json "id" equals "{{id}}" }`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertions, err := Parse(file.Block("expect"), func(pos restofile.Pos, s string) string {
+		return strings.ReplaceAll(s, "{{id}}", "42")
+	})
+
+	if err != nil || assertions[0].Target != "id" || assertions[0].Values[0] != "42" {
+		t.Errorf("expected the value to be interpolated, got %+v (%v)", assertions, err)
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, src := range []string{`status 200 201`, `time 500ms`, `header "ETag" exists`, `json "data.id" equals "42"`, `body contains "ok"`} {
+		assertions, err := parse(t, "expect {\n"+src+"\n}")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := assertions[0].String(); got != src {
+			t.Errorf("expected %q, got %q", src, got)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := strings.Join(Diff("{\n  \"id\": 1,\n  \"name\": \"a\"\n}", "{\n  \"id\": 2,\n  \"name\": \"a\"\n}"), "\n")
+	expected := "  {\n-   \"id\": 1,\n+   \"id\": 2,\n    \"name\": \"a\"\n  }"
+
+	if got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
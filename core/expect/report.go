@@ -0,0 +1,191 @@
+package expect
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Suite is the results of the requests of a Restofile
+type Suite struct {
+	Name     string
+	Cases    []Case
+	Duration time.Duration
+}
+
+// Case is the results of a request, Err is set when it couldn't be sent or checked
+type Case struct {
+	Name     string
+	Duration time.Duration
+	Results  []Result
+	Err      error
+}
+
+// Failures returns the failed assertions of the request
+func (c Case) Failures() []Result {
+	var failures []Result
+
+	for _, result := range c.Results {
+		if !result.Passed {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+// Passed reports whether the request was sent and all its assertions passed
+func (c Case) Passed() bool {
+	return c.Err == nil && len(c.Failures()) == 0
+}
+
+// Detail explains a failed assertion, with a diff for the values that span several lines
+func (r Result) Detail() string {
+	if r.Message != "" {
+		return r.Message
+	}
+
+	if strings.Contains(r.Expected, "\n") || strings.Contains(r.Actual, "\n") {
+		return strings.Join(Diff(r.Expected, r.Actual), "\n")
+	}
+
+	return fmt.Sprintf("expected: %s\nactual:   %s", r.Expected, r.Actual)
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// WriteJUnit writes the results as JUnit XML, a testsuite for each Restofile and a testcase for each request
+func WriteJUnit(w io.Writer, suites []Suite) error {
+	report := junitSuites{}
+
+	var total time.Duration
+
+	for _, suite := range suites {
+		s := junitSuite{Name: suite.Name, Tests: len(suite.Cases), Time: seconds(suite.Duration)}
+
+		for _, c := range suite.Cases {
+			jc := junitCase{Name: c.Name, ClassName: suite.Name, Time: seconds(c.Duration)}
+
+			if c.Err != nil {
+				jc.Error = &junitMessage{Message: c.Err.Error(), Text: c.Err.Error()}
+				s.Errors++
+			} else if failures := c.Failures(); len(failures) > 0 {
+				var details []string
+
+				for _, failure := range failures {
+					details = append(details, failure.Assertion.Pos.String()+": "+failure.Assertion.String()+"\n"+failure.Detail())
+				}
+
+				jc.Failure = &junitMessage{Message: fmt.Sprintf("%d of %d assertions failed", len(failures), len(c.Results)), Text: strings.Join(details, "\n\n")}
+				s.Failures++
+			}
+
+			s.Cases = append(s.Cases, jc)
+		}
+
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Errors += s.Errors
+		report.Suites = append(report.Suites, s)
+		total += suite.Duration
+	}
+
+	report.Time = seconds(total)
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+
+	return err
+}
+
+// WriteTAP writes the results in the Test Anything Protocol, version 13, with the failures in YAML blocks
+func WriteTAP(w io.Writer, suites []Suite) error {
+	var lines []string
+
+	n := 0
+
+	for _, suite := range suites {
+		for _, c := range suite.Cases {
+			n++
+
+			name := suite.Name + " " + c.Name
+
+			if c.Passed() {
+				lines = append(lines, fmt.Sprintf("ok %d - %s", n, name))
+
+				continue
+			}
+
+			lines = append(lines, fmt.Sprintf("not ok %d - %s", n, name), "  ---")
+
+			if c.Err != nil {
+				lines = append(lines, "  message: "+strconv.Quote(c.Err.Error()), "  severity: error")
+			} else {
+				lines = append(lines, "  severity: fail", "  failures:")
+
+				for _, failure := range c.Failures() {
+					lines = append(lines,
+						"    - assertion: "+strconv.Quote(failure.Assertion.String()),
+						"      at: "+strconv.Quote(failure.Assertion.Pos.String()),
+					)
+
+					if failure.Message != "" {
+						lines = append(lines, "      message: "+strconv.Quote(failure.Message))
+					} else {
+						lines = append(lines,
+							"      expected: "+strconv.Quote(failure.Expected),
+							"      actual: "+strconv.Quote(failure.Actual),
+						)
+					}
+				}
+			}
+
+			lines = append(lines, "  ...")
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "TAP version 13\n1..%d\n%s\n", n, strings.Join(lines, "\n"))
+
+	return err
+}
@@ -0,0 +1,90 @@
+package expect
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdfnx/resto/core/restofile"
+)
+
+func suites() []Suite {
+	status := Assertion{Pos: restofile.Pos{File: "Restofile", Line: 3, Column: 3}, Subject: "status", Op: "equals", Values: []string{"200"}}
+
+	return []Suite{{
+		Name:     "Restofile",
+		Duration: 300 * time.Millisecond,
+		Cases: []Case{
+			{Name: "login", Duration: 100 * time.Millisecond, Results: []Result{{Assertion: status, Passed: true}}},
+			{Name: "me", Duration: 100 * time.Millisecond, Results: []Result{{Assertion: status, Expected: "200", Actual: "401"}}},
+			{Name: "logout", Err: errors.New(`Restofile:9:8: undefined variable "token"`)},
+		},
+	}}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := WriteJUnit(&out, suites()); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitSuites
+
+	if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out.String())
+	}
+
+	if report.Tests != 3 || report.Failures != 1 || report.Errors != 1 {
+		t.Errorf("expected 3 tests, 1 failure and 1 error, got %+v", report)
+	}
+
+	cases := report.Suites[0].Cases
+
+	if cases[0].Failure != nil || cases[0].Error != nil || cases[0].Time != "0.100" {
+		t.Errorf("expected login to pass in 0.100s, got %+v", cases[0])
+	}
+
+	if cases[1].Failure == nil || !strings.Contains(cases[1].Failure.Text, "Restofile:3:3: status 200\nexpected: 200\nactual:   401") {
+		t.Errorf("expected me to fail with the status, got %+v", cases[1].Failure)
+	}
+
+	if cases[2].Error == nil || !strings.Contains(cases[2].Error.Message, "undefined variable") {
+		t.Errorf("expected logout to be an error, got %+v", cases[2])
+	}
+}
+
+func TestWriteTAP(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := WriteTAP(&out, suites()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"TAP version 13",
+		"1..3",
+		"ok 1 - Restofile login",
+		"not ok 2 - Restofile me",
+		"  ---",
+		"  severity: fail",
+		"  failures:",
+		`    - assertion: "status 200"`,
+		`      at: "Restofile:3:3"`,
+		`      expected: "200"`,
+		`      actual: "401"`,
+		"  ...",
+		"not ok 3 - Restofile logout",
+		"  ---",
+		`  message: "Restofile:9:8: undefined variable \"token\""`,
+		"  severity: error",
+		"  ...",
+	}, "\n") + "\n"
+
+	if out.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
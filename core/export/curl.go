@@ -0,0 +1,47 @@
+package export
+
+import (
+	"net/http"
+	"strings"
+)
+
+type curlGenerator struct{}
+
+func (curlGenerator) Name() string {
+	return "curl"
+}
+
+func (curlGenerator) Generate(req *http.Request) (string, error) {
+	body, err := requestBody(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{"curl"}
+
+	switch req.Method {
+	case "", http.MethodGet:
+	case http.MethodHead:
+		parts = append(parts, "--head")
+	default:
+		parts = append(parts, "-X "+req.Method)
+	}
+
+	parts = append(parts, shellQuote(req.URL.String()))
+
+	for _, h := range sortedHeaders(req) {
+		parts = append(parts, "-H "+shellQuote(h.Key+": "+h.Value))
+	}
+
+	if body != "" {
+		parts = append(parts, "--data-raw "+shellQuote(body))
+	}
+
+	return strings.Join(parts, " \\\n  "), nil
+}
+
+// shellQuote wraps s in single quotes so a POSIX shell reads it literally
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
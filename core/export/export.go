@@ -0,0 +1,121 @@
+package export
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Generator turns a request into a snippet that reproduces it with another tool
+type Generator interface {
+	Name() string
+	Generate(req *http.Request) (string, error)
+}
+
+var generators = []Generator{
+	curlGenerator{},
+	goGenerator{},
+	pythonGenerator{},
+	javascriptGenerator{},
+	powershellGenerator{},
+}
+
+var aliases = map[string]string{
+	"golang":            "go",
+	"net/http":          "go",
+	"requests":          "python",
+	"javascript":        "js",
+	"fetch":             "js",
+	"pwsh":              "powershell",
+	"invoke-restmethod": "powershell",
+}
+
+// Names returns the names of all supported generators
+func Names() []string {
+	var names []string
+
+	for _, g := range generators {
+		names = append(names, g.Name())
+	}
+
+	return names
+}
+
+// Get returns the generator registered under name or one of its aliases
+func Get(name string) (Generator, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+
+	for _, g := range generators {
+		if g.Name() == name {
+			return g, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown export format %q, available formats: %s", name, strings.Join(Names(), ", "))
+}
+
+// Generate is a shortcut for Get(name) followed by Generate(req)
+func Generate(name string, req *http.Request) (string, error) {
+	g, err := Get(name)
+
+	if err != nil {
+		return "", err
+	}
+
+	return g.Generate(req)
+}
+
+// requestBody reads the body of req without consuming it
+func requestBody(req *http.Request) (string, error) {
+	if req.GetBody == nil {
+		return "", nil
+	}
+
+	b, err := req.GetBody()
+
+	if err != nil {
+		return "", err
+	}
+
+	defer b.Close()
+
+	data, err := ioutil.ReadAll(b)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+type header struct {
+	Key   string
+	Value string
+}
+
+// sortedHeaders flattens the headers of req in a stable order
+func sortedHeaders(req *http.Request) []header {
+	var keys []string
+
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var headers []header
+
+	for _, key := range keys {
+		for _, value := range req.Header[key] {
+			headers = append(headers, header{Key: key, Value: value})
+		}
+	}
+
+	return headers
+}
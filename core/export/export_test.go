@@ -0,0 +1,98 @@
+package export
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newRequest(t *testing.T, method, url, body string, headers map[string]string) *http.Request {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return req
+}
+
+func TestCurl(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "plain get",
+			method:   "GET",
+			url:      "https://api.github.com",
+			expected: "curl \\\n  'https://api.github.com'",
+		},
+		{
+			name:     "head",
+			method:   "HEAD",
+			url:      "https://api.github.com",
+			expected: "curl \\\n  --head \\\n  'https://api.github.com'",
+		},
+		{
+			name:    "post with headers and quoted body",
+			method:  "POST",
+			url:     "https://api.xcode.codes/users",
+			body:    `{"name": "it's me"}`,
+			headers: map[string]string{"Content-Type": "application/json", "Authorization": "Bearer TOKEN"},
+			expected: "curl \\\n  -X POST \\\n  'https://api.xcode.codes/users' \\\n" +
+				"  -H 'Authorization: Bearer TOKEN' \\\n" +
+				"  -H 'Content-Type: application/json' \\\n" +
+				"  --data-raw '{\"name\": \"it'\\''s me\"}'",
+		},
+	}
+
+	for _, tt := range cases {
+		out, err := Generate("curl", newRequest(t, tt.method, tt.url, tt.body, tt.headers))
+
+		if err != nil {
+			t.Fatalf("%s :: %s", tt.name, err.Error())
+		}
+
+		if out != tt.expected {
+			t.Errorf("%s :: got\n%s\nexpected\n%s", tt.name, out, tt.expected)
+		}
+	}
+}
+
+func TestGeneratorsIncludeBodyAndHeaders(t *testing.T) {
+	for _, name := range Names() {
+		req := newRequest(t, "PUT", "https://api.xcode.codes/jobs/1", `{"done":true}`, map[string]string{"X-Tenant": "acme"})
+
+		out, err := Generate(name, req)
+
+		if err != nil {
+			t.Fatalf("%s :: %s", name, err.Error())
+		}
+
+		for _, want := range []string{"https://api.xcode.codes/jobs/1", "X-Tenant", "acme", "done"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s :: output doesn't contain %q:\n%s", name, want, out)
+			}
+		}
+	}
+}
+
+func TestUnknownGenerator(t *testing.T) {
+	if _, err := Get("cobol"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+
+	for _, alias := range []string{"fetch", "pwsh", "golang", "requests"} {
+		if _, err := Get(alias); err != nil {
+			t.Errorf("alias %s :: %s", alias, err.Error())
+		}
+	}
+}
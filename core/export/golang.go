@@ -0,0 +1,68 @@
+package export
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type goGenerator struct{}
+
+func (goGenerator) Name() string {
+	return "go"
+}
+
+func (goGenerator) Generate(req *http.Request) (string, error) {
+	body, err := requestBody(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	b.WriteString("package main\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t\"fmt\"\n")
+	b.WriteString("\t\"io\"\n")
+	b.WriteString("\t\"net/http\"\n")
+
+	if body != "" {
+		b.WriteString("\t\"strings\"\n")
+	}
+
+	b.WriteString(")\n\n")
+	b.WriteString("func main() {\n")
+
+	bodyArg := "nil"
+
+	if body != "" {
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n\n", strconv.Quote(body))
+		bodyArg = "body"
+	}
+
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(req.Method), strconv.Quote(req.URL.String()), bodyArg)
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n\n")
+
+	headers := sortedHeaders(req)
+
+	for _, h := range headers {
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", strconv.Quote(h.Key), strconv.Quote(h.Value))
+	}
+
+	if len(headers) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\tres, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n\n")
+	b.WriteString("\tdefer res.Body.Close()\n\n")
+	b.WriteString("\tdata, err := io.ReadAll(res.Body)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n\n")
+	b.WriteString("\tfmt.Println(res.Status)\n")
+	b.WriteString("\tfmt.Println(string(data))\n")
+	b.WriteString("}")
+
+	return b.String(), nil
+}
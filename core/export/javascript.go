@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type javascriptGenerator struct{}
+
+func (javascriptGenerator) Name() string {
+	return "js"
+}
+
+func (javascriptGenerator) Generate(req *http.Request) (string, error) {
+	body, err := requestBody(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", quote(req.URL.String()))
+	fmt.Fprintf(&b, "  method: %s,\n", quote(req.Method))
+
+	headers := sortedHeaders(req)
+
+	if len(headers) > 0 {
+		b.WriteString("  headers: {\n")
+
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(h.Key), quote(h.Value))
+		}
+
+		b.WriteString("  },\n")
+	}
+
+	if body != "" {
+		fmt.Fprintf(&b, "  body: %s,\n", quote(body))
+	}
+
+	b.WriteString("});\n\n")
+	b.WriteString("console.log(response.status);\n")
+	b.WriteString("console.log(await response.text());")
+
+	return b.String(), nil
+}
+
+// quote returns s as a double quoted string literal, valid in both javascript and python
+func quote(s string) string {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type powershellGenerator struct{}
+
+func (powershellGenerator) Name() string {
+	return "powershell"
+}
+
+func (powershellGenerator) Generate(req *http.Request) (string, error) {
+	body, err := requestBody(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	args := []string{
+		"-Uri " + psQuote(req.URL.String()),
+		"-Method " + psMethod(req.Method),
+	}
+
+	var headers []header
+
+	for _, h := range sortedHeaders(req) {
+		// Invoke-RestMethod refuses a Content-Type header, it has its own parameter for it
+		if http.CanonicalHeaderKey(h.Key) == "Content-Type" {
+			args = append(args, "-ContentType "+psQuote(h.Value))
+			continue
+		}
+
+		headers = append(headers, h)
+	}
+
+	if len(headers) > 0 {
+		b.WriteString("$headers = @{\n")
+
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s = %s\n", psQuote(h.Key), psQuote(h.Value))
+		}
+
+		b.WriteString("}\n")
+		args = append(args, "-Headers $headers")
+	}
+
+	if body != "" {
+		fmt.Fprintf(&b, "$body = %s\n", psQuote(body))
+		args = append(args, "-Body $body")
+	}
+
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Invoke-RestMethod " + strings.Join(args, " "))
+
+	return b.String(), nil
+}
+
+// psQuote wraps s in single quotes so powershell reads it literally
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// psMethod converts an http method to the casing of the -Method enum, e.g. POST -> Post
+func psMethod(method string) string {
+	if method == "" {
+		return "Get"
+	}
+
+	return strings.ToUpper(method[:1]) + strings.ToLower(method[1:])
+}
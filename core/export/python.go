@@ -0,0 +1,51 @@
+package export
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type pythonGenerator struct{}
+
+func (pythonGenerator) Name() string {
+	return "python"
+}
+
+func (pythonGenerator) Generate(req *http.Request) (string, error) {
+	body, err := requestBody(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", quote(req.URL.String()))
+
+	args := "url"
+	headers := sortedHeaders(req)
+
+	if len(headers) > 0 {
+		b.WriteString("headers = {\n")
+
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(h.Key), quote(h.Value))
+		}
+
+		b.WriteString("}\n")
+		args += ", headers=headers"
+	}
+
+	if body != "" {
+		fmt.Fprintf(&b, "data = %s\n", quote(body))
+		args += ", data=data"
+	}
+
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s, %s)\n\n", quote(req.Method), args)
+	b.WriteString("print(response.status_code)\n")
+	b.WriteString("print(response.text)")
+
+	return b.String(), nil
+}
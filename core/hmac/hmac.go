@@ -0,0 +1,181 @@
+package hmac
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/abdfnx/resto/core/options"
+)
+
+const (
+	// DefaultTemplate is the canonical string of requests when no template is given
+	DefaultTemplate = `{method}\n{path}\n{timestamp}\n{body}`
+
+	// DefaultHeader is the header the signature is sent in
+	DefaultHeader = "X-Signature"
+
+	// DefaultTimestampHeader is the header the timestamp is sent in, when the template uses it
+	DefaultTimestampHeader = "X-Timestamp"
+)
+
+// Message is the part of a request that can be signed
+type Message struct {
+	Method    string
+	Host      string
+	Path      string
+	Query     string
+	Timestamp string
+	Body      []byte
+}
+
+// Config returns cfg with the defaults of the empty fields
+func Config(cfg options.HMAC) options.HMAC {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = "sha256"
+	}
+
+	if cfg.Header == "" {
+		cfg.Header = DefaultHeader
+	}
+
+	if cfg.Template == "" {
+		cfg.Template = DefaultTemplate
+	}
+
+	if cfg.TimestampHeader == "" {
+		cfg.TimestampHeader = DefaultTimestampHeader
+	}
+
+	if cfg.Encoding == "" {
+		cfg.Encoding = "hex"
+	}
+
+	cfg.Algorithm = strings.ToLower(cfg.Algorithm)
+	cfg.Encoding = strings.ToLower(cfg.Encoding)
+
+	return cfg
+}
+
+// Validate checks the algorithm and the encoding of cfg
+func Validate(cfg options.HMAC) error {
+	cfg = Config(cfg)
+
+	if _, err := newHash(cfg.Algorithm); err != nil {
+		return err
+	}
+
+	if cfg.Encoding != "hex" && cfg.Encoding != "base64" {
+		return fmt.Errorf("hmac: unknown encoding %q, it must be hex or base64", cfg.Encoding)
+	}
+
+	return nil
+}
+
+// UsesTimestamp reports whether the canonical string of template contains the timestamp
+func UsesTimestamp(template string) bool {
+	return strings.Contains(Config(options.HMAC{Template: template}).Template, "{timestamp}")
+}
+
+// Canonical builds the string to sign from template, `\n` and `\t` in the template are line feeds and tabs
+//
+// The placeholders are {method}, {host}, {path}, {query}, {uri} (the path and the query),
+// {timestamp}, {body} and {body_sha256} (the hex SHA-256 of the body)
+func Canonical(template string, msg Message) string {
+	uri := msg.Path
+
+	if uri == "" {
+		uri = "/"
+	}
+
+	path := uri
+
+	if msg.Query != "" {
+		uri += "?" + msg.Query
+	}
+
+	bodyHash := sha256.Sum256(msg.Body)
+
+	return strings.NewReplacer(
+		`\n`, "\n",
+		`\t`, "\t",
+		"{method}", strings.ToUpper(msg.Method),
+		"{host}", msg.Host,
+		"{path}", path,
+		"{query}", msg.Query,
+		"{uri}", uri,
+		"{timestamp}", msg.Timestamp,
+		"{body}", string(msg.Body),
+		"{body_sha256}", hex.EncodeToString(bodyHash[:]),
+	).Replace(Config(options.HMAC{Template: template}).Template)
+}
+
+// Sign returns the encoded HMAC of canonical with the key of cfg
+func Sign(cfg options.HMAC, canonical string) (string, error) {
+	cfg = Config(cfg)
+
+	if err := Validate(cfg); err != nil {
+		return "", err
+	}
+
+	newHash, _ := newHash(cfg.Algorithm)
+
+	mac := hmac.New(newHash, []byte(cfg.Key))
+	mac.Write([]byte(canonical))
+
+	if cfg.Encoding == "base64" {
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+	}
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Result is the outcome of Verify, with what's needed to debug a mismatch
+type Result struct {
+	Canonical string
+	Expected  string
+	Match     bool
+}
+
+// Verify computes the signature of msg and compares it to signature, an `algorithm=` prefix like `sha256=` is ignored
+func Verify(cfg options.HMAC, msg Message, signature string) (*Result, error) {
+	cfg = Config(cfg)
+	canonical := Canonical(cfg.Template, msg)
+	expected, err := Sign(cfg, canonical)
+
+	if err != nil {
+		return nil, err
+	}
+
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), cfg.Algorithm+"=")
+
+	if cfg.Encoding == "hex" {
+		signature = strings.ToLower(signature)
+	}
+
+	return &Result{
+		Canonical: canonical,
+		Expected:  expected,
+		Match:     subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1,
+	}, nil
+}
+
+func newHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	case "sha1":
+		return sha1.New, nil
+	}
+
+	return nil, fmt.Errorf("hmac: unknown algorithm %q, it must be sha256, sha512 or sha1", algorithm)
+}
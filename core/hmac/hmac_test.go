@@ -0,0 +1,82 @@
+package hmac
+
+import (
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+)
+
+var message = Message{
+	Method:    "post",
+	Host:      "hooks.example.com",
+	Path:      "/orders/42",
+	Query:     "dry_run=1",
+	Timestamp: "1700000000",
+	Body:      []byte(`{"id":42}`),
+}
+
+func TestCanonical(t *testing.T) {
+	cases := []struct {
+		template string
+		expected string
+	}{
+		{"", "POST\n/orders/42\n1700000000\n{\"id\":42}"},
+		{`{timestamp}.{body}`, `1700000000.{"id":42}`},
+		{`{method} {uri}\t{host}`, "POST /orders/42?dry_run=1\thooks.example.com"},
+		{`{body_sha256}`, "17b4db064e17f4878e391177e6ca623b798911f34014bc9e78920993d7dd27ad"},
+	}
+
+	for _, c := range cases {
+		if got := Canonical(c.template, message); got != c.expected {
+			t.Errorf("%q :: expected %q, got %q", c.template, c.expected, got)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	// RFC 4231 test case 2
+	cfg := options.HMAC{Key: "Jefe"}
+	expected := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+
+	if got, err := Sign(cfg, "what do ya want for nothing?"); err != nil || got != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, got, err)
+	}
+
+	cfg.Algorithm = "sha512"
+	expected = "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"
+
+	if got, err := Sign(cfg, "what do ya want for nothing?"); err != nil || got != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, got, err)
+	}
+
+	cfg.Algorithm = "md5"
+
+	if _, err := Sign(cfg, ""); err == nil {
+		t.Errorf("expected an error for an unknown algorithm")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	cfg := options.HMAC{Key: "s3cr3t", Encoding: "base64"}
+	signature, _ := Sign(cfg, Canonical("", message))
+
+	result, err := Verify(cfg, message, signature)
+
+	if err != nil || !result.Match {
+		t.Errorf("expected the signature to match, got %+v (%v)", result, err)
+	}
+
+	cfg.Encoding = "hex"
+	signature, _ = Sign(cfg, Canonical("", message))
+
+	if result, _ := Verify(cfg, message, "sha256="+signature); !result.Match {
+		t.Errorf("expected the sha256= prefix to be ignored")
+	}
+
+	tampered := message
+	tampered.Timestamp = "1700000001"
+
+	if result, _ := Verify(cfg, tampered, signature); result.Match {
+		t.Errorf("expected another timestamp to change the signature")
+	}
+}
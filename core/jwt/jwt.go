@@ -0,0 +1,208 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tidwall/pretty"
+)
+
+// expiringSoon is when a still valid token starts to get a warning
+const expiringSoon = 5 * time.Minute
+
+// Token is a decoded, not yet verified, JSON Web Token
+type Token struct {
+	Raw       string
+	Header    map[string]interface{}
+	Claims    map[string]interface{}
+	Signature []byte
+
+	header []byte
+	claims []byte
+}
+
+// Decode splits a compact JWT and decodes its header and claims, the signature isn't verified
+func Decode(raw string) (*Token, error) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(strings.TrimPrefix(raw, "Bearer "), "bearer ")
+
+	parts := strings.Split(raw, ".")
+
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("jwt: a token has 3 parts separated by dots, got %d", len(parts))
+	}
+
+	t := &Token{Raw: raw}
+
+	var err error
+
+	if t.header, err = decodeSegment(parts[0]); err != nil {
+		return nil, fmt.Errorf("jwt: invalid header: %s", err.Error())
+	}
+
+	if t.claims, err = decodeSegment(parts[1]); err != nil {
+		return nil, fmt.Errorf("jwt: invalid claims: %s", err.Error())
+	}
+
+	if t.Signature, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[2], "=")); err != nil {
+		return nil, fmt.Errorf("jwt: invalid signature: %s", err.Error())
+	}
+
+	if err := unmarshal(t.header, &t.Header); err != nil {
+		return nil, fmt.Errorf("jwt: invalid header: %s", err.Error())
+	}
+
+	if err := unmarshal(t.claims, &t.Claims); err != nil {
+		return nil, fmt.Errorf("jwt: invalid claims: %s", err.Error())
+	}
+
+	return t, nil
+}
+
+// ExpiredWarnings returns the warnings of raw when it's a JWT that already expired, tokens that aren't JWTs have none
+func ExpiredWarnings(raw string, now time.Time) []string {
+	token, err := Decode(raw)
+
+	if err != nil || !token.Expired(now) {
+		return nil
+	}
+
+	return token.Warnings(now, "")
+}
+
+// HeaderJSON is the indented json of the header
+func (t *Token) HeaderJSON() string {
+	return strings.TrimSpace(string(pretty.Pretty(t.header)))
+}
+
+// ClaimsJSON is the indented json of the claims
+func (t *Token) ClaimsJSON() string {
+	return strings.TrimSpace(string(pretty.Pretty(t.claims)))
+}
+
+// Algorithm is the `alg` of the header
+func (t *Token) Algorithm() string {
+	alg, _ := t.Header["alg"].(string)
+
+	return alg
+}
+
+// Time returns a NumericDate claim like exp, nbf or iat
+func (t *Token) Time(claim string) (time.Time, bool) {
+	number, ok := t.Claims[claim].(json.Number)
+
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seconds, err := number.Float64()
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(seconds), 0), true
+}
+
+// Audiences returns the `aud` claim, which is a string or a list of strings
+func (t *Token) Audiences() []string {
+	switch aud := t.Claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		audiences := []string{}
+
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+
+		return audiences
+	}
+
+	return nil
+}
+
+// HasAudience reports whether the token is meant for audience
+func (t *Token) HasAudience(audience string) bool {
+	for _, aud := range t.Audiences() {
+		if aud == audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Times describes iat, nbf and exp as human times relative to now
+func (t *Token) Times(now time.Time) []string {
+	lines := []string{}
+
+	for _, claim := range []struct{ name, title string }{
+		{"iat", "Issued At"},
+		{"nbf", "Not Before"},
+		{"exp", "Expires At"},
+	} {
+		if at, ok := t.Time(claim.name); ok {
+			lines = append(lines, fmt.Sprintf("%-11s %s (%s)", claim.title+":", at.Local().Format("2006-01-02 15:04:05 MST"), relative(at, now)))
+		}
+	}
+
+	return lines
+}
+
+// Warnings lists the problems of the token at now, like an expired token or a wrong audience
+func (t *Token) Warnings(now time.Time, audience string) []string {
+	warnings := []string{}
+
+	if exp, ok := t.Time("exp"); ok {
+		if !now.Before(exp) {
+			warnings = append(warnings, "the token expired "+relative(exp, now))
+		} else if exp.Sub(now) < expiringSoon {
+			warnings = append(warnings, "the token expires "+relative(exp, now))
+		}
+	}
+
+	if nbf, ok := t.Time("nbf"); ok && now.Before(nbf) {
+		warnings = append(warnings, "the token isn't valid before "+relative(nbf, now))
+	}
+
+	if audience != "" && !t.HasAudience(audience) {
+		warnings = append(warnings, fmt.Sprintf("the token is for %q, not %q", strings.Join(t.Audiences(), ", "), audience))
+	}
+
+	return warnings
+}
+
+// Expired reports whether the exp claim is in the past
+func (t *Token) Expired(now time.Time) bool {
+	exp, ok := t.Time("exp")
+
+	return ok && !now.Before(exp)
+}
+
+func relative(at, now time.Time) string {
+	d := at.Sub(now).Round(time.Second)
+
+	if d < 0 {
+		return (-d).String() + " ago"
+	}
+
+	return "in " + d.String()
+}
+
+func decodeSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
+
+func unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
@@ -0,0 +1,197 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/abdfnx/resto/tools"
+)
+
+// Key is what a token can be verified with, only one of its fields is used
+type Key struct {
+	// Secret is the HS256 shared secret, `env:NAME` reads it from the environment
+	Secret string
+
+	// File is a PEM public key or certificate for RS256
+	File string
+
+	// JWKS is a local JSON Web Key Set file for RS256
+	JWKS string
+}
+
+// Empty reports whether no key was given
+func (k Key) Empty() bool {
+	return k.Secret == "" && k.File == "" && k.JWKS == ""
+}
+
+// Verify checks the signature of the token with key
+func (t *Token) Verify(key Key) error {
+	signingInput := t.Raw[:strings.LastIndex(t.Raw, ".")]
+
+	switch t.Algorithm() {
+	case "HS256":
+		if key.Secret == "" {
+			return fmt.Errorf("jwt: a secret is required to verify HS256 tokens")
+		}
+
+		secret, err := tools.ResolveValue(key.Secret)
+
+		if err != nil {
+			return err
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(signingInput))
+
+		if !hmac.Equal(mac.Sum(nil), t.Signature) {
+			return fmt.Errorf("jwt: invalid HS256 signature")
+		}
+
+		return nil
+	case "RS256":
+		publicKey, err := t.rsaKey(key)
+
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256([]byte(signingInput))
+
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, sum[:], t.Signature); err != nil {
+			return fmt.Errorf("jwt: invalid RS256 signature")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("jwt: can't verify %q tokens, only HS256 and RS256", t.Algorithm())
+	}
+}
+
+func (t *Token) rsaKey(key Key) (*rsa.PublicKey, error) {
+	if key.File != "" {
+		data, err := ioutil.ReadFile(key.File)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return ParsePublicKey(data)
+	}
+
+	if key.JWKS != "" {
+		data, err := ioutil.ReadFile(key.JWKS)
+
+		if err != nil {
+			return nil, err
+		}
+
+		kid, _ := t.Header["kid"].(string)
+
+		return KeyFromJWKS(data, kid)
+	}
+
+	return nil, fmt.Errorf("jwt: a public key or a JWKS file is required to verify RS256 tokens")
+}
+
+// ParsePublicKey reads a PEM encoded RSA public key or certificate
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, fmt.Errorf("jwt: the key isn't PEM encoded")
+	}
+
+	var parsed interface{}
+	var err error
+
+	switch block.Type {
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			parsed = cert.PublicKey
+		}
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("jwt: invalid public key: %s", err.Error())
+	}
+
+	publicKey, ok := parsed.(*rsa.PublicKey)
+
+	if !ok {
+		return nil, fmt.Errorf("jwt: the public key isn't an RSA key")
+	}
+
+	return publicKey, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// KeyFromJWKS returns the RSA key of a JSON Web Key Set, with kid or the only signing key when the token has no kid
+func KeyFromJWKS(data []byte, kid string) (*rsa.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: invalid JWKS: %s", err.Error())
+	}
+
+	candidates := []jwk{}
+
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		if kid == "" || key.Kid == kid {
+			candidates = append(candidates, key)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("jwt: no RSA key with kid %q in the JWKS", kid)
+	}
+
+	if len(candidates) > 1 {
+		return nil, fmt.Errorf("jwt: the token has no kid and the JWKS has %d RSA keys", len(candidates))
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(candidates[0].N)
+
+	if err != nil {
+		return nil, fmt.Errorf("jwt: invalid JWK modulus: %s", err.Error())
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(candidates[0].E)
+
+	if err != nil {
+		return nil, fmt.Errorf("jwt: invalid JWK exponent: %s", err.Error())
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
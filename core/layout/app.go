@@ -6,12 +6,21 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/abdfnx/resto/core/api"
 	"github.com/abdfnx/resto/core/editor"
 	"github.com/abdfnx/resto/core/editor/runtime"
+	"github.com/abdfnx/resto/core/environment"
+	"github.com/abdfnx/resto/core/export"
+	"github.com/abdfnx/resto/core/jwt"
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/vault"
 	"github.com/abdfnx/resto/tools"
 
+	"github.com/atotto/clipboard"
 	tcell "github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/tidwall/gjson"
@@ -38,7 +47,16 @@ var (
 	headersCount int = 0
 )
 
-func Layout(version string) {
+// Layout opens the TUI, the {{name}} variables of the request are replaced with the ones of env, if it's not nil
+func Layout(version string, env *environment.Environment) {
+	LayoutWithRequest(version, nil, env)
+}
+
+// LayoutWithRequest opens the TUI with the request form filled from initial, if it's not nil
+func LayoutWithRequest(version string, initial *options.Request, env *environment.Environment) {
+	// a terminal prompt can't run under the TUI, the vault is unlocked with its own input
+	vault.Prompt = nil
+
 	app := tview.NewApplication()
 	flex := tview.NewFlex()
 
@@ -127,20 +145,28 @@ func Layout(version string) {
 			method = option
 		}).SetCurrentOption(0)
 
+	contentTypes := []string{
+		"none",
+		"application/json",
+		"application/graphql",
+		"application/xml",
+		"text/html",
+		"text/plain",
+	}
+
 	contentType := tview.NewDropDown().
 		SetLabel("Content Type").
-		SetOptions([]string{
-			"none",
-			"application/json",
-			"application/graphql",
-			"application/xml",
-			"text/html",
-			"text/plain",
-		}, func(option string, optionIndex int) {
+		SetOptions(contentTypes, func(option string, optionIndex int) {
 			cType = option
 		}).SetCurrentOption(0)
 
 	// request body
+	if initial != nil && initial.Body != "" {
+		if err := ioutil.WriteFile(fn, []byte(initial.Body), 0644); err != nil {
+			log.Fatalf("could not write %v: %v", fn, err)
+		}
+	}
+
 	content, err := ioutil.ReadFile(fn)
 	buffer := editor.NewBufferFromString(string(content), fn)
 	if err != nil {
@@ -213,27 +239,113 @@ func Layout(version string) {
 			app.Draw()
 		})
 
+	// response filter
+	filterField := tview.NewInputField().
+		SetPlaceholder("gjson path, e.g. data.users.#.name")
+
+	showResponse := func() {
+		responseView.Clear()
+
+		filtered, err := api.FilterResponse(respone, filterField.GetText(), false)
+
+		if err != nil {
+			filtered = err.Error()
+		}
+
+		fmt.Fprintf(responseView, "%s ", filtered)
+	}
+
+	filterField.SetChangedFunc(func(text string) {
+		showResponse()
+	})
+
+	filterField.SetDoneFunc(func(key tcell.Key) {
+		app.SetRoot(flex, true).SetFocus(responseView)
+	})
+
 	// headers inputs
 	headers := tview.NewTextView()
 
-	// auth inputs
-	token := tview.NewInputField().
-		SetLabel("Token").
-		SetFieldWidth(20)
+	// auth inputs, each provider gets the inputs of its fields, in the order of its fields
+	authProviders := api.AuthProviders()
+	authInputs := map[string][]tview.FormItem{}
+
+	for _, provider := range authProviders {
+		for _, field := range provider.Fields() {
+			var input tview.FormItem
+
+			if field.Options != nil {
+				input = tview.NewDropDown().
+					SetLabel(field.Label).
+					SetOptions(field.Options, nil).
+					SetCurrentOption(0)
+			} else {
+				inputField := tview.NewInputField().
+					SetLabel(field.Label).
+					SetText(field.Default).
+					SetFieldWidth(20)
+
+				if field.Secret {
+					inputField.SetMaskCharacter('*')
+				}
 
-	username := tview.NewInputField().
-		SetLabel("Username").
-		SetFieldWidth(20)
+				input = inputField
+			}
 
-	password := tview.NewInputField().
-		SetLabel("Password").
-		SetFieldWidth(20)
+			authInputs[provider.Name()] = append(authInputs[provider.Name()], input)
+		}
+	}
+
+	// inputValue returns the text of an input, or the option picked in a dropdown
+	inputValue := func(input tview.FormItem) string {
+		if dropDown, ok := input.(*tview.DropDown); ok {
+			_, option := dropDown.GetCurrentOption()
+
+			return option
+		}
+
+		return input.(*tview.InputField).GetText()
+	}
+
+	// setInputValue fills the input of field with value, an empty value sets the default of the field
+	setInputValue := func(field api.AuthField, input tview.FormItem, value string) {
+		if dropDown, ok := input.(*tview.DropDown); ok {
+			dropDown.SetCurrentOption(0)
+
+			for i, option := range field.Options {
+				if option == strings.ToLower(value) {
+					dropDown.SetCurrentOption(i)
+				}
+			}
+
+			return
+		}
+
+		if value == "" {
+			value = field.Default
+		}
+
+		input.(*tview.InputField).SetText(value)
+	}
+
+	currentAuth := func() *options.Auth {
+		auth := &options.Auth{Type: authType}
+
+		if provider, ok := api.LookupAuthProvider(authType); ok {
+			for i, field := range provider.Fields() {
+				*field.Value(auth) = inputValue(authInputs[authType][i])
+			}
+		}
+
+		return auth
+	}
 
 	flex.AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(requestForm, 0, 1, false).
 		AddItem(authForm, 20, 1, false).
 		AddItem(headersForm, 15, 1, false), 0, 1, false).
 		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(filterField, 3, 1, false).
 			AddItem(responseView, 0, 3, false).
 			AddItem(statusView, 7, 1, false), 0, 2, false).
 		AddItem(tview.NewBox().SetBorder(true), 0, 0, false)
@@ -278,12 +390,7 @@ func Layout(version string) {
 		})
 	})
 
-	send := func() {
-		responseView.Clear()
-		statusView.Clear()
-
-		httpURL = urlField.GetText()
-
+	readBody := func() string {
 		b, e := os.Open(fn)
 
 		if e != nil {
@@ -300,49 +407,145 @@ func Layout(version string) {
 		if cType == "application/json" {
 			var r map[string]interface{}
 			json.Unmarshal([]byte(currentBody), &r)
-			body = string(pretty.Pretty([]byte(currentBody)))
-		} else {
-			body = string(currentBody)
+			return string(pretty.Pretty([]byte(currentBody)))
 		}
 
-		if method == "POST" || method == "PUT" || method == "PATCH" || method == "DELETE" {
-			respone, status, requestHeaders, _ = api.BasicRequestWithBody(
-				httpURL,
-				method,
-				cType,
-				body,
-				authType,
-				token.GetText(),
-				username.GetText(),
-				password.GetText(),
-				false,
-				headersCount,
-				headersForm,
-			)
-		} else {
+		return string(currentBody)
+	}
+
+	// currentRequest returns the request of the form, with the variables of the environment replaced
+	currentRequest := func(reqContentType, reqBody string) (*options.Request, error) {
+		req := &options.Request{
+			Method:      method,
+			URL:         urlField.GetText(),
+			ContentType: reqContentType,
+			Body:        reqBody,
+			Headers:     api.FormHeaders(headersCount, headersForm),
+			AuthType:    currentAuth(),
+		}
+
+		if env == nil {
+			return req, nil
+		}
+
+		var err error
+
+		expand := func(value *string) {
+			if err == nil {
+				*value, err = environment.Expand(*value, env.Vars)
+			}
+		}
+
+		// auth values are resolved when the request is built, like the ones of a Restofile
+		interpolate := func(value *string) {
+			if err == nil {
+				*value, err = environment.Interpolate(*value, env.Vars)
+			}
+		}
+
+		expand(&req.URL)
+		expand(&req.Body)
+
+		for key, value := range req.Headers {
+			expand(&value)
+			req.Headers[key] = value
+		}
+
+		if provider, ok := api.LookupAuthProvider(authType); ok {
+			for _, field := range provider.Fields() {
+				interpolate(field.Value(req.AuthType))
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%v, it's not in the %s environment", err, env.Name)
+		}
+
+		return req, nil
+	}
+
+	send := func() {
+		responseView.Clear()
+		statusView.Clear()
+
+		httpURL = urlField.GetText()
+		body = readBody()
+
+		if method != "POST" && method != "PUT" && method != "PATCH" && method != "DELETE" {
 			body = ""
+		}
+
+		req, err := currentRequest(cType, body)
 
-			respone, status, requestHeaders, _ = api.BasicGet(
-				httpURL,
-				method,
-				authType,
-				token.GetText(),
-				username.GetText(),
-				password.GetText(),
-				false,
-				headersCount,
-				headersForm,
-			)
+		if err == nil {
+			respone, status, requestHeaders, err = api.Do(req, false)
+		}
+
+		if err != nil {
+			fmt.Fprintf(statusView, "%s ", err.Error())
+			return
 		}
 
 		headers.Clear()
 		requestHeaders += "\n\nTo Exit Press 'Esc' Key"
 
-		fmt.Fprintf(responseView, "%s ", respone)
+		showResponse()
 		fmt.Fprintf(statusView, "%s ", status)
 		fmt.Fprintf(headers, "%s", requestHeaders)
 	}
 
+	// withVault runs action once the vault is unlocked, when the auth fields reference `secret:` values
+	withVault := func(focus tview.Primitive, action func()) {
+		auth := currentAuth()
+
+		if req, err := currentRequest("", ""); err == nil {
+			auth = req.AuthType
+		}
+		needsVault := false
+
+		if provider, ok := api.LookupAuthProvider(auth.Type); ok {
+			for _, field := range provider.Fields() {
+				if field.Resolve && strings.HasPrefix(strings.TrimSpace(*field.Value(auth)), "secret:") {
+					needsVault = true
+				}
+			}
+		}
+
+		if !needsVault || !vault.Locked() {
+			action()
+			app.SetRoot(flex, true).SetFocus(focus)
+
+			return
+		}
+
+		unlockPage := tview.NewPages()
+
+		passphrase := tview.NewInputField().SetMaskCharacter('*')
+		passphrase.SetBorder(true)
+		passphrase.SetLabel("vault passphrase").SetLabelWidth(17).SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEnter {
+				statusView.Clear()
+
+				if _, err := vault.Unlock(passphrase.GetText()); err != nil {
+					fmt.Fprintf(statusView, "%s ", err.Error())
+				} else {
+					action()
+				}
+
+				app.SetRoot(flex, true).SetFocus(focus)
+			} else if key == tcell.KeyEsc {
+				app.SetRoot(flex, true).SetFocus(focus)
+			}
+		})
+
+		unlockPage.AddAndSwitchToPage("unlock", tview.NewGrid().
+			SetColumns(0, 0, 0).
+			SetRows(0, 3, 0).
+			AddItem(passphrase, 1, 1, 1, 1, 0, 0, true), true)
+
+		app.SetRoot(unlockPage, true).SetFocus(passphrase)
+	}
+
 	requestForm.AddFormItem(requestMethods).
 		AddFormItem(urlField).
 		AddFormItem(contentType).
@@ -356,11 +559,53 @@ func Layout(version string) {
 			app.SetRoot(flex, true).SetFocus(authForm)
 		}).
 		AddButton("Send", func() {
-			send()
-
-			app.SetRoot(flex, true).SetFocus(responseView)
+			withVault(responseView, send)
 		})
 
+	copyAsCurl := func() {
+		statusView.Clear()
+
+		reqBody := ""
+		reqContentType := ""
+
+		if method == "POST" || method == "PUT" || method == "PATCH" || method == "DELETE" {
+			reqBody = readBody()
+
+			if cType != "none" {
+				reqContentType = cType
+			}
+		}
+
+		current, err := currentRequest(reqContentType, reqBody)
+
+		if err != nil {
+			fmt.Fprintf(statusView, "%s ", err.Error())
+			return
+		}
+
+		req, err := api.Build(current)
+
+		if err != nil {
+			fmt.Fprintf(statusView, "%s ", err.Error())
+			return
+		}
+
+		api.RedactRequest(req, current.AuthType)
+
+		snippet, err := export.Generate("curl", req)
+
+		if err == nil {
+			err = clipboard.WriteAll(snippet)
+		}
+
+		if err != nil {
+			fmt.Fprintf(statusView, "Could not copy the request: %s ", err.Error())
+			return
+		}
+
+		fmt.Fprintf(statusView, "%s ", "Request copied to clipboard as curl")
+	}
+
 	responseView.SetDoneFunc(func(key tcell.Key) {
 		if key == tcell.KeyTab {
 			app.SetRoot(flex, true).SetFocus(requestForm)
@@ -382,7 +627,9 @@ func Layout(version string) {
 			"Headers",
 			"Authorization",
 			"Show Response Headers",
+			"Filter Response",
 			"Save Response in File",
+			"Copy as curl",
 			"Return",
 			"Quit From App",
 		}).
@@ -392,8 +639,7 @@ func Layout(version string) {
 				app.SetRoot(flex, true).SetFocus(requestForm)
 
 			case "Send Request":
-				send()
-				app.SetRoot(flex, true).SetFocus(requestForm)
+				withVault(requestForm, send)
 
 			case "Body":
 				app.SetRoot(bodyEditor, true).SetFocus(bodyEditor)
@@ -407,6 +653,9 @@ func Layout(version string) {
 			case "Show Response Headers":
 				app.SetRoot(headers, true).SetFocus(headers)
 
+			case "Filter Response":
+				app.SetRoot(flex, true).SetFocus(filterField)
+
 			case "Save Response in File":
 				data := []byte(respone)
 
@@ -419,6 +668,9 @@ func Layout(version string) {
 					app.SetRoot(flex, true).SetFocus(requestForm)
 				})
 
+			case "Copy as curl":
+				withVault(requestForm, copyAsCurl)
+
 			case "Return":
 				app.SetRoot(flex, true).SetFocus(requestForm)
 
@@ -427,52 +679,142 @@ func Layout(version string) {
 			}
 		})
 
-	authForm.AddDropDown("Authentication Type", []string{"none", "basic auth", "bearer token"}, 0, func(option string, optionIndex int) {
-		tokenIndex := authForm.GetFormItemIndex("Token")
-		usernameIndex := authForm.GetFormItemIndex("Username")
-		passwordIndex := authForm.GetFormItemIndex("Password")
-
-		if option == "basic auth" {
-			if tokenIndex != -1 {
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Token"))
-			} else if usernameIndex != -1 && passwordIndex != -1 {
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Username"))
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Password"))
-			}
+	// the dropdown lists the registered auth providers
+	authOptions := []string{"none"}
 
-			authForm.AddFormItem(username)
-			authForm.AddFormItem(password)
+	for _, provider := range authProviders {
+		authOptions = append(authOptions, provider.Label())
+	}
 
-			authType = "basic"
-		} else if option == "bearer token" {
-			if usernameIndex != -1 && passwordIndex != -1 {
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Username"))
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Password"))
-			} else if tokenIndex != -1 {
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Token"))
+	authTypeOption := func(name string) int {
+		for i, provider := range authProviders {
+			if provider.Name() == name {
+				return i + 1
 			}
+		}
 
-			authForm.AddFormItem(token)
+		return 0
+	}
 
-			authType = "bearer"
-		} else {
-			if usernameIndex != -1 && passwordIndex != -1 {
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Username"))
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Password"))
+	authForm.AddDropDown("Authentication Type", authOptions, 0, func(option string, optionIndex int) {
+		for _, inputs := range authInputs {
+			for _, input := range inputs {
+				if index := authForm.GetFormItemIndex(input.GetLabel()); index != -1 {
+					authForm.RemoveFormItem(index)
+				}
 			}
+		}
+
+		if optionIndex > 0 {
+			authType = authProviders[optionIndex-1].Name()
 
-			if tokenIndex != -1 {
-				authForm.RemoveFormItem(authForm.GetFormItemIndex("Token"))
+			for _, input := range authInputs[authType] {
+				authForm.AddFormItem(input)
+			}
+		} else {
+			for _, provider := range authProviders {
+				for i, field := range provider.Fields() {
+					setInputValue(field, authInputs[provider.Name()][i], "")
+				}
 			}
 
-			token.SetText("")
-			username.SetText("")
-			password.SetText("")
+			authType = ""
 		}
 	})
 
+	// jwt view, decodes the bearer token and verifies it with a secret, a public key or a JWKS file
+	jwtView := tview.NewTextView().
+		SetScrollable(true).
+		SetChangedFunc(func() {
+			app.Draw()
+		})
+
+	jwtForm := tview.NewForm()
+
+	jwtSecret := tview.NewInputField().
+		SetLabel("HS256 Secret").
+		SetMaskCharacter('*').
+		SetFieldWidth(20)
+
+	jwtKeyFile := tview.NewInputField().
+		SetLabel("Public Key File").
+		SetFieldWidth(20)
+
+	jwtJWKSFile := tview.NewInputField().
+		SetLabel("JWKS File").
+		SetFieldWidth(20)
+
+	jwtAudience := tview.NewInputField().
+		SetLabel("Audience").
+		SetFieldWidth(20)
+
+	decodeJWT := func() {
+		jwtView.Clear()
+
+		raw, err := tools.ResolveValue(currentAuth().TokenAuth)
+
+		if err != nil {
+			fmt.Fprintf(jwtView, "%s\n", err.Error())
+			return
+		}
+
+		decoded, err := jwt.Decode(raw)
+
+		if err != nil {
+			fmt.Fprintf(jwtView, "%s\n\nSelect \"bearer token\" and enter a JWT in the Token field.", err.Error())
+			return
+		}
+
+		now := time.Now()
+
+		fmt.Fprintf(jwtView, "HEADER\n%s\n\nCLAIMS\n%s\n", decoded.HeaderJSON(), decoded.ClaimsJSON())
+
+		if times := decoded.Times(now); len(times) > 0 {
+			fmt.Fprintf(jwtView, "\n%s\n", strings.Join(times, "\n"))
+		}
+
+		if warnings := decoded.Warnings(now, jwtAudience.GetText()); len(warnings) > 0 {
+			fmt.Fprintf(jwtView, "\n! %s\n", strings.Join(warnings, "\n! "))
+		}
+
+		key := jwt.Key{Secret: jwtSecret.GetText(), File: jwtKeyFile.GetText(), JWKS: jwtJWKSFile.GetText()}
+
+		if key.Empty() {
+			fmt.Fprintf(jwtView, "\nThe signature isn't verified, enter a secret, a public key or a JWKS file.")
+		} else if err := decoded.Verify(key); err != nil {
+			fmt.Fprintf(jwtView, "\nX %s", err.Error())
+		} else {
+			fmt.Fprintf(jwtView, "\n✓ the %s signature is valid", decoded.Algorithm())
+		}
+
+		jwtView.ScrollToBeginning()
+	}
+
+	jwtForm.AddFormItem(jwtSecret).
+		AddFormItem(jwtKeyFile).
+		AddFormItem(jwtJWKSFile).
+		AddFormItem(jwtAudience).
+		AddButton("Decode", decodeJWT).
+		AddButton("Back", func() {
+			app.SetRoot(flex, true).SetFocus(authForm)
+		}).
+		SetCancelFunc(func() {
+			app.SetRoot(flex, true).SetFocus(authForm)
+		})
+
+	jwtForm.SetBorder(true).SetTitle("Verify JWT").SetTitleAlign(tview.AlignCenter)
+	jwtView.SetBorder(true).SetTitle("JWT").SetTitleAlign(tview.AlignCenter)
+
+	jwtFlex := tview.NewFlex().
+		AddItem(jwtForm, 45, 1, true).
+		AddItem(jwtView, 0, 2, false)
+
 	authForm.AddButton("Headers", func() {
 		app.SetRoot(flex, true).SetFocus(headersForm)
+	}).AddButton("Decode JWT", func() {
+		decodeJWT()
+
+		app.SetRoot(jwtFlex, true).SetFocus(jwtForm)
 	}).AddButton("Request", func() {
 		app.SetRoot(flex, true).SetFocus(requestForm)
 	})
@@ -483,11 +825,65 @@ func Layout(version string) {
 		}
 	})
 
+	if initial != nil {
+		for i, option := range []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"} {
+			if option == initial.Method {
+				requestMethods.SetCurrentOption(i)
+			}
+		}
+
+		urlField.SetText(initial.URL)
+
+		if initial.ContentType != "" {
+			index := -1
+
+			for i, option := range contentTypes {
+				if option == initial.ContentType {
+					index = i
+				}
+			}
+
+			if index == -1 {
+				contentType.AddOption(initial.ContentType, nil)
+				index = len(contentTypes)
+			}
+
+			contentType.SetCurrentOption(index)
+		}
+
+		var keys []string
+
+		for key := range initial.Headers {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			headersForm.AddInputField(key, initial.Headers[key], 20, nil, nil)
+
+			headersCount++
+		}
+
+		authTypes := authForm.GetFormItemByLabel("Authentication Type").(*tview.DropDown)
+
+		if initial.AuthType != nil {
+			if provider, ok := api.LookupAuthProvider(initial.AuthType.Type); ok {
+				authTypes.SetCurrentOption(authTypeOption(provider.Name()))
+
+				for i, field := range provider.Fields() {
+					setInputValue(field, authInputs[provider.Name()][i], *field.Value(initial.AuthType))
+				}
+			}
+		}
+	}
+
 	// set borders
 	authForm.SetBorder(true)
 	headersForm.SetBorder(true)
 	requestForm.SetBorder(true)
 	responseView.SetBorder(true)
+	filterField.SetBorder(true)
 	statusView.SetBorder(true)
 
 	// set titles
@@ -495,8 +891,13 @@ func Layout(version string) {
 	headersForm.SetTitle("Headers").SetTitleAlign(tview.AlignCenter)
 	requestForm.SetTitle("Request Form").SetTitleAlign(tview.AlignCenter)
 	responseView.SetTitle("Response").SetTitleAlign(tview.AlignCenter)
+	filterField.SetTitle("Filter").SetTitleAlign(tview.AlignCenter)
 	statusView.SetTitle("Status").SetTitleAlign(tview.AlignCenter)
 
+	if env != nil {
+		statusView.SetTitle("Status (env: " + env.Name + ")")
+	}
+
 	newReleaseModal := tview.NewModal()
 
 	enableMouse := gjson.Get(tools.SettingsContent(), "rs_settings.enable_mouse").Bool()
@@ -505,8 +906,14 @@ func Layout(version string) {
 		app.EnableMouse(true)
 	}
 
-	if version != api.GetLatest() && gjson.Get(tools.SettingsContent(), "rs_settings.show_update").Bool() != false {
-		newReleaseModal.SetText("There's a new version of resto is avalaible: " + version + " → " + api.GetLatest()).
+	latestVersion := ""
+
+	if gjson.Get(tools.SettingsContent(), "rs_settings.show_update").Bool() {
+		latestVersion = api.GetLatest()
+	}
+
+	if latestVersion != "" && version != latestVersion {
+		newReleaseModal.SetText("There's a new version of resto is avalaible: " + version + " → " + latestVersion).
 			AddButtons([]string{"How to Update ?", "Don't show again", "Cancel"}).
 			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 				if buttonLabel == "How to Update ?" {
@@ -0,0 +1,574 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/abdfnx/resto/core/api"
+	"github.com/abdfnx/resto/core/environment"
+	"github.com/abdfnx/resto/core/expect"
+	"github.com/abdfnx/resto/core/restofile"
+	"github.com/abdfnx/resto/validation"
+)
+
+// The severities of the diagnostics
+const (
+	Error   = "error"
+	Warning = "warning"
+)
+
+// Diagnostic is a problem of a Restofile, Rule names the check that found it, like unknown-key
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// Position returns file:line:column, or just the file for the problems of the whole file
+func (d Diagnostic) Position() string {
+	if d.Line == 0 {
+		return d.File
+	}
+
+	return fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", d.Position(), d.Severity, d.Message, d.Rule)
+}
+
+var (
+	// the headers that hold credentials
+	secretHeaders = []string{"authorization", "proxy-authorization", "cookie", "x-api-key", "api-key", "x-auth-token"}
+
+	// the variables that look like credentials
+	secretVar = regexp.MustCompile(`(?i)(token|secret|password|passwd|api[-_]?key)`)
+
+	methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}
+
+	// envName matches the characters of a variable name that can't be in the name of an env variable
+	envName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+type linter struct {
+	file        *restofile.File
+	envs        environment.Environments
+	env         string
+	diagnostics []Diagnostic
+}
+
+// Syntax returns the diagnostic of an error of restofile.Parse
+func Syntax(path string, err error) Diagnostic {
+	return fromError(path, "syntax", err)
+}
+
+// Include returns the diagnostic of an error of restofile.Resolve, like an include cycle or a missing file
+func Include(path string, err error) Diagnostic {
+	return fromError(path, "include", err)
+}
+
+func fromError(path, rule string, err error) Diagnostic {
+	var parseErr *restofile.Error
+
+	if errors.As(err, &parseErr) {
+		// the error can be in an included file
+		if parseErr.Pos.File != "" {
+			path = parseErr.Pos.File
+		}
+
+		return Diagnostic{File: path, Line: parseErr.Pos.Line, Column: parseErr.Pos.Column, Severity: Error, Rule: rule, Message: parseErr.Msg}
+	}
+
+	return Diagnostic{File: path, Severity: Error, Rule: rule, Message: err.Error()}
+}
+
+// Check returns the problems of a Restofile sorted by position: unknown blocks and keys, requests without a method,
+// a url or a body, bodies with both content and readFrom, variables in hook commands, invalid urls and expect blocks, undefined variables and credentials written in plain text.
+// envs are the environments of the environment file next to it, env is the one the variables are checked with,
+// when it's empty a variable of any environment is defined
+func Check(file *restofile.File, envs environment.Environments, env string) []Diagnostic {
+	l := &linter{file: file, envs: envs, env: env}
+
+	l.checkSchema("", &restofile.Block{Body: file.Body})
+	l.checkRequests()
+	l.checkBodies()
+	l.checkHooks()
+	l.checkSecrets()
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		a, b := l.diagnostics[i], l.diagnostics[j]
+
+		if a.File != b.File {
+			return a.File == l.file.Path || (b.File != l.file.Path && a.File < b.File)
+		}
+
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+
+	return l.diagnostics
+}
+
+func (l *linter) report(pos restofile.Pos, severity, rule, format string, args ...interface{}) {
+	file := l.file.Path
+
+	// the nodes of included files have their own file
+	if pos.File != "" {
+		file = pos.File
+	}
+
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		File:     file,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// checkSchema reports the entries and blocks of block that resto doesn't read, name is the name of block in Schemas
+func (l *linter) checkSchema(name string, block *restofile.Block) {
+	schema := restofile.Schemas[name]
+
+	where := "the top level"
+
+	if name != "" {
+		where = "the " + name + " block"
+	}
+
+	for _, entry := range block.Entries() {
+		if !schema.HasKey(entry.Key) {
+			l.report(entry.Pos, Error, "unknown-key", "unknown key %q in %s%s", entry.Key, where, suggest(entry.Key, schema.Keys))
+		}
+	}
+
+	for _, child := range block.Blocks("") {
+		if schema.HasBlock(child.Name) {
+			l.checkSchema(child.Name, child)
+
+			continue
+		}
+
+		if len(schema.Blocks) == 0 {
+			l.report(child.Pos, Error, "unknown-block", "%s can't hold a %s block", where, child.Name)
+		} else {
+			l.report(child.Pos, Error, "unknown-block", "unknown block %q in %s%s", child.Name, where, suggest(child.Name, schema.Blocks))
+		}
+	}
+}
+
+// suggest returns a hint for the known name that is the closest to a misspelled one
+func suggest(name string, known []string) string {
+	best, bestDistance := "", 3
+
+	for _, k := range known {
+		if strings.EqualFold(k, name) {
+			return fmt.Sprintf(", did you mean %q?", k)
+		}
+
+		if d := distance(strings.ToLower(name), strings.ToLower(k)); d < bestDistance {
+			best, bestDistance = k, d
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// distance is the Levenshtein distance of a and b
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+func (l *linter) checkRequests() {
+	requests := l.file.Blocks("request")
+	names := map[string]bool{}
+	captured := map[string]bool{}
+
+	for _, request := range requests {
+		if name := request.Label(); names[name] {
+			if name == "" {
+				l.report(request.Pos, Error, "duplicate-request", "there's more than one request without a name, name them like request \"login\" { ... }")
+			} else {
+				l.report(request.Pos, Error, "duplicate-request", "there's more than one request called %q", name)
+			}
+		} else {
+			names[name] = true
+		}
+
+		l.checkRequest(request)
+
+		defined := l.defined(request)
+
+		for name := range captured {
+			defined[name] = true
+		}
+
+		blocks := append([]*restofile.Block{request}, requestBlocks(request)...)
+
+		// a before hook can print any variable
+		if l.hasBeforeHook(request) {
+			blocks = nil
+		}
+
+		for _, b := range blocks {
+			l.checkVars(b, defined)
+		}
+
+		for _, entry := range request.Block("capture").Entries() {
+			captured[entry.Key] = true
+		}
+	}
+
+	// the shared blocks can use the variables of any request
+	defined := l.defined(nil)
+
+	for _, request := range requests {
+		for key := range l.defined(request) {
+			defined[key] = true
+		}
+	}
+
+	for name := range captured {
+		defined[name] = true
+	}
+
+	for _, name := range []string{"headers", "query", "auth", "body"} {
+		// the shared blocks can use the variables of the before hooks
+		if l.hasBeforeHook(nil) {
+			continue
+		}
+
+		for _, b := range l.file.Blocks(name) {
+			l.checkVars(b, defined)
+		}
+	}
+}
+
+// hasBeforeHook reports whether request has a before hook, its own or the one of the top-level hooks block.
+// When request is nil, it reports whether any request has one
+func (l *linter) hasBeforeHook(request *restofile.Block) bool {
+	if request == nil {
+		for _, r := range l.file.Blocks("request") {
+			if l.hasBeforeHook(r) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if hooks := request.Block("hooks"); hooks != nil {
+		return hooks.Entry("before") != nil
+	}
+
+	return l.file.Block("hooks").Entry("before") != nil
+}
+
+// requestBlocks returns the blocks of a request whose values are interpolated
+func requestBlocks(request *restofile.Block) []*restofile.Block {
+	var blocks []*restofile.Block
+
+	for _, name := range []string{"headers", "query", "auth", "body", "expect"} {
+		blocks = append(blocks, request.Blocks(name)...)
+	}
+
+	return blocks
+}
+
+func (l *linter) checkRequest(request *restofile.Block) {
+	method := strings.ToUpper(request.Value("method"))
+	url := request.Entry("url")
+
+	if request.Entry("method") == nil {
+		l.report(request.Pos, Error, "missing-key", "the request block needs a method")
+	} else if len(environment.References(method)) == 0 && index(methods, method) == -1 {
+		l.report(request.Entry("method").Values[0].Pos, Error, "invalid-method", "unknown method %q, it must be %s", method, strings.Join(methods, ", "))
+	}
+
+	if url == nil {
+		l.report(request.Pos, Error, "missing-key", "the request block needs a url")
+	} else if value, ok := l.expand(request, url.Value()); ok && !strings.HasPrefix(value, "env:") && !strings.HasPrefix(value, "secret:") {
+		if _, err := validation.CheckURL(value); err != nil {
+			l.report(url.Values[0].Pos, Error, "invalid-url", "invalid url %q, %s", value, strings.ToLower(strings.TrimSpace(err.Error())))
+		}
+	}
+
+	if method == "POST" || method == "PUT" || method == "PATCH" {
+		body := request.Block("body")
+
+		if body == nil {
+			body = l.file.Block("body")
+		}
+
+		if body == nil {
+			l.report(request.Pos, Warning, "missing-body", "the %s request has no body, add a body block with content, readFrom or openBodyEditor", method)
+		} else if body.Entry("content") == nil && body.Entry("readFrom") == nil && body.Entry("openBodyEditor") == nil {
+			l.report(body.Pos, Error, "missing-body", "the body block needs content, readFrom or openBodyEditor")
+		}
+	}
+
+	if block := request.Block("expect"); block != nil {
+		if _, err := expect.Parse(block, nil); err != nil {
+			var parseErr *restofile.Error
+
+			if errors.As(err, &parseErr) {
+				l.report(parseErr.Pos, Error, "invalid-expect", "%s", parseErr.Msg)
+			}
+		}
+	}
+}
+
+// expand replaces the variables of s with the values of the vars blocks and the environment, ok is false when one of them
+// has no single value, like the variables of captures or of several environments
+func (l *linter) expand(request *restofile.Block, s string) (string, bool) {
+	values := map[string]string{}
+
+	blocks := append(l.file.Blocks("vars"), request.Blocks("vars")...)
+
+	if l.env != "" {
+		for _, b := range l.file.Blocks("env") {
+			if b.Label() == l.env {
+				blocks = append(blocks, b)
+			}
+		}
+	}
+
+	for _, b := range blocks {
+		for _, entry := range b.Entries() {
+			values[entry.Key] = entry.Value()
+		}
+	}
+
+	for key, value := range l.envs[l.env] {
+		values[key] = value
+	}
+
+	for _, name := range environment.References(s) {
+		if _, ok := values[name]; !ok {
+			return s, false
+		}
+
+		// a variable that's defined differently by the environments has no single value
+		if l.env == "" && l.inEnvironments(name) {
+			return s, false
+		}
+	}
+
+	out, err := environment.Interpolate(s, values)
+
+	return out, err == nil
+}
+
+func (l *linter) inEnvironments(name string) bool {
+	for _, vars := range l.envs {
+		if _, ok := vars[name]; ok {
+			return true
+		}
+	}
+
+	for _, b := range l.file.Blocks("env") {
+		if b.Entry(name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defined returns the variables of the vars blocks of the file and of request, and of the environments
+func (l *linter) defined(request *restofile.Block) map[string]bool {
+	defined := map[string]bool{}
+
+	blocks := append(l.file.Blocks("vars"), request.Blocks("vars")...)
+
+	for _, b := range l.file.Blocks("env") {
+		if l.env == "" || b.Label() == l.env {
+			blocks = append(blocks, b)
+		}
+	}
+
+	for _, b := range blocks {
+		for _, entry := range b.Entries() {
+			defined[entry.Key] = true
+		}
+	}
+
+	for name, vars := range l.envs {
+		if l.env == "" || name == l.env {
+			for key := range vars {
+				defined[key] = true
+			}
+		}
+	}
+
+	return defined
+}
+
+// checkVars reports the variables of the entries of block that aren't defined
+func (l *linter) checkVars(block *restofile.Block, defined map[string]bool) {
+	for _, entry := range block.Entries() {
+		for _, value := range entry.Values {
+			// the variables of """ strings aren't interpolated
+			if value.Raw {
+				continue
+			}
+
+			for _, name := range environment.References(value.Text) {
+				if defined[name] {
+					continue
+				}
+
+				if l.env == "" {
+					l.report(value.Pos, Error, "undefined-variable", "undefined variable %q, define it in a vars block or an environment, or capture it in an earlier request", name)
+				} else {
+					l.report(value.Pos, Error, "undefined-variable", "undefined variable %q, it's not in the vars blocks, the captured values or the %s environment", name, l.env)
+				}
+			}
+		}
+	}
+}
+
+// checkBodies reports the body blocks that have both content and readFrom, since only one of them can be sent
+func (l *linter) checkBodies() {
+	bodies := l.file.Blocks("body")
+
+	for _, request := range l.file.Blocks("request") {
+		bodies = append(bodies, request.Blocks("body")...)
+	}
+
+	for _, body := range bodies {
+		if body.Entry("content") != nil && body.Entry("readFrom") != nil {
+			l.report(body.Entry("readFrom").Pos, Error, "conflicting-body", "the body block has content and readFrom, keep one of them")
+		}
+	}
+}
+
+// checkHooks reports the {{variables}} of hook commands, the commands aren't templated and get the variables as env variables
+func (l *linter) checkHooks() {
+	hooks := l.file.Blocks("hooks")
+
+	for _, request := range l.file.Blocks("request") {
+		hooks = append(hooks, request.Blocks("hooks")...)
+	}
+
+	for _, block := range hooks {
+		for _, key := range []string{"before", "after"} {
+			entry := block.Entry(key)
+
+			if entry == nil || entry.Values[0].Raw {
+				continue
+			}
+
+			for _, name := range environment.References(entry.Value()) {
+				l.report(entry.Values[0].Pos, Warning, "hook-variable", "hook commands aren't templated, use $RESTO_VAR_%s instead of {{%s}}", envName.ReplaceAllString(name, "_"), name)
+			}
+		}
+	}
+}
+
+// checkSecrets reports the credentials of auth, headers, vars and env blocks that are written in the file
+func (l *linter) checkSecrets() {
+	var walk func(block *restofile.Block)
+
+	walk = func(block *restofile.Block) {
+		for _, entry := range block.Entries() {
+			secret := false
+
+			switch block.Name {
+			case "auth":
+				secret = secretAuthKey(entry.Key)
+			case "headers":
+				secret = index(secretHeaders, strings.ToLower(entry.Key)) != -1
+			case "vars", "env":
+				secret = secretVar.MatchString(entry.Key)
+			}
+
+			if secret && plaintext(entry.Value()) {
+				l.report(entry.Values[0].Pos, Warning, "plaintext-secret", "%q is written in plain text, use env:NAME, secret:NAME or a variable of %s", entry.Key, environment.FileName)
+			}
+		}
+
+		for _, child := range block.Blocks("") {
+			walk(child)
+		}
+	}
+
+	walk(&restofile.Block{Body: l.file.Body})
+}
+
+// secretAuthKey reports whether key is a secret field of an auth provider
+func secretAuthKey(key string) bool {
+	for _, provider := range api.AuthProviders() {
+		for _, field := range provider.Fields() {
+			if field.Key == key && field.Secret {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// plaintext reports whether a value is written as it is, instead of being read from an env variable, the vault or a variable
+func plaintext(value string) bool {
+	return value != "" && !strings.HasPrefix(value, "env:") && !strings.HasPrefix(value, "secret:") && len(environment.References(value)) == 0
+}
+
+func index(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,241 @@
+package lint
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/core/environment"
+	"github.com/abdfnx/resto/core/restofile"
+)
+
+const restofileSrc = `vars {
+   baseUrl "https://api.example.com"
+   apiToken "s3cr3t"
+}
+
+headers {
+   Authorization "Bearer {{token}}"
+   Cookie "session=abc"
+}
+
+request "login" {
+   method "POST"
+   url "{{baseUrl}}/login"
+   contentTyp "json"
+
+   capture {
+      token "json:token"
+   }
+
+   expect {
+      status 200
+      json "token" is "string"
+   }
+}
+
+request "me" {
+   method "GET"
+   url "{{baseUrl}}/me?tenant={{tenant}}"
+
+   auth {
+      type "basic"
+      username "alice"
+      password "P@ss"
+   }
+
+   heders {
+      Accept "application/json"
+   }
+}
+
+request "upload" {
+   method "PUT"
+   url "ftp://files.example.com/upload"
+
+   body {
+      readFrm "upload.bin"
+   }
+}
+
+request "me" {
+   method "FETCH"
+   url "{{host}}/me"
+}
+`
+
+func TestCheck(t *testing.T) {
+	file, err := restofile.Parse("Restofile", []byte(restofileSrc))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envs := environment.Environments{"staging": {"host": "https://staging.example.com"}}
+
+	var got []string
+
+	for _, d := range Check(file, envs, "") {
+		got = append(got, d.String())
+	}
+
+	expected := []string{
+		`Restofile:3:13: warning: "apiToken" is written in plain text, use env:NAME, secret:NAME or a variable of resto.env.json (plaintext-secret)`,
+		`Restofile:8:11: warning: "Cookie" is written in plain text, use env:NAME, secret:NAME or a variable of resto.env.json (plaintext-secret)`,
+		`Restofile:11:1: warning: the POST request has no body, add a body block with content, readFrom or openBodyEditor (missing-body)`,
+		`Restofile:14:4: error: unknown key "contentTyp" in the request block, did you mean "contentType"? (unknown-key)`,
+		`Restofile:22:7: error: unknown json assertion "is", it must be equals, contains, matches, exists, type (invalid-expect)`,
+		`Restofile:28:8: error: undefined variable "tenant", define it in a vars block or an environment, or capture it in an earlier request (undefined-variable)`,
+		`Restofile:33:16: warning: "password" is written in plain text, use env:NAME, secret:NAME or a variable of resto.env.json (plaintext-secret)`,
+		`Restofile:36:4: error: unknown block "heders" in the request block, did you mean "headers"? (unknown-block)`,
+		`Restofile:43:8: error: invalid url "ftp://files.example.com/upload", url missing protocol or contains invalid protocol (invalid-url)`,
+		`Restofile:45:4: error: the body block needs content, readFrom or openBodyEditor (missing-body)`,
+		`Restofile:46:7: error: unknown key "readFrm" in the body block, did you mean "readFrom"? (unknown-key)`,
+		`Restofile:50:1: error: there's more than one request called "me" (duplicate-request)`,
+		`Restofile:51:11: error: unknown method "FETCH", it must be GET, HEAD, POST, PUT, PATCH, DELETE (invalid-method)`,
+	}
+
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestCheckEnv(t *testing.T) {
+	src := `env "local" { baseUrl "http://localhost:8080" }
+request {
+   method "GET"
+   url "{{baseUrl}}/{{version}}"
+}
+`
+
+	file, err := restofile.Parse("Restofile", []byte(src))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envs := environment.Environments{"staging": {"baseUrl": "staging.example.com", "version": "v2"}}
+
+	if diagnostics := Check(file, envs, ""); len(diagnostics) != 0 {
+		t.Errorf("expected the variables of all the environments to be defined, got %v", diagnostics)
+	}
+
+	diagnostics := Check(file, envs, "local")
+
+	if len(diagnostics) != 1 || diagnostics[0].Rule != "undefined-variable" || !strings.Contains(diagnostics[0].Message, `"version"`) {
+		t.Errorf("expected version to be undefined in local, got %v", diagnostics)
+	}
+
+	diagnostics = Check(file, envs, "staging")
+
+	if len(diagnostics) != 1 || diagnostics[0].Rule != "invalid-url" {
+		t.Errorf("expected the url of staging to be invalid, got %v", diagnostics)
+	}
+}
+
+func TestCheckInlineBodies(t *testing.T) {
+	src := "request {\n   method POST\n   url \"https://x\"\n\n   body {\n      content \"\"\"\n      {\"template\": \"{{name}}\"}\n      \"\"\"\n   }\n}\n\nrequest \"b\" {\n   method POST\n   url \"https://x\"\n\n   body {\n      content <<EOF\n      {{name}}\n      EOF\n   }\n}\n"
+
+	file, err := restofile.Parse("Restofile", []byte(src))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics := Check(file, nil, "")
+
+	if len(diagnostics) != 1 || diagnostics[0].Rule != "undefined-variable" || diagnostics[0].Line != 17 {
+		t.Errorf("expected only the variable of the heredoc to be undefined, got %v", diagnostics)
+	}
+}
+
+func TestCheckConflictingBody(t *testing.T) {
+	src := "body {\n   content \"{}\"\n   readFrom \"user.json\"\n}\n\nrequest {\n   method POST\n   url \"https://x\"\n}\n"
+
+	file, err := restofile.Parse("Restofile", []byte(src))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics := Check(file, nil, "")
+
+	if len(diagnostics) != 1 || diagnostics[0].Rule != "conflicting-body" || diagnostics[0].Line != 3 {
+		t.Errorf("expected the body to be reported once, got %v", diagnostics)
+	}
+}
+
+func TestCheckHooks(t *testing.T) {
+	src := "request \"signed\" {\n   method GET\n   url \"https://x/{{nonce}}\"\n\n   hooks {\n      before \"./nonce.sh {{user}}\"\n      after \"gunzip\"\n      timeout \"1s\"\n   }\n}\n\nrequest \"plain\" {\n   method GET\n   url \"https://x/{{nonce}}\"\n}\n"
+
+	file, err := restofile.Parse("Restofile", []byte(src))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+
+	for _, d := range Check(file, nil, "") {
+		got = append(got, d.String())
+	}
+
+	expected := []string{
+		`Restofile:6:14: warning: hook commands aren't templated, use $RESTO_VAR_user instead of {{user}} (hook-variable)`,
+		`Restofile:8:7: error: unknown key "timeout" in the hooks block (unknown-key)`,
+		`Restofile:14:8: error: undefined variable "nonce", define it in a vars block or an environment, or capture it in an earlier request (undefined-variable)`,
+	}
+
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestSyntax(t *testing.T) {
+	_, err := restofile.Parse("Restofile", []byte("request {\n   url \"https://x\n}"))
+
+	d := Syntax("Restofile", err)
+
+	if d.Line != 2 || d.Column != 8 || d.Rule != "syntax" || d.Severity != Error {
+		t.Errorf("expected a syntax error at 2:8, got %+v", d)
+	}
+
+	if d := Syntax("Restofile", errors.New("permission denied")); d.Line != 0 || d.Message != "permission denied" {
+		t.Errorf("expected a diagnostic without a position, got %+v", d)
+	}
+}
+
+func TestCheckIncludes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Restofile")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "auth.resto"), []byte("auth {\n   type \"bearer\"\n   tokn \"env:TOKEN\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("include \"auth.resto\"\n\nrequest \"me\" {\n   url \"https://x\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := restofile.Load(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics := Check(file, nil, "")
+
+	if len(diagnostics) != 2 || diagnostics[0].Rule != "missing-key" || diagnostics[0].File != path {
+		t.Fatalf("expected the problems of the Restofile first, got %v", diagnostics)
+	}
+
+	if d := diagnostics[1]; d.Rule != "unknown-key" || d.File != filepath.Join(dir, "auth.resto") || d.Line != 3 {
+		t.Errorf("expected the unknown key to be reported in auth.resto, got %+v", d)
+	}
+
+	if d := Include(path, restofile.Errorf(restofile.Pos{File: "shared.resto", Line: 1, Column: 9}, "include cycle")); d.File != "shared.resto" || d.Rule != "include" {
+		t.Errorf("expected the include error to be reported in shared.resto, got %+v", d)
+	}
+}
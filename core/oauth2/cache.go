@@ -0,0 +1,174 @@
+package oauth2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/vault"
+	"github.com/abdfnx/resto/tools"
+)
+
+// tokenStore keeps the cached tokens, it's the vault outside of tests
+type tokenStore interface {
+	Get(name string) (string, bool)
+	Set(name, value string)
+	Remove(name string) bool
+	Save() error
+}
+
+// openStore returns the vault the tokens are cached in, or nil when there's no vault or no way to unlock it,
+// the tokens aren't cached then. useTempCache swaps it out
+var openStore = func() (tokenStore, error) {
+	if v := vault.Opened(); v != nil {
+		return v, nil
+	}
+
+	if !vault.Exists() || vault.Locked() {
+		return nil, nil
+	}
+
+	v, err := vault.Unlocked()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// legacyFile is where older versions cached the tokens in plaintext, they're moved to the vault
+var legacyFile = tools.OAuth2File
+
+// cacheKey identifies the tokens of a profile, or of an inline configuration
+func cacheKey(cfg *options.OAuth2) string {
+	if cfg.Profile != "" {
+		return cfg.Profile
+	}
+
+	return strings.Join([]string{
+		cfg.TokenURL,
+		cfg.ClientID,
+		strings.Join(Scopes(cfg.Scopes), " "),
+		cfg.Audience,
+	}, "|")
+}
+
+// secretName is the vault secret that keeps the token of key, like oauth2/github,
+// the key of an inline configuration is hashed since it has urls
+func secretName(key string) string {
+	if !strings.Contains(key, "|") {
+		return "oauth2/" + key
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return "oauth2/" + hex.EncodeToString(sum[:8])
+}
+
+// openCache opens the store of the tokens, after moving the plaintext cache of older versions into it
+func openCache() (tokenStore, error) {
+	store, err := openStore()
+
+	if err != nil || store == nil {
+		return nil, err
+	}
+
+	return store, migrate(store)
+}
+
+// migrate moves the tokens of the plaintext cache into store and removes the file
+func migrate(store tokenStore) error {
+	data, err := ioutil.ReadFile(legacyFile())
+
+	if err != nil {
+		return nil
+	}
+
+	legacy := map[string]*Token{}
+	json.Unmarshal(data, &legacy)
+
+	for key, token := range legacy {
+		if _, ok := store.Get(secretName(key)); ok {
+			continue
+		}
+
+		value, err := json.Marshal(token)
+
+		if err != nil {
+			return err
+		}
+
+		store.Set(secretName(key), string(value))
+	}
+
+	if len(legacy) > 0 {
+		if err := store.Save(); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(legacyFile())
+}
+
+// loadToken returns the cached token of key, or nil when there's none
+func loadToken(key string) (*Token, error) {
+	store, err := openCache()
+
+	if err != nil || store == nil {
+		return nil, err
+	}
+
+	value, ok := store.Get(secretName(key))
+
+	if !ok {
+		return nil, nil
+	}
+
+	token := &Token{}
+
+	// a token that can't be read is fetched again
+	if err := json.Unmarshal([]byte(value), token); err != nil {
+		return nil, nil
+	}
+
+	return token, nil
+}
+
+// saveToken caches token under key in the vault, without a vault it's only used by this run
+func saveToken(key string, token *Token) error {
+	store, err := openCache()
+
+	if err != nil || store == nil {
+		return err
+	}
+
+	value, err := json.Marshal(token)
+
+	if err != nil {
+		return err
+	}
+
+	store.Set(secretName(key), string(value))
+
+	return store.Save()
+}
+
+// Forget removes the cached token of key
+func Forget(key string) error {
+	store, err := openCache()
+
+	if err != nil || store == nil {
+		return err
+	}
+
+	if !store.Remove(secretName(key)) {
+		return nil
+	}
+
+	return store.Save()
+}
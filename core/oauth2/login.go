@@ -0,0 +1,228 @@
+package oauth2
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/core/vault"
+)
+
+// loginTimeout is how long Login waits for the browser to come back to the callback
+var loginTimeout = 5 * time.Minute
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// Login runs the authorization code flow with PKCE: it listens on a loopback callback,
+// sends the user to the authorize url with openBrowser and exchanges the returned code for a token
+func Login(cfg *options.OAuth2, openBrowser func(string) error, out io.Writer) (*Token, error) {
+	if err := LoadProfile(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.AuthURL == "" || cfg.TokenURL == "" {
+		return nil, fmt.Errorf("oauth2: an auth url and a token url are required to login")
+	}
+
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("oauth2: a client id is required to login")
+	}
+
+	// the refresh token is only useful if it's kept
+	if store, err := openCache(); err != nil {
+		return nil, err
+	} else if store == nil {
+		return nil, fmt.Errorf("oauth2: the token is kept in the vault, set %s or create it with `resto secret set`", vault.PassphraseEnv)
+	}
+
+	listenAddr := "127.0.0.1:0"
+	callbackPath := "/callback"
+
+	if cfg.RedirectURL != "" {
+		redirect, err := url.Parse(cfg.RedirectURL)
+
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: invalid redirect url: %s", err.Error())
+		}
+
+		listenAddr = redirect.Host
+		callbackPath = redirect.Path
+
+		// the browser comes back to / when the redirect url has no path
+		if callbackPath == "" {
+			callbackPath = "/"
+		}
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: can't listen for the callback: %s", err.Error())
+	}
+
+	defer listener.Close()
+
+	redirectURI := cfg.RedirectURL
+
+	if redirectURI == "" {
+		redirectURI = "http://" + listener.Addr().String() + callbackPath
+	}
+
+	verifier, err := randomString(32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := randomString(16)
+
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := authorizeURL(cfg, redirectURI, state, codeChallenge(verifier))
+
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan callbackResult, 1)
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != callbackPath {
+				http.NotFound(w, r)
+				return
+			}
+
+			result := readCallback(r.URL.Query(), state)
+
+			if result.err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "<h3>Login failed</h3><p>%s</p>", html.EscapeString(result.err.Error()))
+			} else {
+				fmt.Fprint(w, "<h3>Logged in</h3><p>You can close this window and go back to resto.</p>")
+			}
+
+			select {
+			case results <- result:
+			default:
+			}
+		}),
+	}
+
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Fprintf(out, "Open this URL in your browser to login:\n\n  %s\n\n", authURL)
+
+	if openBrowser != nil {
+		openBrowser(authURL)
+	}
+
+	var result callbackResult
+
+	select {
+	case result = <-results:
+	case <-time.After(loginTimeout):
+		return nil, fmt.Errorf("oauth2: timed out waiting for the login callback")
+	}
+
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := requestToken(cfg, map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          result.code,
+		"redirect_uri":  redirectURI,
+		"code_verifier": verifier,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveToken(cacheKey(cfg), token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func authorizeURL(cfg *options.OAuth2, redirectURI, state, challenge string) (string, error) {
+	authURL, err := url.Parse(cfg.AuthURL)
+
+	if err != nil {
+		return "", fmt.Errorf("oauth2: invalid auth url: %s", err.Error())
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", challenge)
+	query.Set("code_challenge_method", "S256")
+
+	if scopes := Scopes(cfg.Scopes); len(scopes) > 0 {
+		query.Set("scope", strings.Join(scopes, " "))
+	}
+
+	if cfg.Audience != "" {
+		query.Set("audience", cfg.Audience)
+	}
+
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+func readCallback(query url.Values, state string) callbackResult {
+	if e := query.Get("error"); e != "" {
+		if description := query.Get("error_description"); description != "" {
+			e += ": " + description
+		}
+
+		return callbackResult{err: fmt.Errorf("oauth2: authorization failed: %s", e)}
+	}
+
+	if query.Get("state") != state {
+		return callbackResult{err: fmt.Errorf("oauth2: the callback state doesn't match the login request")}
+	}
+
+	if query.Get("code") == "" {
+		return callbackResult{err: fmt.Errorf("oauth2: the callback has no authorization code")}
+	}
+
+	return callbackResult{code: query.Get("code")}
+}
+
+// codeChallenge is the S256 PKCE challenge of verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
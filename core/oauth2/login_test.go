@@ -0,0 +1,168 @@
+package oauth2
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/abdfnx/resto/core/options"
+)
+
+func s256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// newAuthServer is a fake authorization server that logs the user in right away
+func newAuthServer(t *testing.T) *httptest.Server {
+	challenges := map[string]string{}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("client_id") != "cli" {
+			t.Errorf("unexpected authorize request: %s", r.URL.RawQuery)
+		}
+
+		challenges["code-1"] = query.Get("code_challenge")
+
+		redirect, _ := url.Parse(query.Get("redirect_uri"))
+		redirect.RawQuery = url.Values{"code": {"code-1"}, "state": {query.Get("state")}}.Encode()
+
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+
+		code := r.PostForm.Get("code")
+
+		if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != "cli" ||
+			challenges[code] == "" || s256(r.PostForm.Get("code_verifier")) != challenges[code] {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "user-token",
+			"refresh_token": "user-refresh",
+			"expires_in":    3600,
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// fakeBrowser follows the authorize url and the redirect to the callback, like the user's browser
+func fakeBrowser(t *testing.T) func(string) error {
+	return func(authURL string) error {
+		go func() {
+			res, err := http.Get(authURL)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				body, _ := ioutil.ReadAll(res.Body)
+				t.Errorf("callback failed: %s", body)
+			}
+		}()
+
+		return nil
+	}
+}
+
+func TestLogin(t *testing.T) {
+	useTempCache(t)
+	server := newAuthServer(t)
+
+	cfg := &options.OAuth2{
+		AuthURL:  server.URL + "/authorize",
+		TokenURL: server.URL + "/token",
+		ClientID: "cli",
+		Scopes:   "openid profile",
+	}
+
+	token, err := Login(cfg, fakeBrowser(t), ioutil.Discard)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "user-token" || token.RefreshToken != "user-refresh" {
+		t.Errorf("unexpected token: %+v", token)
+	}
+
+	accessToken, err := AccessToken(cfg)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if accessToken != "user-token" {
+		t.Errorf("expected the login token to be cached, got %q", accessToken)
+	}
+}
+
+func TestLoginRedirectWithoutPath(t *testing.T) {
+	useTempCache(t)
+	server := newAuthServer(t)
+
+	// a free port for the redirect url
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener.Close()
+
+	cfg := &options.OAuth2{
+		AuthURL:     server.URL + "/authorize",
+		TokenURL:    server.URL + "/token",
+		ClientID:    "cli",
+		RedirectURL: "http://" + listener.Addr().String(),
+	}
+
+	token, err := Login(cfg, fakeBrowser(t), ioutil.Discard)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "user-token" {
+		t.Errorf("unexpected token: %+v", token)
+	}
+}
+
+func TestLoginStateMismatch(t *testing.T) {
+	result := readCallback(url.Values{"code": {"abc"}, "state": {"other"}}, "state")
+
+	if result.err == nil {
+		t.Errorf("expected a state mismatch error")
+	}
+
+	result = readCallback(url.Values{"error": {"access_denied"}, "error_description": {"denied"}}, "state")
+
+	if result.err == nil || result.err.Error() != "oauth2: authorization failed: access_denied: denied" {
+		t.Errorf("unexpected error: %v", result.err)
+	}
+}
@@ -0,0 +1,181 @@
+package oauth2
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+)
+
+// expiryDelta refreshes tokens a bit before they really expire, to survive clock skew and slow requests
+const expiryDelta = 30 * time.Second
+
+var now = time.Now
+
+// Token is an OAuth2 token as cached in the vault
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// Valid reports whether the token can still be used
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+
+	return t.ExpiresAt.IsZero() || now().Add(expiryDelta).Before(t.ExpiresAt)
+}
+
+// AccessToken returns a valid access token for cfg, from the cache when possible,
+// otherwise it's refreshed with the refresh token or fetched with the client credentials
+func AccessToken(cfg *options.OAuth2) (string, error) {
+	if err := LoadProfile(cfg); err != nil {
+		return "", err
+	}
+
+	key := cacheKey(cfg)
+	cached, err := loadToken(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	if cached.Valid() {
+		return cached.AccessToken, nil
+	}
+
+	if cfg.TokenURL == "" {
+		return "", fmt.Errorf("oauth2: a token url is required")
+	}
+
+	refreshToken := cfg.RefreshToken
+
+	if cached != nil && cached.RefreshToken != "" {
+		refreshToken = cached.RefreshToken
+	}
+
+	var token *Token
+
+	if refreshToken != "" {
+		token, err = Refresh(cfg, refreshToken)
+	}
+
+	if token == nil && cfg.ClientID != "" && cfg.ClientSecret != "" {
+		token, err = ClientCredentials(cfg)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if token == nil && cfg.AuthURL != "" && cfg.Profile != "" {
+		return "", fmt.Errorf("oauth2: no token for profile %q, login with `resto auth login %s`", cfg.Profile, cfg.Profile)
+	}
+
+	if token == nil {
+		return "", fmt.Errorf("oauth2: a refresh token or a client id and secret are required to get a token")
+	}
+
+	if err := saveToken(key, token); err != nil {
+		return "", err
+	}
+
+	return token.AccessToken, nil
+}
+
+// CachedToken returns the cached access token of cfg while it's valid, it never refreshes or fetches one
+func CachedToken(cfg *options.OAuth2) (string, bool) {
+	token, err := loadToken(cacheKey(cfg))
+
+	if err != nil || !token.Valid() {
+		return "", false
+	}
+
+	return token.AccessToken, true
+}
+
+// ClientCredentials fetches a token with the client_credentials grant
+func ClientCredentials(cfg *options.OAuth2) (*Token, error) {
+	params := map[string]string{
+		"grant_type": "client_credentials",
+		"scope":      strings.Join(Scopes(cfg.Scopes), " "),
+		"audience":   cfg.Audience,
+	}
+
+	return requestToken(cfg, params)
+}
+
+// Refresh exchanges a refresh token for a new token, the old refresh token is kept if the server doesn't rotate it
+func Refresh(cfg *options.OAuth2, refreshToken string) (*Token, error) {
+	params := map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+		"scope":         strings.Join(Scopes(cfg.Scopes), " "),
+	}
+
+	token, err := requestToken(cfg, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+
+	return token, nil
+}
+
+// Scopes splits a space or comma separated list of scopes
+func Scopes(scopes string) []string {
+	return strings.FieldsFunc(scopes, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
+
+// LoadProfile fills the empty fields of cfg from the `oauth2.<profile>` settings,
+// then resolves the `env:` and `secret:` references of all of them
+func LoadProfile(cfg *options.OAuth2) error {
+	fields := map[string]*string{
+		"authUrl":      &cfg.AuthURL,
+		"redirectUrl":  &cfg.RedirectURL,
+		"tokenUrl":     &cfg.TokenURL,
+		"clientId":     &cfg.ClientID,
+		"clientSecret": &cfg.ClientSecret,
+		"scopes":       &cfg.Scopes,
+		"audience":     &cfg.Audience,
+		"refreshToken": &cfg.RefreshToken,
+	}
+
+	if cfg.Profile != "" {
+		profile := tools.Setting("oauth2." + cfg.Profile)
+
+		if !profile.Exists() {
+			return fmt.Errorf("oauth2: profile %q isn't defined in the `rs_settings.oauth2` settings", cfg.Profile)
+		}
+
+		for key, field := range fields {
+			if *field == "" {
+				*field = profile.Get(key).String()
+			}
+		}
+	}
+
+	for _, field := range fields {
+		value, err := tools.ResolveValue(*field)
+
+		if err != nil {
+			return err
+		}
+
+		*field = value
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdfnx/resto/core/options"
+	"github.com/abdfnx/resto/tools"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	grants   []string
+	basic    bool
+	rotate   bool
+	requests int
+}
+
+func newTokenServer(t *testing.T, acceptBasic bool) *tokenServer {
+	ts := &tokenServer{}
+
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.requests++
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+
+		id, secret, ok := r.BasicAuth()
+
+		if ok && !acceptBasic {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		if !ok {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+
+		ts.basic = ok
+
+		grant := r.PostForm.Get("grant_type")
+		ts.grants = append(ts.grants, grant)
+
+		if grant == "client_credentials" && (id != "app" || secret != "s3cret") {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		if grant == "refresh_token" && r.PostForm.Get("refresh_token") != "refresh-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		res := map[string]interface{}{
+			"access_token": grant + "-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"scope":        r.PostForm.Get("scope"),
+		}
+
+		if grant == "client_credentials" || ts.rotate {
+			res["refresh_token"] = "refresh-1"
+		}
+
+		json.NewEncoder(w).Encode(res)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+// memoryStore is a tokenStore that keeps the tokens in memory
+type memoryStore struct {
+	secrets map[string]string
+}
+
+func (m *memoryStore) Get(name string) (string, bool) {
+	value, ok := m.secrets[name]
+
+	return value, ok
+}
+
+func (m *memoryStore) Set(name, value string) {
+	m.secrets[name] = value
+}
+
+func (m *memoryStore) Remove(name string) bool {
+	_, ok := m.secrets[name]
+	delete(m.secrets, name)
+
+	return ok
+}
+
+func (m *memoryStore) Save() error {
+	return nil
+}
+
+// useTempCache caches the tokens in memory, and moves a plaintext cache from a temporary file
+func useTempCache(t *testing.T) (*memoryStore, string) {
+	store := &memoryStore{secrets: map[string]string{}}
+	path := filepath.Join(t.TempDir(), "oauth2.json")
+
+	previous := openStore
+
+	openStore = func() (tokenStore, error) { return store, nil }
+	legacyFile = func() string { return path }
+
+	t.Cleanup(func() {
+		openStore = previous
+		legacyFile = tools.OAuth2File
+		now = time.Now
+	})
+
+	return store, path
+}
+
+func TestClientCredentialsIsCached(t *testing.T) {
+	useTempCache(t)
+	server := newTokenServer(t, true)
+
+	cfg := &options.OAuth2{TokenURL: server.URL, ClientID: "app", ClientSecret: "s3cret", Scopes: "read,write"}
+
+	for i := 0; i < 2; i++ {
+		token, err := AccessToken(cfg)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if token != "client_credentials-token" {
+			t.Errorf("got token %q", token)
+		}
+	}
+
+	if server.requests != 1 {
+		t.Errorf("expected the cached token to be reused, got %d token requests", server.requests)
+	}
+
+	if !server.basic {
+		t.Errorf("expected the client to authenticate with basic auth")
+	}
+}
+
+func TestExpiredTokenIsRefreshed(t *testing.T) {
+	useTempCache(t)
+	server := newTokenServer(t, true)
+
+	cfg := &options.OAuth2{TokenURL: server.URL, ClientID: "app", ClientSecret: "s3cret"}
+
+	if _, err := AccessToken(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	token, err := AccessToken(cfg)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "refresh_token-token" {
+		t.Errorf("got token %q", token)
+	}
+
+	expected := []string{"client_credentials", "refresh_token"}
+
+	if len(server.grants) != 2 || server.grants[0] != expected[0] || server.grants[1] != expected[1] {
+		t.Errorf("expected grants %v, got %v", expected, server.grants)
+	}
+
+	if cached, _ := loadToken(cacheKey(cfg)); cached.RefreshToken != "refresh-1" {
+		t.Errorf("expected the refresh token to be kept, got %q", cached.RefreshToken)
+	}
+}
+
+func TestClientSecretInBody(t *testing.T) {
+	useTempCache(t)
+	server := newTokenServer(t, false)
+
+	token, err := AccessToken(&options.OAuth2{TokenURL: server.URL, ClientID: "app", ClientSecret: "s3cret"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "client_credentials-token" || server.basic {
+		t.Errorf("expected the credentials to be sent in the body, got %q", token)
+	}
+}
+
+func TestTokenErrors(t *testing.T) {
+	useTempCache(t)
+	server := newTokenServer(t, true)
+
+	_, err := AccessToken(&options.OAuth2{TokenURL: server.URL, ClientID: "app", ClientSecret: "wrong"})
+
+	if err == nil || err.Error() != "oauth2: token request failed: invalid_client" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseFormTokenResponse(t *testing.T) {
+	res, err := parseTokenResponse("application/x-www-form-urlencoded", []byte("access_token=abc&token_type=bearer&expires_in=60"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.AccessToken != "abc" || res.ExpiresIn.String() != "60" {
+		t.Errorf("unexpected response: %+v", res)
+	}
+}
+
+func TestPlaintextCacheIsMoved(t *testing.T) {
+	store, path := useTempCache(t)
+
+	legacy := `{"github": {"access_token": "gho_123", "refresh_token": "ghr_456", "expires_at": "2100-01-01T00:00:00Z"}}`
+
+	if err := ioutil.WriteFile(path, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	token, ok := CachedToken(&options.OAuth2{Profile: "github"})
+
+	if !ok || token != "gho_123" {
+		t.Errorf("expected the token of the plaintext cache, got %q", token)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the plaintext cache to be removed, got %v", err)
+	}
+
+	if !strings.Contains(store.secrets["oauth2/github"], "ghr_456") {
+		t.Errorf("expected the tokens in the vault, got %v", store.secrets)
+	}
+}
+
+func TestInlineConfigurationSecretName(t *testing.T) {
+	name := secretName(cacheKey(&options.OAuth2{TokenURL: "https://auth.example.com/token", ClientID: "app"}))
+
+	if !strings.HasPrefix(name, "oauth2/") || strings.Contains(name, "example.com") {
+		t.Errorf("expected a hashed secret name, got %q", name)
+	}
+}
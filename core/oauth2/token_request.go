@@ -0,0 +1,158 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	httpClient "github.com/abdfnx/resto/client"
+	"github.com/abdfnx/resto/core/options"
+)
+
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	RefreshToken     string      `json:"refresh_token"`
+	Scope            string      `json:"scope"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// requestToken posts params to the token endpoint, the client is authenticated with
+// HTTP basic auth first and with the client_id/client_secret form params if the server refuses it
+func requestToken(cfg *options.OAuth2, params map[string]string) (*Token, error) {
+	token, status, err := postToken(cfg, params, true)
+
+	if err != nil && cfg.ClientSecret != "" && (status == http.StatusBadRequest || status == http.StatusUnauthorized) {
+		token, _, err = postToken(cfg, params, false)
+	}
+
+	return token, err
+}
+
+func postToken(cfg *options.OAuth2, params map[string]string, basicAuth bool) (*Token, int, error) {
+	form := url.Values{}
+
+	for key, value := range params {
+		if value != "" {
+			form.Set(key, value)
+		}
+	}
+
+	clientID := cfg.ClientID
+	clientSecret := cfg.ClientSecret
+
+	if !basicAuth || clientSecret == "" {
+		form.Set("client_id", clientID)
+
+		if clientSecret != "" {
+			form.Set("client_secret", clientSecret)
+		}
+	}
+
+	req, err := http.NewRequest("POST", cfg.TokenURL, strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("oauth2: %s", err.Error())
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if basicAuth && clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	res, err := httpClient.HttpClient().Do(req)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("oauth2: error sending token request: %s", err.Error())
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+
+	if err != nil {
+		return nil, res.StatusCode, err
+	}
+
+	parsed, err := parseTokenResponse(res.Header.Get("Content-Type"), body)
+
+	if err != nil {
+		return nil, res.StatusCode, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 || parsed.Error != "" {
+		message := parsed.Error
+
+		if parsed.ErrorDescription != "" {
+			message += ": " + parsed.ErrorDescription
+		}
+
+		if message == "" {
+			message = res.Status
+		}
+
+		return nil, res.StatusCode, fmt.Errorf("oauth2: token request failed: %s", message)
+	}
+
+	if parsed.AccessToken == "" {
+		return nil, res.StatusCode, fmt.Errorf("oauth2: the server didn't return an access token")
+	}
+
+	token := &Token{
+		AccessToken:  parsed.AccessToken,
+		TokenType:    parsed.TokenType,
+		RefreshToken: parsed.RefreshToken,
+		Scope:        parsed.Scope,
+	}
+
+	if seconds, err := parsed.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		token.ExpiresAt = now().Add(time.Duration(seconds) * time.Second)
+	}
+
+	return token, res.StatusCode, nil
+}
+
+// parseTokenResponse reads json responses, and form encoded ones like GitHub sends by default
+func parseTokenResponse(contentType string, body []byte) (*tokenResponse, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "text/plain" {
+		values, err := url.ParseQuery(string(body))
+
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: can't parse the token response: %s", err.Error())
+		}
+
+		return &tokenResponse{
+			AccessToken:      values.Get("access_token"),
+			TokenType:        values.Get("token_type"),
+			RefreshToken:     values.Get("refresh_token"),
+			Scope:            values.Get("scope"),
+			ExpiresIn:        json.Number(values.Get("expires_in")),
+			Error:            values.Get("error"),
+			ErrorDescription: values.Get("error_description"),
+		}, nil
+	}
+
+	parsed := &tokenResponse{}
+
+	if err := json.Unmarshal(body, parsed); err != nil {
+		return nil, fmt.Errorf("oauth2: can't parse the token response: %s", err.Error())
+	}
+
+	if _, err := strconv.ParseInt(string(parsed.ExpiresIn), 10, 64); err != nil {
+		parsed.ExpiresIn = ""
+	}
+
+	return parsed, nil
+}
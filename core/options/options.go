@@ -1,10 +1,54 @@
 package options
 
+import (
+	"github.com/abdfnx/resto/ios"
+)
+
 type Auth struct {
 	TokenAuth 		  string
 	BasicAuthUsername string
 	BasicAuthPassword string
 	Type 		      string
+	OAuth2 			  OAuth2
+	AWS 			  AWS
+	APIKey 			  APIKey
+	HMAC 			  HMAC
+}
+
+type OAuth2 struct {
+	Profile      string
+	AuthURL      string
+	RedirectURL  string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       string
+	Audience     string
+	RefreshToken string
+}
+
+type APIKey struct {
+	Name  string
+	Value string
+	In    string
+}
+
+type HMAC struct {
+	Key             string
+	Algorithm       string
+	Header          string
+	Template        string
+	TimestampHeader string
+	Encoding        string
+}
+
+type AWS struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+	Service         string
+	Profile         string
 }
 
 type Method struct {
@@ -16,13 +60,31 @@ type Method struct {
 	OpenEditor 		bool
 	Body 			string
 	IsBodyStdin 	bool
+	Print 			string
+	Filter 			string
+	RawOutput 		bool
+	NoPager 		bool
 }
 
 type CLIOptions struct {
+	IO     *ios.IOStreams
 	Method *Method
 	URL    string
 }
 
+type Request struct {
+	Method      string
+	URL         string
+	ContentType string
+	Body        string
+	Headers     map[string]string
+	Query       map[string]string
+	AuthType    *Auth
+
+	// Insecure skips the verification of TLS certificates, like `curl -k`
+	Insecure bool
+}
+
 type InstallCommandOptions struct {
 	Shell    string
 	IsHidden bool
@@ -30,8 +92,95 @@ type InstallCommandOptions struct {
 }
 
 type RunCommandOptions struct {
-	Path    string
-	ShowAll bool
+	IO        *ios.IOStreams
+	Path      string
+	Name      string
+	All       bool
+	List      bool
+	Env       string
+	State     string
+	ShowAll   bool
+	Print     string
+	Filter    string
+	RawOutput bool
+	NoPager   bool
+}
+
+type TestCommandOptions struct {
+	IO    *ios.IOStreams
+	Paths []string
+	Env   string
+	State string
+	JUnit string
+	TAP   string
+}
+
+type FmtCommandOptions struct {
+	IO    *ios.IOStreams
+	Paths []string
+	Check bool
+}
+
+type LintCommandOptions struct {
+	IO     *ios.IOStreams
+	Paths  []string
+	Env    string
+	Format string
+}
+
+type ImportCommandOptions struct {
+	IO    *ios.IOStreams
+	Save  string
+	UI    bool
+	Print string
+}
+
+type AuthLoginCommandOptions struct {
+	IO        *ios.IOStreams
+	Profile   string
+	NoBrowser bool
+}
+
+type AuthAddCommandOptions struct {
+	IO            *ios.IOStreams
+	Host          string
+	Type          string
+	Username      string
+	Password      string
+	Token         string
+	PasswordStdin bool
+}
+
+type AuthCommandOptions struct {
+	IO   *ios.IOStreams
+	Host string
+}
+
+type JWTDecodeCommandOptions struct {
+	IO       *ios.IOStreams
+	Token    string
+	Secret   string
+	KeyFile  string
+	JWKSFile string
+	Audience string
+}
+
+type HMACVerifyCommandOptions struct {
+	IO        *ios.IOStreams
+	HMAC      HMAC
+	Method    string
+	URL       string
+	Timestamp string
+	Body      string
+	BodyFile  string
+	Signature string
+}
+
+type SecretCommandOptions struct {
+	IO         *ios.IOStreams
+	Name       string
+	Value      string
+	ValueStdin bool
 }
 
 type GetLatestCommandOptions struct {
@@ -0,0 +1,225 @@
+package restofile
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Pos is a position in a Restofile, lines and columns start at 1
+type Pos struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Pos) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Node is a Block or an Entry
+type Node interface {
+	Position() Pos
+}
+
+// Value is a quoted string or a bare word
+type Value struct {
+	Pos    Pos
+	Text   string
+	Quoted bool
+
+	// Heredoc is the delimiter of a <<EOF string, Raw is true for a """ string, whose variables aren't interpolated
+	Heredoc string
+	Raw     bool
+}
+
+// Entry is a key followed by one or more values, like `method "GET"` or `header "Content-Type" contains "json"`
+type Entry struct {
+	Pos    Pos
+	Key    string
+	Values []Value
+
+	// KeyQuoted is true for quoted keys, like the header names of `"X-Tenant" "acme"`
+	KeyQuoted bool
+
+	// Comments are the comment lines right before the entry, an empty one is a blank line between them and the entry,
+	// Comment is the one at the end of its line
+	Comments []string
+	Comment  string
+}
+
+// Position returns where the entry starts
+func (e *Entry) Position() Pos {
+	return e.Pos
+}
+
+// Value returns the text of the first value
+func (e *Entry) Value() string {
+	if e == nil || len(e.Values) == 0 {
+		return ""
+	}
+
+	return e.Values[0].Text
+}
+
+// Block is a named group of entries and blocks, like `request { ... }` or `request "login" { ... }`
+type Block struct {
+	Pos    Pos
+	Name   string
+	Labels []Value
+	Body   []Node
+
+	Comments []string
+	Comment  string
+
+	// EndComments are the comments between the last node and the closing brace
+	EndComments []string
+}
+
+// Position returns where the block starts
+func (b *Block) Position() Pos {
+	return b.Pos
+}
+
+// Label returns the first label of the block, like the name of `request "login" { ... }`
+func (b *Block) Label() string {
+	if b == nil || len(b.Labels) == 0 {
+		return ""
+	}
+
+	return b.Labels[0].Text
+}
+
+// Blocks returns the direct child blocks called name, or all of them when name is empty
+func (b *Block) Blocks(name string) []*Block {
+	if b == nil {
+		return nil
+	}
+
+	var blocks []*Block
+
+	for _, node := range b.Body {
+		if block, ok := node.(*Block); ok && (name == "" || block.Name == name) {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks
+}
+
+// Block returns the first direct child block called name, or nil
+func (b *Block) Block(name string) *Block {
+	if blocks := b.Blocks(name); len(blocks) > 0 {
+		return blocks[0]
+	}
+
+	return nil
+}
+
+// Entries returns the direct entries of the block
+func (b *Block) Entries() []*Entry {
+	if b == nil {
+		return nil
+	}
+
+	var entries []*Entry
+
+	for _, node := range b.Body {
+		if entry, ok := node.(*Entry); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// Entry returns the last direct entry called key, later entries override the earlier ones
+func (b *Block) Entry(key string) *Entry {
+	var found *Entry
+
+	for _, entry := range b.Entries() {
+		if entry.Key == key {
+			found = entry
+		}
+	}
+
+	return found
+}
+
+// Value returns the first value of the entry called key, or an empty string
+func (b *Block) Value(key string) string {
+	return b.Entry(key).Value()
+}
+
+// Abstract reports whether a request is only there to be extended, `resto run` and `resto test` don't send it
+func (b *Block) Abstract() bool {
+	return b.Value("abstract") == "true"
+}
+
+// File is a parsed Restofile
+type File struct {
+	Path string
+	Body []Node
+
+	// EndComments are the comments after the last node
+	EndComments []string
+}
+
+func (f *File) root() *Block {
+	if f == nil {
+		return nil
+	}
+
+	return &Block{Body: f.Body}
+}
+
+// Blocks returns the top-level blocks called name, or all of them when name is empty
+func (f *File) Blocks(name string) []*Block {
+	return f.root().Blocks(name)
+}
+
+// Block returns the first top-level block called name, or nil
+func (f *File) Block(name string) *Block {
+	return f.root().Block(name)
+}
+
+// Entries returns the top-level entries
+func (f *File) Entries() []*Entry {
+	return f.root().Entries()
+}
+
+// Entry returns the last top-level entry called key
+func (f *File) Entry(key string) *Entry {
+	return f.root().Entry(key)
+}
+
+// Value returns the first value of the top-level entry called key
+func (f *File) Value(key string) string {
+	return f.root().Value(key)
+}
+
+// Error is a syntax or a validation error at a position of a Restofile
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+func errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Errorf returns an error at pos, for the values that are checked after parsing
+func Errorf(pos Pos, format string, args ...interface{}) error {
+	return errorf(pos, format, args...)
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
@@ -0,0 +1,48 @@
+// Package restofile parses Restofiles, the files `resto run` sends requests from.
+//
+// A Restofile is a list of entries and blocks:
+//
+//	request {
+//	   method "POST"
+//	   url "https://api.example.com/v1/users"   # a comment
+//	   contentType "json"
+//	}
+//
+//	auth {
+//	   type "bearer"
+//	   token: "env:TOKEN"
+//	}
+//
+// Grammar, in EBNF:
+//
+//	File      = Body .
+//	Body      = { Statement | Separator } .
+//	Statement = Entry | Block .
+//	Entry     = Key [ ":" ] Value { Value } [ Comment ] ( Separator | "}" | EOF ) .
+//	Block     = Word { Value } "{" [ Comment ] Body "}" .
+//	Key       = Word | String .
+//	Value     = Word | String .
+//	Separator = newline | ";" .
+//
+// Lexical elements:
+//
+//	Word      = ( letter | digit | "_" | "-" | "." ) { letter | digit | "_" | "-" | "." } .
+//	String    = `"` { character | Escape } `"` | RawString | Heredoc .
+//	Escape    = `\"` | `\\` | `\n` | `\t` | `\r` .
+//	RawString = `"""` { character | newline } `"""` .
+//	Heredoc   = "<<" Word newline { line newline } indentation Word .
+//	Comment   = "#" ... newline | "//" ... newline | "/*" ... "*/" .
+//
+// Quoted strings can't span lines and unknown escapes are kept as written. Raw strings and
+// heredocs can, they have no escapes and the indentation of their closing """ or delimiter is
+// removed from their lines. The variables of raw strings aren't interpolated. Spaces, tabs and
+// carriage returns separate tokens, line feeds end entries. Comments are kept on the
+// nodes they precede or end the line of, so Format can print the file back in the
+// canonical style of `resto fmt`.
+//
+// Entries and blocks with the same name can repeat, Block.Entry returns the last entry
+// of a key. Errors are *Error values that start with the file:line:column of the problem.
+//
+// Parse keeps `include` and `extends` entries as written, Load and Resolve replace them
+// with the nodes of the included files and of the extended requests.
+package restofile
@@ -0,0 +1,299 @@
+package restofile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// indent is the indentation of a level of blocks
+const indent = "   "
+
+// Format returns the canonical text of a Restofile: blocks are indented with three spaces and separated by blank lines,
+// values are quoted, the colons after keys are dropped and the entries and blocks are ordered like Schemas.
+// Comments are kept with the nodes they belong to, and the requests keep their order
+func Format(f *File) []byte {
+	var b strings.Builder
+
+	body := f.Body
+
+	// the comments at the top of the file that are followed by a blank line stay at the top
+	if len(body) > 0 {
+		body = append([]Node(nil), body...)
+
+		switch node := body[0].(type) {
+		case *Entry:
+			if header, rest := splitHeader(node.Comments); header != nil {
+				formatComments(&b, "", header)
+
+				entry := *node
+				entry.Comments = rest
+				body[0] = &entry
+			}
+		case *Block:
+			if header, rest := splitHeader(node.Comments); header != nil {
+				formatComments(&b, "", header)
+
+				block := *node
+				block.Comments = rest
+				body[0] = &block
+			}
+		}
+	}
+
+	formatBody(&b, "", body, f.EndComments, 0)
+
+	return []byte(b.String())
+}
+
+// splitHeader splits comments at their last blank line
+func splitHeader(comments []string) (header, rest []string) {
+	for i := len(comments) - 1; i >= 0; i-- {
+		if comments[i] == "" {
+			return comments[:i+1], comments[i+1:]
+		}
+	}
+
+	return nil, comments
+}
+
+func formatBody(b *strings.Builder, parent string, nodes []Node, endComments []string, depth int) {
+	prefix := strings.Repeat(indent, depth)
+	nodes = order(parent, nodes)
+
+	for i, node := range nodes {
+		_, isBlock := node.(*Block)
+
+		if i > 0 {
+			if _, prevBlock := nodes[i-1].(*Block); isBlock || prevBlock {
+				b.WriteString("\n")
+			}
+		}
+
+		switch node := node.(type) {
+		case *Entry:
+			formatComments(b, prefix, node.Comments)
+
+			b.WriteString(prefix + formatKey(node.Key))
+
+			for i, value := range node.Values {
+				if value.Heredoc != "" || value.Raw {
+					b.WriteString(" " + multiline(prefix, value))
+				} else if keyword(parent, node, i) {
+					b.WriteString(" " + value.Text)
+				} else {
+					b.WriteString(" " + Quote(value.Text))
+				}
+			}
+
+			formatLineComment(b, node.Comment)
+		case *Block:
+			formatComments(b, prefix, node.Comments)
+
+			b.WriteString(prefix + node.Name)
+
+			for _, label := range node.Labels {
+				b.WriteString(" " + Quote(label.Text))
+			}
+
+			if len(node.Body) == 0 && len(node.EndComments) == 0 && node.Comment == "" {
+				b.WriteString(" {}\n")
+
+				continue
+			}
+
+			b.WriteString(" {")
+			formatLineComment(b, node.Comment)
+			formatBody(b, node.Name, node.Body, node.EndComments, depth+1)
+			b.WriteString(prefix + "}\n")
+		}
+	}
+
+	if len(endComments) > 0 && len(nodes) > 0 {
+		if _, lastBlock := nodes[len(nodes)-1].(*Block); lastBlock {
+			b.WriteString("\n")
+		}
+	}
+
+	formatComments(b, prefix, endComments)
+}
+
+func formatComments(b *strings.Builder, prefix string, comments []string) {
+	for _, comment := range comments {
+		if comment == "" {
+			b.WriteString("\n")
+		} else {
+			b.WriteString(prefix + comment + "\n")
+		}
+	}
+}
+
+func formatLineComment(b *strings.Builder, comment string) {
+	if comment != "" {
+		b.WriteString(" " + comment)
+	}
+
+	b.WriteString("\n")
+}
+
+// order sorts the leading keys of the schema of parent first, then the other entries, then the blocks in the order of the schema,
+// the nodes that have the same rank keep their order
+func order(parent string, nodes []Node) []Node {
+	schema := Schemas[parent]
+
+	rank := func(node Node) int {
+		switch node := node.(type) {
+		case *Entry:
+			if i := index(schema.Keys, node.Key); i != -1 && i < schema.Leading {
+				return i
+			}
+
+			return schema.Leading
+		case *Block:
+			if i := index(schema.Blocks, node.Name); i != -1 {
+				return len(schema.Keys) + 1 + i
+			}
+		}
+
+		return len(schema.Keys) + 1 + len(schema.Blocks)
+	}
+
+	ordered := append([]Node(nil), nodes...)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+
+	return ordered
+}
+
+// multiline returns a <<EOF or a """ string, its lines are indented like the entry, prefix is the indentation of the entry
+func multiline(prefix string, value Value) string {
+	if value.Raw && !strings.Contains(value.Text, "\n") {
+		return `"""` + value.Text + `"""`
+	}
+
+	open, end := `"""`, `"""`
+
+	if !value.Raw {
+		end = delimiter(value)
+		open = "<<" + end
+	}
+
+	var b strings.Builder
+
+	b.WriteString(open + "\n")
+
+	for _, line := range strings.Split(value.Text, "\n") {
+		if line != "" {
+			b.WriteString(prefix + line)
+		}
+
+		b.WriteString("\n")
+	}
+
+	b.WriteString(prefix + end)
+
+	return b.String()
+}
+
+// delimiter returns the delimiter of a heredoc, EOF by default, that doesn't start a line of its text
+func delimiter(value Value) string {
+	base := value.Heredoc
+
+	if base == "" {
+		base = "EOF"
+	}
+
+	name := base
+
+	for n := 2; endsHeredoc(value.Text, name); n++ {
+		name = fmt.Sprintf("%s%d", base, n)
+	}
+
+	return name
+}
+
+func endsHeredoc(text, delimiter string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if isDelimiterLine(line, delimiter) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatKey(key string) string {
+	if isWord(key) {
+		return key
+	}
+
+	return Quote(key)
+}
+
+// keyword reports whether the value i of an entry is written without quotes, like the codes, the durations
+// and the assertions of an expect block: status 200, time 500ms or json "data.id" exists
+func keyword(parent string, entry *Entry, i int) bool {
+	if parent != "expect" || !isWord(entry.Values[i].Text) {
+		return false
+	}
+
+	switch entry.Key {
+	case "status", "time":
+		return true
+	case "header", "json":
+		return i == 1
+	case "body":
+		return i == 0
+	}
+
+	return false
+}
+
+func isWord(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isWordByte(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Quote returns s as a Restofile string. Backslashes are only escaped before the characters of an escape,
+// since the lexer keeps unknown escapes, so regexes like \d+ are written as they are
+func Quote(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\\':
+			if i+1 == len(s) || strings.IndexByte("\"\\ntr\n\t\r", s[i+1]) != -1 {
+				b.WriteString(`\\`)
+			} else {
+				b.WriteByte('\\')
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
@@ -0,0 +1,189 @@
+package restofile
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const unformatted = `# the api, a blank line keeps the comment apart
+
+# the login
+request "login" {
+  contentType: json
+  url "{{baseUrl}}/login"   # the url
+    method POST
+  expect { status 200 }
+  capture {
+    token "json:token"
+  }
+  body { readFrom "login.json" }
+}
+vars { baseUrl "https://api.example.com" }
+headers {
+  "Accept" "application/json"
+  "X Custom" "a \"b\" \\n"
+}
+auth { token "env:TOKEN"; type bearer
+  // the end of auth
+}
+request "me" { method GET; url "{{baseUrl}}/me"; capture { id "regex:id=(\d+)" } }
+/* the end */
+`
+
+const formatted = `# the api, a blank line keeps the comment apart
+
+vars {
+   baseUrl "https://api.example.com"
+}
+
+headers {
+   Accept "application/json"
+   "X Custom" "a \"b\" \\n"
+}
+
+auth {
+   type "bearer"
+   token "env:TOKEN"
+   // the end of auth
+}
+
+# the login
+request "login" {
+   method "POST"
+   url "{{baseUrl}}/login" # the url
+   contentType "json"
+
+   body {
+      readFrom "login.json"
+   }
+
+   capture {
+      token "json:token"
+   }
+
+   expect {
+      status 200
+   }
+}
+
+request "me" {
+   method "GET"
+   url "{{baseUrl}}/me"
+
+   capture {
+      id "regex:id=(\d+)"
+   }
+}
+
+/* the end */
+`
+
+func TestFormat(t *testing.T) {
+	f, err := Parse("Restofile", []byte(unformatted))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(Format(f))
+
+	if got != formatted {
+		t.Errorf("expected\n%s\ngot\n%s", formatted, got)
+	}
+
+	again, err := Parse("Restofile", []byte(got))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(Format(again)) != got {
+		t.Errorf("formatting a formatted file changed it")
+	}
+
+	if value := again.Block("headers").Entry("X Custom").Value(); value != `a "b" \n` {
+		t.Errorf("expected the value to be kept, got %q", value)
+	}
+}
+
+func TestFormatExamples(t *testing.T) {
+	paths, err := filepath.Glob("../../examples/restofile/*/Restofile")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		src, err := ioutil.ReadFile(path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := Parse(path, src)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the examples are written with the line endings of the platform
+		if got := string(Format(f)); got != strings.ReplaceAll(string(src), "\r\n", "\n") {
+			t.Errorf("%s isn't formatted, expected\n%s", path, got)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for _, text := range []string{``, `plain`, `"quoted"`, `\d+`, `C:\Users`, `ends with \`, `\n literal`, "tab\tand\nnewline", "\\\n"} {
+		f, err := Parse("Restofile", []byte("key "+Quote(text)))
+
+		if err != nil {
+			t.Fatalf("%s :: %v", Quote(text), err)
+		}
+
+		if got := f.Value("key"); got != text {
+			t.Errorf("%s :: expected %q, got %q", Quote(text), text, got)
+		}
+	}
+
+	if got := Quote(`\d+`); got != `"\d+"` {
+		t.Errorf("expected unknown escapes to be kept, got %s", got)
+	}
+}
+
+func TestFormatMultiline(t *testing.T) {
+	src := "body {\n  content <<JSON\n    {\n      \"id\": 1\n\n    }\n    JSON # the user\n  raw \"\"\"\n{{literal}}\n\"\"\"\n  line \"\"\"a \"b\" \\d\"\"\"\n}\n"
+
+	expected := "body {\n   content <<JSON\n   {\n     \"id\": 1\n\n   }\n   JSON # the user\n   raw \"\"\"{{literal}}\"\"\"\n   line \"\"\"a \"b\" \\d\"\"\"\n}\n"
+
+	f, err := Parse("Restofile", []byte(src))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(Format(f)); got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	again, err := Parse("Restofile", []byte(expected))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"content", "raw", "line"} {
+		if a, b := f.Block("body").Value(key), again.Block("body").Value(key); a != b {
+			t.Errorf("%s :: expected %q after formatting, got %q", key, a, b)
+		}
+	}
+
+	// a line of the text that starts with the delimiter gets another one
+	text := &File{Body: []Node{&Entry{Key: "content", Values: []Value{{Text: "EOF\nEOF2", Heredoc: "EOF"}}}}}
+
+	if got := string(Format(text)); got != "content <<EOF3\nEOF\nEOF2\nEOF3\n" {
+		t.Errorf("expected the EOF3 delimiter, got %q", got)
+	}
+}
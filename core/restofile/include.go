@@ -0,0 +1,261 @@
+package restofile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extendable are the blocks a request adds to the ones of the request it extends, its other blocks replace them
+var extendable = []string{"vars", "headers", "query", "capture", "expect"}
+
+// Load reads and parses the Restofile at path, and resolves its includes and extends
+func Load(path string) (*File, error) {
+	f, err := ParseFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Resolve(f)
+}
+
+// Resolve returns f with its `include "path"` entries replaced by the nodes of the included files, and the requests
+// that have an `extends "name"` entry merged with the request they extend.
+//
+// Includes are allowed at the top level and in requests, their paths are relative to the including file. A file that's
+// already included at the top level, or in the same request, is skipped, so two files can include the same one.
+// A request gets the entries and blocks of the request it extends that it doesn't have, except for the vars,
+// headers, query, capture and expect blocks whose entries are added to its own
+func Resolve(f *File) (*File, error) {
+	body, err := includeNodes(f.Body, []string{filepath.Clean(f.Path)}, true, map[string]bool{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = extendRequests(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{Path: f.Path, Body: body, EndComments: f.EndComments}, nil
+}
+
+// includeNodes replaces the includes of nodes, chain is the files that include each other down to the one of nodes,
+// included is the files already included at the same level, the top level or a request
+func includeNodes(nodes []Node, chain []string, top bool, included map[string]bool) ([]Node, error) {
+	var out []Node
+
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case *Entry:
+			if node.Key != "include" || node.KeyQuoted {
+				out = append(out, node)
+
+				continue
+			}
+
+			nodes, err := include(node, chain, included)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, nodes...)
+		case *Block:
+			if !top || node.Name != "request" {
+				out = append(out, node)
+
+				continue
+			}
+
+			body, err := includeNodes(node.Body, chain, false, map[string]bool{})
+
+			if err != nil {
+				return nil, err
+			}
+
+			block := *node
+			block.Body = body
+			out = append(out, &block)
+		}
+	}
+
+	return out, nil
+}
+
+// include returns the nodes of the file of an include entry, with their own includes replaced, or nothing when it's already included
+func include(entry *Entry, chain []string, included map[string]bool) ([]Node, error) {
+	if len(entry.Values) != 1 {
+		return nil, errorf(entry.Pos, "include takes the path of a file, like include \"shared/auth.resto\"")
+	}
+
+	path := entry.Value()
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(entry.Pos.File), path)
+	}
+
+	for _, p := range chain {
+		if sameFile(p, path) {
+			return nil, errorf(entry.Values[0].Pos, "include cycle: %s", strings.Join(append(chain, path), " -> "))
+		}
+	}
+
+	key := path
+
+	if abs, err := filepath.Abs(path); err == nil {
+		key = abs
+	}
+
+	if included[key] {
+		return nil, nil
+	}
+
+	included[key] = true
+
+	f, err := ParseFile(path)
+
+	if os.IsNotExist(err) {
+		return nil, errorf(entry.Values[0].Pos, "can't include %s, there's no such file", path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return includeNodes(f.Body, append(chain[:len(chain):len(chain)], path), true, included)
+}
+
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+
+	return absA == absB
+}
+
+// extendRequests merges the requests of nodes that extend another request with it
+func extendRequests(nodes []Node) ([]Node, error) {
+	requests := map[string]*Block{}
+
+	for _, node := range nodes {
+		if block, ok := node.(*Block); ok && block.Name == "request" && block.Label() != "" {
+			if _, ok := requests[block.Label()]; !ok {
+				requests[block.Label()] = block
+			}
+		}
+	}
+
+	resolved := map[*Block]*Block{}
+
+	var resolve func(block *Block, chain []string) (*Block, error)
+
+	resolve = func(block *Block, chain []string) (*Block, error) {
+		if r, ok := resolved[block]; ok {
+			return r, nil
+		}
+
+		entry := block.Entry("extends")
+
+		if entry == nil {
+			return block, nil
+		}
+
+		if len(entry.Values) != 1 {
+			return nil, errorf(entry.Pos, "extends takes the name of a request, like extends \"base\"")
+		}
+
+		name := entry.Value()
+
+		if index(chain, name) != -1 {
+			return nil, errorf(entry.Values[0].Pos, "extends cycle: %s", strings.Join(append(chain, name), " -> "))
+		}
+
+		base, ok := requests[name]
+
+		if !ok {
+			return nil, errorf(entry.Values[0].Pos, "there's no request called %s to extend", quote(name))
+		}
+
+		base, err := resolve(base, append(chain[:len(chain):len(chain)], name))
+
+		if err != nil {
+			return nil, err
+		}
+
+		merged := extend(base, block)
+		resolved[block] = merged
+
+		return merged, nil
+	}
+
+	out := make([]Node, len(nodes))
+
+	for i, node := range nodes {
+		out[i] = node
+
+		if block, ok := node.(*Block); ok && block.Name == "request" {
+			merged, err := resolve(block, []string{block.Label()})
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[i] = merged
+		}
+	}
+
+	return out, nil
+}
+
+// extend returns request with the entries and blocks of base it doesn't have
+func extend(base, request *Block) *Block {
+	merged := *request
+	merged.Body = nil
+
+	// a request that extends an abstract one isn't abstract
+	for _, entry := range base.Entries() {
+		if request.Entry(entry.Key) == nil && entry.Key != "abstract" {
+			merged.Body = append(merged.Body, entry)
+		}
+	}
+
+	for _, entry := range request.Entries() {
+		if entry.Key != "extends" {
+			merged.Body = append(merged.Body, entry)
+		}
+	}
+
+	for _, block := range base.Blocks("") {
+		if request.Block(block.Name) == nil {
+			merged.Body = append(merged.Body, block)
+		}
+	}
+
+	for _, block := range request.Blocks("") {
+		if index(extendable, block.Name) == -1 || request.Block(block.Name) != block {
+			merged.Body = append(merged.Body, block)
+
+			continue
+		}
+
+		// the entries of the request come last, so they override the ones of base
+		withBase := *block
+		withBase.Body = nil
+
+		for _, baseBlock := range base.Blocks(block.Name) {
+			withBase.Body = append(withBase.Body, baseBlock.Body...)
+		}
+
+		withBase.Body = append(withBase.Body, block.Body...)
+		merged.Body = append(merged.Body, &withBase)
+	}
+
+	return &merged
+}
@@ -0,0 +1,250 @@
+package restofile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoadInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"api/Restofile": `include "../shared/auth.resto"
+
+request "me" {
+   include "../shared/accept.resto"
+   method "GET"
+   url "https://api.example.com/me"
+}
+`,
+		"shared/auth.resto": `include "headers.resto"
+
+auth {
+   type "bearer"
+   token "env:TOKEN"
+}
+`,
+		"shared/headers.resto": `headers { X-Tenant "acme" }`,
+		"shared/accept.resto":  `headers { Accept "application/json" }`,
+	})
+
+	f, err := Load(filepath.Join(dir, "api", "Restofile"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Entry("include") != nil || f.Block("auth").Value("token") != "env:TOKEN" || f.Block("headers").Value("X-Tenant") != "acme" {
+		t.Errorf("expected the includes to be replaced, got %+v", f.Body)
+	}
+
+	if pos := f.Block("headers").Pos; pos.File != filepath.Join(dir, "shared", "headers.resto") || pos.Line != 1 {
+		t.Errorf("expected the included nodes to keep their positions, got %v", pos)
+	}
+
+	me := f.Block("request")
+
+	if me.Entry("include") != nil || me.Block("headers").Value("Accept") != "application/json" {
+		t.Errorf("expected the include of the request to be replaced, got %+v", me.Body)
+	}
+}
+
+func TestLoadIncludeDiamond(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"Restofile": `include "users.resto"
+include "admins.resto"
+
+request "me" {
+   include "accept.resto"
+   include "accept.resto"
+   url "https://api.example.com/me"
+}
+`,
+		"users.resto":  "include \"base.resto\"\nrequest \"users\" { extends \"base\" }",
+		"admins.resto": "include \"base.resto\"\nrequest \"admins\" { extends \"base\" }",
+		"base.resto":   "request \"base\" {\n   url \"https://api.example.com\"\n   abstract \"true\"\n}",
+		"accept.resto": `headers { Accept "application/json" }`,
+	})
+
+	f, err := Load(filepath.Join(dir, "Restofile"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+
+	for _, request := range f.Blocks("request") {
+		names = append(names, request.Label())
+
+		if request.Label() != "base" && request.Abstract() {
+			t.Errorf("expected %s not to inherit abstract from base", request.Label())
+		}
+	}
+
+	if strings.Join(names, ",") != "base,users,admins,me" {
+		t.Errorf("expected base.resto to be included once, got the requests %v", names)
+	}
+
+	if headers := f.Blocks("request")[3].Blocks("headers"); len(headers) != 1 {
+		t.Errorf("expected accept.resto to be included once in the request, got %d headers blocks", len(headers))
+	}
+}
+
+func TestLoadIncludeErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"Restofile":         `include "shared/a.resto"`,
+		"shared/a.resto":    `include "b.resto"`,
+		"shared/b.resto":    `include "a.resto"`,
+		"missing/Restofile": `include "nope.resto"`,
+		"syntax/Restofile":  `include "bad.resto"`,
+		"syntax/bad.resto":  `auth {`,
+	})
+
+	cycle := strings.Join([]string{
+		filepath.Join(dir, "Restofile"),
+		filepath.Join(dir, "shared", "a.resto"),
+		filepath.Join(dir, "shared", "b.resto"),
+		filepath.Join(dir, "shared", "a.resto"),
+	}, " -> ")
+
+	cases := []struct {
+		path string
+		err  string
+	}{
+		{path: "Restofile", err: filepath.Join(dir, "shared", "b.resto") + ":1:9: include cycle: " + cycle},
+		{path: "missing/Restofile", err: ":1:9: can't include " + filepath.Join(dir, "missing", "nope.resto") + ", there's no such file"},
+		{path: "syntax/Restofile", err: filepath.Join(dir, "syntax", "bad.resto") + ":1:1: the auth block isn't closed"},
+	}
+
+	for _, c := range cases {
+		_, err := Load(filepath.Join(dir, c.path))
+
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s :: expected an error containing %q, got %v", c.path, c.err, err)
+		}
+	}
+}
+
+const extendsSrc = `request "base" {
+   method "GET"
+   url "https://api.example.com/users"
+
+   headers {
+      Accept "application/json"
+      X-Version "1"
+   }
+
+   auth {
+      type "bearer"
+      token "env:TOKEN"
+   }
+
+   expect { status 200 }
+}
+
+request "admin" {
+   extends "base"
+   url "https://api.example.com/admins"
+
+   headers { X-Version "2" }
+
+   auth {
+      type "basic"
+      username "admin"
+      password "env:ADMIN_PASSWORD"
+   }
+}
+
+request "admin-xml" {
+   extends "admin"
+
+   headers { Accept "application/xml" }
+}
+`
+
+func TestResolveExtends(t *testing.T) {
+	f, err := Parse("Restofile", []byte(extendsSrc))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err = Resolve(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := f.Blocks("request")
+	admin, adminXML := requests[1], requests[2]
+
+	if admin.Entry("extends") != nil || admin.Value("method") != "GET" || admin.Value("url") != "https://api.example.com/admins" {
+		t.Errorf("expected admin to get the method of base, got %+v", admin.Body)
+	}
+
+	if headers := admin.Block("headers"); headers.Value("Accept") != "application/json" || headers.Value("X-Version") != "2" {
+		t.Errorf("expected the headers to be merged, got %+v", headers.Body)
+	}
+
+	if auth := admin.Block("auth"); auth.Value("type") != "basic" || auth.Entry("token") != nil {
+		t.Errorf("expected the auth block of admin to replace the one of base, got %+v", auth.Body)
+	}
+
+	if admin.Block("expect").Value("status") != "200" {
+		t.Errorf("expected admin to get the expect block of base")
+	}
+
+	if adminXML.Value("url") != "https://api.example.com/admins" || adminXML.Block("headers").Value("X-Version") != "2" || adminXML.Block("headers").Value("Accept") != "application/xml" {
+		t.Errorf("expected admin-xml to extend the merged admin, got %+v", adminXML.Block("headers").Body)
+	}
+
+	if adminXML.Block("auth").Value("type") != "basic" {
+		t.Errorf("expected admin-xml to get the auth of admin")
+	}
+}
+
+func TestResolveExtendsErrors(t *testing.T) {
+	cases := []struct {
+		src string
+		err string
+	}{
+		{src: `request "a" { extends "b" }`, err: `Restofile:1:23: there's no request called "b" to extend`},
+		{src: "request \"a\" { extends \"b\" }\nrequest \"b\" { extends \"a\" }", err: "Restofile:2:23: extends cycle: a -> b -> a"},
+		{src: `request "a" { extends "a" }`, err: "Restofile:1:23: extends cycle: a -> a"},
+		{src: "request \"a\" { method GET }\nrequest \"b\" { extends \"a\" \"c\" }", err: "Restofile:2:15: extends takes the name of a request"},
+	}
+
+	for _, c := range cases {
+		f, err := Parse("Restofile", []byte(c.src))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Resolve(f); err == nil || !strings.HasPrefix(err.Error(), c.err) {
+			t.Errorf("%s :: expected %q, got %v", c.src, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package restofile
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewline
+	tokenComment
+	tokenWord
+	tokenString
+	tokenLBrace
+	tokenRBrace
+	tokenColon
+	tokenSemicolon
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of file"
+	case tokenNewline:
+		return "end of line"
+	case tokenComment:
+		return "comment"
+	case tokenWord:
+		return "word"
+	case tokenString:
+		return "string"
+	case tokenLBrace:
+		return "{"
+	case tokenRBrace:
+		return "}"
+	case tokenColon:
+		return ":"
+	case tokenSemicolon:
+		return ";"
+	}
+
+	return "token"
+}
+
+type token struct {
+	kind tokenKind
+	pos  Pos
+
+	// text is the unquoted value of strings, the name of words and the text of comments
+	text string
+
+	// heredoc is the delimiter of a <<EOF string, raw is true for a """ string
+	heredoc string
+	raw     bool
+}
+
+// describe names the token in errors
+func (t token) describe() string {
+	switch t.kind {
+	case tokenWord:
+		return "word " + t.text
+	case tokenString:
+		return "string " + quote(t.text)
+	}
+
+	return t.kind.String()
+}
+
+type lexer struct {
+	src    string
+	file   string
+	offset int
+	line   int
+	column int
+}
+
+func newLexer(file string, src []byte) *lexer {
+	return &lexer{src: string(src), file: file, line: 1, column: 1}
+}
+
+func (l *lexer) pos() Pos {
+	return Pos{File: l.file, Line: l.line, Column: l.column}
+}
+
+func (l *lexer) peek(n int) byte {
+	if l.offset+n >= len(l.src) {
+		return 0
+	}
+
+	return l.src[l.offset+n]
+}
+
+func (l *lexer) advance() rune {
+	r, size := utf8.DecodeRuneInString(l.src[l.offset:])
+	l.offset += size
+
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
+	return r
+}
+
+// next returns the next token, whitespace other than line feeds is skipped
+func (l *lexer) next() (token, error) {
+	for l.offset < len(l.src) {
+		if c := l.src[l.offset]; c != ' ' && c != '\t' && c != '\r' {
+			break
+		}
+
+		l.advance()
+	}
+
+	pos := l.pos()
+
+	if l.offset >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+
+	switch c := l.src[l.offset]; {
+	case c == '\n':
+		l.advance()
+		return token{kind: tokenNewline, pos: pos}, nil
+	case c == '{':
+		l.advance()
+		return token{kind: tokenLBrace, pos: pos}, nil
+	case c == '}':
+		l.advance()
+		return token{kind: tokenRBrace, pos: pos}, nil
+	case c == ':':
+		l.advance()
+		return token{kind: tokenColon, pos: pos}, nil
+	case c == ';':
+		l.advance()
+		return token{kind: tokenSemicolon, pos: pos}, nil
+	case c == '#' || (c == '/' && l.peek(1) == '/'):
+		return l.lineComment(pos), nil
+	case c == '/' && l.peek(1) == '*':
+		return l.blockComment(pos)
+	case c == '"' && strings.HasPrefix(l.src[l.offset:], `"""`):
+		return l.rawString(pos)
+	case c == '"':
+		return l.string(pos)
+	case c == '<' && l.peek(1) == '<':
+		return l.heredoc(pos)
+	case isWordByte(c):
+		return l.word(pos), nil
+	}
+
+	r := l.advance()
+
+	return token{}, errorf(pos, "unexpected character %q", r)
+}
+
+func (l *lexer) lineComment(pos Pos) token {
+	start := l.offset
+
+	for l.offset < len(l.src) && l.src[l.offset] != '\n' {
+		l.advance()
+	}
+
+	return token{kind: tokenComment, pos: pos, text: strings.TrimRight(l.src[start:l.offset], " \t\r")}
+}
+
+func (l *lexer) blockComment(pos Pos) (token, error) {
+	start := l.offset
+	end := strings.Index(l.src[l.offset+2:], "*/")
+
+	if end == -1 {
+		return token{}, errorf(pos, "unterminated comment, it needs a closing */")
+	}
+
+	for l.offset < start+2+end+2 {
+		l.advance()
+	}
+
+	return token{kind: tokenComment, pos: pos, text: l.src[start:l.offset]}, nil
+}
+
+func (l *lexer) string(pos Pos) (token, error) {
+	var b strings.Builder
+
+	l.advance()
+
+	for {
+		if l.offset >= len(l.src) || l.src[l.offset] == '\n' {
+			return token{}, errorf(pos, "unterminated string, it needs a closing \"")
+		}
+
+		r := l.advance()
+
+		if r == '"' {
+			return token{kind: tokenString, pos: pos, text: b.String()}, nil
+		}
+
+		if r != '\\' {
+			b.WriteRune(r)
+			continue
+		}
+
+		if l.offset >= len(l.src) {
+			return token{}, errorf(pos, "unterminated string, it needs a closing \"")
+		}
+
+		switch escaped := l.advance(); escaped {
+		case '"', '\\':
+			b.WriteRune(escaped)
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			// unknown escapes are kept, like the \d of a regex or the \U of a windows path
+			b.WriteRune('\\')
+			b.WriteRune(escaped)
+		}
+	}
+}
+
+// rawString lexes a """ string, it has no escapes and can span lines. When the opening and the closing """ are on their
+// own lines, these lines are dropped and the indentation of the closing """ is removed from the others
+func (l *lexer) rawString(pos Pos) (token, error) {
+	start := l.offset + 3
+	end := strings.Index(l.src[start:], `"""`)
+
+	if end == -1 {
+		return token{}, errorf(pos, "unterminated string, it needs a closing \"\"\"")
+	}
+
+	for l.offset < start+end+3 {
+		l.advance()
+	}
+
+	lines := strings.Split(l.src[start:start+end], "\n")
+
+	if len(lines) == 1 {
+		return token{kind: tokenString, pos: pos, text: lines[0], raw: true}, nil
+	}
+
+	first := Pos{File: pos.File, Line: pos.Line, Column: 1}
+
+	if strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+		first.Line++
+	}
+
+	indentation := ""
+
+	if last := lines[len(lines)-1]; strings.TrimLeft(last, " \t") == "" {
+		indentation = last
+		lines = lines[:len(lines)-1]
+	}
+
+	text, err := dedent(lines, indentation, first, `the lines of a """ string must be indented at least as much as its closing """`)
+
+	if err != nil {
+		return token{}, err
+	}
+
+	return token{kind: tokenString, pos: pos, text: text, raw: true}, nil
+}
+
+// heredoc lexes a <<EOF string, its lines run up to a line that starts with EOF, whose indentation is removed from
+// the others. The line feed before EOF isn't part of the string
+func (l *lexer) heredoc(pos Pos) (token, error) {
+	l.advance()
+	l.advance()
+
+	start := l.offset
+
+	for l.offset < len(l.src) && isWordByte(l.src[l.offset]) {
+		l.advance()
+	}
+
+	delimiter := l.src[start:l.offset]
+
+	if delimiter == "" {
+		return token{}, errorf(pos, "expected a name after <<, like <<EOF")
+	}
+
+	for l.offset < len(l.src) && (l.src[l.offset] == ' ' || l.src[l.offset] == '\t' || l.src[l.offset] == '\r') {
+		l.advance()
+	}
+
+	if l.offset < len(l.src) && l.src[l.offset] != '\n' {
+		return token{}, errorf(l.pos(), "the text of <<%s starts on the next line", delimiter)
+	}
+
+	first := Pos{File: pos.File, Line: pos.Line + 1, Column: 1}
+
+	var lines []string
+
+	for {
+		if l.offset >= len(l.src) {
+			return token{}, errorf(pos, "unterminated heredoc, it needs a closing %s line", delimiter)
+		}
+
+		// the line feed of the previous line
+		l.advance()
+
+		line := l.src[l.offset:]
+
+		if end := strings.IndexByte(line, '\n'); end != -1 {
+			line = line[:end]
+		}
+
+		if isDelimiterLine(line, delimiter) {
+			indentation := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+			// what follows the delimiter, like a } or a comment, is lexed as usual
+			for i := 0; i < len(indentation)+len(delimiter); i++ {
+				l.advance()
+			}
+
+			text, err := dedent(lines, indentation, first, fmt.Sprintf("the lines of <<%s must be indented at least as much as its closing %s", delimiter, delimiter))
+
+			if err != nil {
+				return token{}, err
+			}
+
+			return token{kind: tokenString, pos: pos, text: text, heredoc: delimiter}, nil
+		}
+
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+
+		for l.offset < len(l.src) && l.src[l.offset] != '\n' {
+			l.advance()
+		}
+	}
+}
+
+// isDelimiterLine reports whether line closes a heredoc, it starts with the delimiter after its indentation
+func isDelimiterLine(line, delimiter string) bool {
+	line = strings.TrimLeft(line, " \t")
+
+	return strings.HasPrefix(line, delimiter) && (len(line) == len(delimiter) || !isWordByte(line[len(delimiter)]))
+}
+
+// dedent removes indentation from the lines and joins them, first is the position of the first line.
+// Blank lines become empty, the others must start with indentation
+func dedent(lines []string, indentation string, first Pos, msg string) (string, error) {
+	out := make([]string, len(lines))
+
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, indentation) {
+			return "", errorf(Pos{File: first.File, Line: first.Line + i, Column: 1}, "%s", msg)
+		}
+
+		out[i] = line[len(indentation):]
+	}
+
+	return strings.Join(out, "\n"), nil
+}
+
+func (l *lexer) word(pos Pos) token {
+	start := l.offset
+
+	for l.offset < len(l.src) && isWordByte(l.src[l.offset]) {
+		l.advance()
+	}
+
+	return token{kind: tokenWord, pos: pos, text: l.src[start:l.offset]}
+}
+
+// isWordByte reports whether c can be part of a bare word, like a key, a block name or a value such as 200 or true
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.'
+}
@@ -0,0 +1,230 @@
+package restofile
+
+import (
+	"bytes"
+	"io/ioutil"
+)
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// Parse parses the Restofile src, file is only used in the positions of errors and nodes
+func Parse(file string, src []byte) (*File, error) {
+	src = bytes.TrimPrefix(src, []byte("\xef\xbb\xbf"))
+
+	p := &parser{lex: newLexer(file, src)}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	root := &Block{Pos: Pos{File: file, Line: 1, Column: 1}}
+
+	if err := p.parseBody(root, false); err != nil {
+		return nil, err
+	}
+
+	return &File{Path: file, Body: root.Body, EndComments: root.EndComments}, nil
+}
+
+// ParseFile reads and parses the Restofile at path
+func ParseFile(path string) (*File, error) {
+	src, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(path, src)
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex.next()
+
+	if err != nil {
+		return err
+	}
+
+	p.tok = tok
+
+	return nil
+}
+
+// parseBody parses the entries and blocks of block, up to its closing brace when closing is true or to the end of the file
+func (p *parser) parseBody(block *Block, closing bool) error {
+	var comments []string
+
+	newlines := 0
+
+	for {
+		if p.tok.kind != tokenNewline {
+			newlines = 0
+		}
+
+		switch p.tok.kind {
+		case tokenNewline, tokenSemicolon:
+			// a blank line after comments is kept as an empty comment, so they stay apart from the next node
+			if p.tok.kind == tokenNewline {
+				newlines++
+
+				if newlines == 2 && len(comments) > 0 && comments[len(comments)-1] != "" {
+					comments = append(comments, "")
+				}
+			}
+
+			if err := p.next(); err != nil {
+				return err
+			}
+		case tokenComment:
+			comments = append(comments, p.tok.text)
+
+			if err := p.next(); err != nil {
+				return err
+			}
+		case tokenRBrace:
+			if !closing {
+				return errorf(p.tok.pos, "unexpected }, there's no block to close")
+			}
+
+			block.EndComments = trimBlank(comments)
+
+			return nil
+		case tokenEOF:
+			if closing {
+				return errorf(block.Pos, "the %s block isn't closed, it needs a }", block.Name)
+			}
+
+			block.EndComments = trimBlank(comments)
+
+			return nil
+		case tokenWord, tokenString:
+			node, err := p.parseStatement(comments)
+
+			if err != nil {
+				return err
+			}
+
+			block.Body = append(block.Body, node)
+			comments = nil
+		default:
+			return errorf(p.tok.pos, "expected a key or a block name, got %s", p.tok.describe())
+		}
+	}
+}
+
+// trimBlank drops the blank line after the last comment
+func trimBlank(comments []string) []string {
+	if len(comments) > 0 && comments[len(comments)-1] == "" {
+		return comments[:len(comments)-1]
+	}
+
+	return comments
+}
+
+// parseStatement parses an entry, or a block when the key and its labels are followed by a brace
+func (p *parser) parseStatement(comments []string) (Node, error) {
+	key := p.tok
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	colon := p.tok.kind == tokenColon
+
+	if colon {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	var values []Value
+
+	for p.tok.kind == tokenWord || p.tok.kind == tokenString {
+		values = append(values, Value{Pos: p.tok.pos, Text: p.tok.text, Quoted: p.tok.kind == tokenString, Heredoc: p.tok.heredoc, Raw: p.tok.raw})
+
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.tok.kind == tokenLBrace {
+		if key.kind == tokenString || colon {
+			return nil, errorf(key.pos, "expected a block name before {, got %s", key.describe())
+		}
+
+		return p.parseBlock(key, values, comments)
+	}
+
+	if len(values) == 0 {
+		if p.tok.kind == tokenNewline || p.tok.kind == tokenEOF || p.tok.kind == tokenSemicolon || p.tok.kind == tokenRBrace {
+			return nil, errorf(key.pos, "%s needs a value", quote(key.text))
+		}
+
+		return nil, errorf(p.tok.pos, "expected a value for %s, got %s", quote(key.text), p.tok.describe())
+	}
+
+	entry := &Entry{
+		Pos:       key.pos,
+		Key:       key.text,
+		KeyQuoted: key.kind == tokenString,
+		Values:    values,
+		Comments:  comments,
+	}
+
+	if p.tok.kind == tokenComment {
+		entry.Comment = p.tok.text
+
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch p.tok.kind {
+	case tokenNewline, tokenSemicolon, tokenRBrace, tokenEOF:
+		return entry, nil
+	}
+
+	return nil, errorf(p.tok.pos, "unexpected %s after the value of %s", p.tok.describe(), quote(key.text))
+}
+
+func (p *parser) parseBlock(name token, labels []Value, comments []string) (*Block, error) {
+	block := &Block{
+		Pos:      name.pos,
+		Name:     name.text,
+		Labels:   labels,
+		Comments: comments,
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenComment {
+		block.Comment = p.tok.text
+
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.parseBody(block, true); err != nil {
+		return nil, err
+	}
+
+	// the closing brace
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenComment && block.Comment == "" {
+		block.Comment = p.tok.text
+
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	return block, nil
+}
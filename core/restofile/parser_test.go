@@ -0,0 +1,222 @@
+package restofile
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const restofile = `# a request to create users
+request {
+   method "POST"
+   url "https://api.example.com/v1/users?page=2#top" # the url
+   contentType "application/json"
+}
+
+body {
+   readFrom "examples/user.json"
+}
+
+auth {
+   type "basic"
+   username: "alice"
+   password: "P@$$w0rd \"quoted\""
+   // the url of the request isn't an auth key
+}
+`
+
+func TestParse(t *testing.T) {
+	f, err := Parse("Restofile", []byte(restofile))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := f.Block("request")
+
+	if request == nil || len(f.Blocks("")) != 3 {
+		t.Fatalf("expected 3 blocks, got %+v", f.Body)
+	}
+
+	values := map[string]string{
+		"method":      "POST",
+		"url":         "https://api.example.com/v1/users?page=2#top",
+		"contentType": "application/json",
+		"type":        "",
+	}
+
+	for key, expected := range values {
+		if got := request.Value(key); got != expected {
+			t.Errorf("request.%s :: expected %q, got %q", key, expected, got)
+		}
+	}
+
+	auth := f.Block("auth")
+
+	if auth.Value("type") != "basic" || auth.Value("username") != "alice" || auth.Value("password") != `P@$$w0rd "quoted"` {
+		t.Errorf("unexpected auth block: %+v", auth.Entries())
+	}
+
+	if auth.Value("url") != "" {
+		t.Errorf("expected comments to be skipped")
+	}
+
+	if pos := auth.Entry("username").Pos; pos.String() != "Restofile:14:4" {
+		t.Errorf("expected username at Restofile:14:4, got %s", pos)
+	}
+
+	if request.Comments[0] != "# a request to create users" || request.Entry("url").Comment != "# the url" {
+		t.Errorf("expected the comments to be kept, got %q and %q", request.Comments, request.Entry("url").Comment)
+	}
+
+	if len(auth.EndComments) != 1 {
+		t.Errorf("expected the last comment of auth to be kept, got %q", auth.EndComments)
+	}
+}
+
+func TestParseSyntax(t *testing.T) {
+	cases := []struct {
+		name  string
+		src   string
+		check func(f *File) bool
+	}{
+		{
+			name: "labels and nested blocks",
+			src:  "request \"login\" {\n  method POST\n  expect {\n    status 200\n  }\n}",
+			check: func(f *File) bool {
+				r := f.Block("request")
+				return r.Label() == "login" && r.Block("expect").Value("status") == "200"
+			},
+		},
+		{
+			name: "several values and semicolons",
+			src:  `expect { json "data.id" exists; header "Content-Type" contains "json" }`,
+			check: func(f *File) bool {
+				e := f.Block("expect").Entry("header")
+				return len(e.Values) == 3 && e.Values[2].Text == "json"
+			},
+		},
+		{
+			name: "quoted keys",
+			src:  "headers {\n  \"X-Tenant\" \"acme\"\n}",
+			check: func(f *File) bool {
+				e := f.Block("headers").Entry("X-Tenant")
+				return e.KeyQuoted && e.Value() == "acme"
+			},
+		},
+		{
+			name:  "crlf and block comments",
+			src:   "request {\r\n  /* the\r\n  method */ method \"GET\"\r\n}\r\n",
+			check: func(f *File) bool { return f.Block("request").Value("method") == "GET" },
+		},
+		{
+			name:  "later entries override",
+			src:   "request {\n  method \"GET\"\n  method \"HEAD\"\n}",
+			check: func(f *File) bool { return f.Block("request").Value("method") == "HEAD" },
+		},
+		{
+			name:  "escapes",
+			src:   `x { a "\t\\d+\n" }`,
+			check: func(f *File) bool { return f.Block("x").Value("a") == "\t\\d+\n" },
+		},
+		{
+			name: "heredocs",
+			src:  "body {\n   content <<EOF\n      {\n         \"id\": \"{{id}}\"\n\n      }\n      EOF\n}",
+			check: func(f *File) bool {
+				v := f.Block("body").Entry("content").Values[0]
+				return v.Text == "{\n   \"id\": \"{{id}}\"\n\n}" && v.Heredoc == "EOF" && !v.Raw
+			},
+		},
+		{
+			name: "heredocs end before what follows the delimiter",
+			src:  "body { content <<JSON\r\n{}\r\nJSON }\nx 1",
+			check: func(f *File) bool {
+				return f.Block("body").Value("content") == "{}" && f.Value("x") == "1"
+			},
+		},
+		{
+			name: "raw strings",
+			src:  "body {\n   content \"\"\"\n      {\"path\": \"C:\\new\", \"id\": \"{{id}}\"}\n      \"\"\"\n   a \"\"\"one \"line\\n\"\"\"\n}",
+			check: func(f *File) bool {
+				b := f.Block("body")
+				v := b.Entry("content").Values[0]
+				return v.Text == `{"path": "C:\new", "id": "{{id}}"}` && v.Raw && b.Value("a") == `one "line\n`
+			},
+		},
+		{
+			name:  "empty",
+			src:   "\n# nothing\n",
+			check: func(f *File) bool { return len(f.Body) == 0 && len(f.EndComments) == 1 },
+		},
+	}
+
+	for _, c := range cases {
+		f, err := Parse("", []byte(c.src))
+
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+
+		if !c.check(f) {
+			t.Errorf("%s: unexpected tree %+v", c.name, f.Body)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		src      string
+		expected string
+	}{
+		{"request {\n  method \"GET\n}", `Restofile:2:10: unterminated string`},
+		{"request {\n  method \"GET\"\n", `Restofile:1:1: the request block isn't closed`},
+		{"request {\n}\n}", `Restofile:3:1: unexpected }`},
+		{"request {\n  method\n}", `Restofile:2:3: "method" needs a value`},
+		{"request {\n  method \"GET\" {\n", `Restofile:2:3: the method block isn't closed`},
+		{"request {\n  url = \"x\"\n}", `Restofile:2:7: unexpected character '='`},
+		{"\"request\" {\n}", `Restofile:1:1: expected a block name before {`},
+		{"request {\n  /* open", `Restofile:2:3: unterminated comment`},
+		{"request {\n  method: {\n}", `Restofile:2:3: expected a block name before {`},
+		{": x", `Restofile:1:1: expected a key or a block name, got :`},
+		{"body {\n  content <<EOF\n  {}\n}", `Restofile:2:11: unterminated heredoc, it needs a closing EOF line`},
+		{"body {\n  content << EOF\n}", `Restofile:2:11: expected a name after <<`},
+		{"body {\n  content <<EOF {}\nEOF\n}", `Restofile:2:17: the text of <<EOF starts on the next line`},
+		{"body {\n  content <<EOF\n  {\n }\n  EOF\n}", `Restofile:4:1: the lines of <<EOF must be indented at least as much as its closing EOF`},
+		{"body {\n  content \"\"\"\n  {}\n}", `Restofile:2:11: unterminated string, it needs a closing """`},
+	}
+
+	for _, c := range cases {
+		_, err := Parse("Restofile", []byte(c.src))
+
+		if err == nil {
+			t.Errorf("%q: expected an error", c.src)
+			continue
+		}
+
+		if _, ok := err.(*Error); !ok || !strings.HasPrefix(err.Error(), c.expected) {
+			t.Errorf("%q: expected %q, got %q", c.src, c.expected, err.Error())
+		}
+	}
+}
+
+func TestParseExamples(t *testing.T) {
+	paths, _ := filepath.Glob("../../examples/restofile/*/Restofile")
+
+	if len(paths) == 0 {
+		t.Fatal("no example Restofiles")
+	}
+
+	for _, path := range paths {
+		f, err := ParseFile(path)
+
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+
+		if f.Block("request").Value("url") == "" {
+			t.Errorf("%s: expected a request url", path)
+		}
+	}
+}
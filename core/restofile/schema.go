@@ -0,0 +1,78 @@
+package restofile
+
+// Schema lists the entries and blocks `resto run` reads from a block
+type Schema struct {
+	// Keys are the known entries, the first Leading of them are written first by Format, in this order
+	Keys    []string
+	Leading int
+
+	// AnyKeys is true for blocks of names and values, like headers or vars
+	AnyKeys bool
+
+	// Blocks are the known child blocks, in the order Format writes them
+	Blocks []string
+}
+
+// Schemas maps block names to their schemas, the top level of a file is ""
+var Schemas = map[string]Schema{
+	"": {
+		Keys:    []string{"include"},
+		Leading: 1,
+		Blocks:  []string{"vars", "env", "headers", "query", "auth", "body", "hooks", "request"},
+	},
+	"request": {
+		Keys:    []string{"extends", "method", "url", "contentType", "include", "abstract"},
+		Leading: 4,
+		Blocks:  []string{"vars", "headers", "query", "auth", "body", "hooks", "capture", "expect"},
+	},
+	"body": {
+		Keys: []string{"content", "openBodyEditor", "readFrom"},
+	},
+	// the auth providers add the keys of their fields
+	"auth": {
+		Keys:    []string{"type"},
+		Leading: 1,
+	},
+	"hooks": {
+		Keys: []string{"before", "after", "shell"},
+	},
+	"vars":    {AnyKeys: true},
+	"env":     {AnyKeys: true},
+	"headers": {AnyKeys: true},
+	"query":   {AnyKeys: true},
+	"capture": {AnyKeys: true},
+	"expect":  {AnyKeys: true},
+}
+
+// AddKeys adds keys to the known entries of the blocks named name, the keys it already has are skipped
+func AddKeys(name string, keys ...string) {
+	schema := Schemas[name]
+
+	for _, key := range keys {
+		if index(schema.Keys, key) == -1 {
+			schema.Keys = append(schema.Keys, key)
+		}
+	}
+
+	Schemas[name] = schema
+}
+
+// HasKey reports whether key is an entry of the blocks of the schema
+func (s Schema) HasKey(key string) bool {
+	return s.AnyKeys || index(s.Keys, key) != -1
+}
+
+// HasBlock reports whether name is a child block of the blocks of the schema
+func (s Schema) HasBlock(name string) bool {
+	return index(s.Blocks, name) != -1
+}
+
+func index(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+
+	return -1
+}
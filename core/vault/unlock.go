@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/abdfnx/resto/tools"
+)
+
+// PassphraseEnv is the env variable the passphrase is read from before prompting
+const PassphraseEnv = "RESTO_VAULT_PASSPHRASE"
+
+// ErrLocked is returned when a secret is needed but there's no way to get the passphrase
+var ErrLocked = errors.New("vault: the vault is locked, set " + PassphraseEnv + " or unlock it")
+
+// Prompt asks for the passphrase, it's set by commands that can prompt
+var Prompt func() (string, error)
+
+var (
+	mu       sync.Mutex
+	unlocked *Vault
+)
+
+func init() {
+	tools.RegisterResolver("secret:", Lookup)
+}
+
+// Unlock opens the vault with passphrase and keeps it open for the next lookups
+func Unlock(passphrase string) (*Vault, error) {
+	v, err := Open(passphrase)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	unlocked = v
+	mu.Unlock()
+
+	return v, nil
+}
+
+// Locked reports whether looking up a secret needs a passphrase that resto doesn't have yet
+func Locked() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return unlocked == nil && os.Getenv(PassphraseEnv) == "" && Prompt == nil
+}
+
+// Opened returns the vault if it's already unlocked, it never asks for the passphrase
+func Opened() *Vault {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return unlocked
+}
+
+// Unlocked returns the open vault, it's unlocked with RESTO_VAULT_PASSPHRASE or Prompt when needed
+func Unlocked() (*Vault, error) {
+	mu.Lock()
+	v := unlocked
+	mu.Unlock()
+
+	if v != nil {
+		return v, nil
+	}
+
+	passphrase := os.Getenv(PassphraseEnv)
+
+	if passphrase == "" && Prompt != nil {
+		var err error
+
+		if passphrase, err = Prompt(); err != nil {
+			return nil, err
+		}
+	}
+
+	if passphrase == "" {
+		return nil, ErrLocked
+	}
+
+	return Unlock(passphrase)
+}
+
+// Lookup returns the secret called name, it resolves `secret:NAME` values
+func Lookup(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if !Exists() {
+		return "", fmt.Errorf("vault: no vault yet, add %q with `resto secret set %s`", name, name)
+	}
+
+	v, err := Unlocked()
+
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := v.Get(name)
+
+	if !ok {
+		return "", fmt.Errorf("vault: no secret called %q", name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,201 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/abdfnx/resto/tools"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters of new vaults, they're stored in the file so they can be raised later
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keySize = 32
+)
+
+// additionalData binds the ciphertext to the vault format
+var additionalData = []byte("resto-vault-v1")
+
+// ErrWrongPassphrase is returned when the vault can't be decrypted
+var ErrWrongPassphrase = errors.New("vault: wrong passphrase")
+
+// vaultFile locates the encrypted vault, useTempVault gives each test an empty one
+var vaultFile = tools.VaultFile
+
+// file is the encrypted vault as stored on disk
+type file struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Vault is a decrypted vault, changes are written with Save
+type Vault struct {
+	secrets map[string]string
+	header  file
+	key     []byte
+}
+
+// Exists reports whether a vault was created
+func Exists() bool {
+	_, err := os.Stat(vaultFile())
+
+	return err == nil
+}
+
+// Open decrypts the vault with passphrase, a vault that doesn't exist yet is empty
+func Open(passphrase string) (*Vault, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("vault: the passphrase can't be empty")
+	}
+
+	data, err := ioutil.ReadFile(vaultFile())
+
+	if os.IsNotExist(err) {
+		return create(passphrase)
+	} else if err != nil {
+		return nil, err
+	}
+
+	v := &Vault{secrets: map[string]string{}}
+
+	if err := json.Unmarshal(data, &v.header); err != nil {
+		return nil, fmt.Errorf("vault: invalid %s: %s", vaultFile(), err.Error())
+	}
+
+	if v.header.Version != 1 || v.header.KDF != "scrypt" {
+		return nil, fmt.Errorf("vault: unsupported vault version %d (%s)", v.header.Version, v.header.KDF)
+	}
+
+	if v.key, err = scrypt.Key([]byte(passphrase), v.header.Salt, v.header.N, v.header.R, v.header.P, keySize); err != nil {
+		return nil, fmt.Errorf("vault: %s", err.Error())
+	}
+
+	gcm, err := newGCM(v.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, v.header.Nonce, v.header.Data, additionalData)
+
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	if err := json.Unmarshal(plaintext, &v.secrets); err != nil {
+		return nil, fmt.Errorf("vault: invalid secrets: %s", err.Error())
+	}
+
+	return v, nil
+}
+
+func create(passphrase string) (*Vault, error) {
+	salt := make([]byte, 16)
+
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+
+	if err != nil {
+		return nil, fmt.Errorf("vault: %s", err.Error())
+	}
+
+	return &Vault{
+		secrets: map[string]string{},
+		header:  file{Version: 1, KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: salt},
+		key:     key,
+	}, nil
+}
+
+// Get returns the secret called name
+func (v *Vault) Get(name string) (string, bool) {
+	value, ok := v.secrets[name]
+
+	return value, ok
+}
+
+// Set adds or replaces a secret
+func (v *Vault) Set(name, value string) {
+	v.secrets[name] = value
+}
+
+// Remove deletes a secret, it reports whether it existed
+func (v *Vault) Remove(name string) bool {
+	_, ok := v.secrets[name]
+	delete(v.secrets, name)
+
+	return ok
+}
+
+// Names returns the names of the secrets, sorted
+func (v *Vault) Names() []string {
+	names := make([]string, 0, len(v.secrets))
+
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Save encrypts the secrets with a new nonce and writes the vault, only readable by the user
+func (v *Vault) Save() error {
+	plaintext, err := json.Marshal(v.secrets)
+
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(v.key)
+
+	if err != nil {
+		return err
+	}
+
+	v.header.Nonce = make([]byte, gcm.NonceSize())
+
+	if _, err := rand.Read(v.header.Nonce); err != nil {
+		return err
+	}
+
+	v.header.Data = gcm.Seal(nil, v.header.Nonce, plaintext, additionalData)
+
+	data, err := json.MarshalIndent(v.header, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(vaultFile(), data, 0600)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
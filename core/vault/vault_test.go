@@ -0,0 +1,157 @@
+package vault
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdfnx/resto/tools"
+)
+
+func useTempVault(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	vaultFile = func() string { return path }
+	unlocked = nil
+
+	t.Cleanup(func() {
+		vaultFile = tools.VaultFile
+		unlocked = nil
+	})
+
+	return path
+}
+
+func TestVaultRoundTrip(t *testing.T) {
+	path := useTempVault(t)
+
+	if Exists() {
+		t.Fatalf("expected no vault yet")
+	}
+
+	v, err := Open("correct horse")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v.Set("GITHUB_TOKEN", "ghp_123")
+	v.Set("API_KEY", "k3y")
+
+	if err := v.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the vault to be 0600, got %v", info.Mode().Perm())
+	}
+
+	data, _ := ioutil.ReadFile(path)
+
+	if !json.Valid(data) || containsAny(string(data), "ghp_123", "k3y", "GITHUB_TOKEN") {
+		t.Errorf("expected the secrets to be encrypted, got %s", data)
+	}
+
+	v, err = Open("correct horse")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, ok := v.Get("GITHUB_TOKEN"); !ok || value != "ghp_123" {
+		t.Errorf("expected ghp_123, got %q", value)
+	}
+
+	if names := v.Names(); len(names) != 2 || names[0] != "API_KEY" || names[1] != "GITHUB_TOKEN" {
+		t.Errorf("expected the sorted names, got %v", names)
+	}
+
+	if !v.Remove("API_KEY") || v.Remove("API_KEY") {
+		t.Errorf("expected API_KEY to be removed once")
+	}
+}
+
+func TestVaultWrongPassphrase(t *testing.T) {
+	path := useTempVault(t)
+
+	v, err := Open("correct horse")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v.Set("TOKEN", "t0ken")
+
+	if err := v.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open("battery staple"); err != ErrWrongPassphrase {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	var stored file
+
+	data, _ := ioutil.ReadFile(path)
+	json.Unmarshal(data, &stored)
+
+	stored.Data[0] ^= 0xff
+	data, _ = json.Marshal(stored)
+	ioutil.WriteFile(path, data, 0600)
+
+	if _, err := Open("correct horse"); err != ErrWrongPassphrase {
+		t.Errorf("expected a tampered vault to be refused, got %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	useTempVault(t)
+
+	if _, err := tools.ResolveValue("secret:TOKEN"); err == nil {
+		t.Errorf("expected an error without a vault")
+	}
+
+	v, err := Open("correct horse")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v.Set("TOKEN", "t0ken")
+
+	if err := v.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv(PassphraseEnv, "correct horse")
+	defer os.Unsetenv(PassphraseEnv)
+
+	value, err := tools.ResolveValue("secret:TOKEN")
+
+	if err != nil || value != "t0ken" {
+		t.Errorf("expected t0ken, got %q (%v)", value, err)
+	}
+
+	if _, err := tools.ResolveValue("secret:MISSING"); err == nil {
+		t.Errorf("expected an error for a missing secret")
+	}
+}
+
+func containsAny(text string, values ...string) bool {
+	for _, value := range values {
+		if strings.Contains(text, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package ios
+
+import "testing"
+
+func TestEnvColor(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		noColor  string
+		clicolor string
+		force    string
+		disabled bool
+		forced   bool
+	}{
+		{name: "default"},
+		{name: "NO_COLOR", noColor: "1", disabled: true},
+		{name: "CLICOLOR=0", clicolor: "0", disabled: true},
+		{name: "CLICOLOR=1", clicolor: "1"},
+		{name: "CLICOLOR_FORCE", force: "1", forced: true},
+		{name: "CLICOLOR_FORCE=0", force: "0"},
+		{name: "NO_COLOR and CLICOLOR_FORCE", noColor: "1", force: "1", disabled: true, forced: true},
+	} {
+		t.Setenv("NO_COLOR", c.noColor)
+		t.Setenv("CLICOLOR", c.clicolor)
+		t.Setenv("CLICOLOR_FORCE", c.force)
+
+		if got := EnvColorDisabled(); got != c.disabled {
+			t.Errorf("%s: expected EnvColorDisabled to be %v, got %v", c.name, c.disabled, got)
+		}
+
+		if got := EnvColorForced(); got != c.forced {
+			t.Errorf("%s: expected EnvColorForced to be %v, got %v", c.name, c.forced, got)
+		}
+	}
+}
+
+func TestDetectTerminalTheme(t *testing.T) {
+	io, _, _, _ := Test()
+
+	if theme := io.TerminalTheme(); theme != "none" {
+		t.Errorf("expected no theme before it's detected, got %q", theme)
+	}
+
+	if theme := io.DetectTerminalTheme(); theme != "none" {
+		t.Errorf("expected no theme without colors, got %q", theme)
+	}
+
+	io.SetColorEnabled(true)
+	t.Setenv("GLAMOUR_STYLE", "dracula")
+
+	if theme := io.DetectTerminalTheme(); theme != "none" {
+		t.Errorf("expected no theme with a glamour style, got %q", theme)
+	}
+
+	t.Setenv("GLAMOUR_STYLE", "")
+
+	if theme := io.DetectTerminalTheme(); theme != "dark" && theme != "light" {
+		t.Errorf("expected a dark or light theme, got %q", theme)
+	}
+
+	if io.TerminalTheme() != io.DetectTerminalTheme() {
+		t.Errorf("expected the detected theme to be kept")
+	}
+}
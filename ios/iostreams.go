@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -48,7 +49,7 @@ type IOStreams struct {
 	ttySize           func() (int, int, error)
 
 	pagerCommand string
-	pagerProcess *os.Process
+	pagerProcess *exec.Cmd
 
 	neverPrompt bool
 
@@ -190,6 +191,11 @@ func (s *IOStreams) StartPager() error {
 		pagerEnv = append(pagerEnv, "LV=-c")
 	}
 
+	// keep the colors of the response when paging with less
+	if filepath.Base(pagerArgs[0]) == "less" && !hasRawControlFlag(pagerArgs[1:]) {
+		pagerArgs = append(pagerArgs, "-R")
+	}
+
 	pagerExe, err := looker.LookPath(pagerArgs[0])
 
 	if err != nil {
@@ -212,17 +218,32 @@ func (s *IOStreams) StartPager() error {
 		return err
 	}
 
-	s.pagerProcess = pagerCmd.Process
+	s.pagerProcess = pagerCmd
 	return nil
 }
 
+func hasRawControlFlag(args []string) bool {
+	for _, arg := range args {
+		if arg == "--RAW-CONTROL-CHARS" || arg == "--raw-control-chars" {
+			return true
+		}
+
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.ContainsAny(arg, "Rr") {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *IOStreams) StopPager() {
 	if s.pagerProcess == nil {
 		return
 	}
 
 	_ = s.Out.(io.ReadCloser).Close()
-	_, _ = s.pagerProcess.Wait()
+	// waiting on the command also waits for the output of the pager to be copied
+	_ = s.pagerProcess.Wait()
 	s.pagerProcess = nil
 }
 
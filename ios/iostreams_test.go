@@ -0,0 +1,105 @@
+package ios
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStartPager(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("the pager is tr")
+	}
+
+	io, _, out, _ := Test()
+	io.SetStdoutTTY(true)
+	io.SetPager("tr a-z A-Z")
+
+	if err := io.StartPager(); err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Fprint(io.Out, "paged response")
+	io.StopPager()
+
+	if out.String() != "PAGED RESPONSE" {
+		t.Errorf("expected the response to go through the pager, got %q", out.String())
+	}
+}
+
+func TestStartPagerSkipped(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		pager string
+		tty   bool
+	}{
+		{name: "no pager", pager: "", tty: true},
+		{name: "cat", pager: "cat", tty: true},
+		{name: "not a terminal", pager: "tr a-z A-Z", tty: false},
+	} {
+		io, _, out, _ := Test()
+		io.SetStdoutTTY(c.tty)
+		io.SetPager(c.pager)
+
+		if err := io.StartPager(); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		fmt.Fprint(io.Out, "response")
+		io.StopPager()
+
+		if out.String() != "response" {
+			t.Errorf("%s: expected the response to be written as is, got %q", c.name, out.String())
+		}
+	}
+}
+
+func TestStartPagerLess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake less is a shell script")
+	}
+
+	dir := t.TempDir()
+
+	// the fake less prints its arguments instead of the response
+	if err := ioutil.WriteFile(filepath.Join(dir, "less"), []byte("#!/bin/sh\ncat >/dev/null\necho \"$@\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	for pager, expected := range map[string]string{
+		"less":                     "-R\n",
+		"less -S":                  "-S -R\n",
+		"less -SR":                 "-SR\n",
+		"less --raw-control-chars": "--raw-control-chars\n",
+	} {
+		io, _, out, _ := Test()
+		io.SetStdoutTTY(true)
+		io.SetPager(pager)
+
+		if err := io.StartPager(); err != nil {
+			t.Fatalf("%s: %v", pager, err)
+		}
+
+		io.StopPager()
+
+		if out.String() != expected {
+			t.Errorf("%s: expected the arguments %q, got %q", pager, expected, out.String())
+		}
+	}
+}
+
+func TestStartPagerNotFound(t *testing.T) {
+	io, _, _, _ := Test()
+	io.SetStdoutTTY(true)
+	io.SetPager("resto-no-such-pager")
+
+	if err := io.StartPager(); err == nil {
+		t.Errorf("expected an error for a pager that isn't installed")
+	}
+}
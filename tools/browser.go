@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// OpenBrowser opens url in the browser from `BROWSER`, or the default one of the system
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		cmd = exec.Command(browser, url)
+	} else if runtime.GOOS == "windows" {
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	} else if runtime.GOOS == "darwin" {
+		cmd = exec.Command("open", url)
+	} else {
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	return cmd.Start()
+}
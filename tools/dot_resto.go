@@ -13,6 +13,9 @@ var homeDir, _ = homedir.Dir()
 var dotResto = path.Join(homeDir, "./.resto")
 var requestFile = path.Join(dotResto, "requestBody")
 var settingsFile = path.Join(dotResto, "settings.json")
+var oauth2File = path.Join(dotResto, "oauth2.json")
+var hostsFile = path.Join(dotResto, "hosts.json")
+var vaultFile = path.Join(dotResto, "vault.json")
 var cliDir = path.Join(dotResto, "/cli")
 
 func CheckDotResto() {
@@ -52,6 +55,21 @@ func SettingsFile() string {
 	return settingsFile
 }
 
+// OAuth2File is the plaintext cache of OAuth2 tokens of older versions, they're kept in the vault now
+func OAuth2File() string {
+	return oauth2File
+}
+
+// HostsFile is the store of per host credentials
+func HostsFile() string {
+	return hostsFile
+}
+
+// VaultFile is the encrypted secret vault
+func VaultFile() string {
+	return vaultFile
+}
+
 func CLIRequestFile(format string) string {
 	return path.Join(cliDir, "requestBody." + format)
 }
@@ -2,19 +2,47 @@ package tools
 
 import (
 	"bytes"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 )
 
 func Exec(shell, command string) (error, string, string) {
+	return ExecWith(shell, command, nil, "")
+}
+
+// ExecWith runs command like Exec, with env added to the environment of resto and stdin as its input
+func ExecWith(shell, command string, env []string, stdin string) (error, string, string) {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 
-	cmd := exec.Command(shell, "-c", command)
+	cmd := exec.Command(shell, shellArgs(shell, command)...)
 
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+
 	err := cmd.Run()
 
 	return err, stdout.String(), stderr.String()
 }
+
+// shellArgs returns the arguments that make shell run command, -c for sh like shells
+func shellArgs(shell, command string) []string {
+	switch strings.ToLower(strings.TrimSuffix(filepath.Base(shell), filepath.Ext(shell))) {
+	case "powershell", "pwsh":
+		return []string{"-NoProfile", "-NonInteractive", "-Command", command}
+	case "cmd":
+		return []string{"/C", command}
+	}
+
+	return []string{"-c", command}
+}
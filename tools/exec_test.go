@@ -0,0 +1,20 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShellArgs(t *testing.T) {
+	for shell, expected := range map[string][]string{
+		"bash":           {"-c", "echo hi"},
+		"/bin/sh":        {"-c", "echo hi"},
+		"powershell.exe": {"-NoProfile", "-NonInteractive", "-Command", "echo hi"},
+		"pwsh":           {"-NoProfile", "-NonInteractive", "-Command", "echo hi"},
+		"cmd.exe":        {"/C", "echo hi"},
+	} {
+		if args := shellArgs(shell, "echo hi"); fmt.Sprint(args) != fmt.Sprint(expected) {
+			t.Errorf("%s: expected %q, got %q", shell, expected, args)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Resolver returns the value referenced by name, like a vault secret
+type Resolver func(name string) (string, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{}
+)
+
+// RegisterResolver adds a `prefix:NAME` reference kind to Resolve, next to `env:`
+func RegisterResolver(prefix string, resolver Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	resolvers[prefix] = resolver
+}
+
+// IsReference reports whether value is an `env:NAME` reference or one of the registered kinds, which ResolveValue resolves
+func IsReference(value string) bool {
+	if strings.HasPrefix(value, "env:") {
+		return true
+	}
+
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+
+	for prefix := range resolvers {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveValue returns the value of `env:NAME` references, and of the registered ones like `secret:NAME`,
+// other values are returned as they are
+func ResolveValue(value string) (string, error) {
+	if strings.HasPrefix(value, "env:") {
+		return os.Getenv(strings.TrimSpace(strings.TrimPrefix(value, "env:"))), nil
+	}
+
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+
+	for prefix, resolver := range resolvers {
+		if strings.HasPrefix(value, prefix) {
+			return resolver(strings.TrimPrefix(value, prefix))
+		}
+	}
+
+	return value, nil
+}
@@ -2,6 +2,8 @@ package tools
 
 import (
 	"io/ioutil"
+
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 	"github.com/tidwall/pretty"
 )
@@ -16,6 +18,17 @@ func SettingsContent() string {
 	return string(stgFile)
 }
 
+// Setting returns the value of a `rs_settings` key, it's empty if the settings file can't be read
+func Setting(key string) gjson.Result {
+	stgFile, err := ioutil.ReadFile(settingsFile)
+
+	if err != nil {
+		return gjson.Result{}
+	}
+
+	return gjson.Get(string(stgFile), "rs_settings." + key)
+}
+
 func UpdateSettings(value bool) {
 	settings, _ := sjson.Set(settingsFile, "rs_settings.show_update", value)
 